@@ -0,0 +1,176 @@
+// Package backlog embeds Ergo's dependency-aware backlog in Go programs.
+//
+// It exposes the use cases the ergo command runs. An Application is bound to
+// a project directory; each method takes a typed request, holds the
+// repository lock only for its own duration, and returns a typed outcome or an
+// error classified by ErrorKind. Concurrent processes, including the ergo
+// command, may share one backlog.
+//
+// Reads return a Graph snapshot. Its query methods (IsReady, Blockers,
+// Dependents, Dependencies, Children, IsEpic, and EpicState) derive
+// relationships the same way list and claim do. A snapshot never changes;
+// call the Application again to observe later writes, and change the backlog
+// only through Application methods.
+//
+// # Compatibility
+//
+// Within major version 4 of the module, this package keeps its exported
+// names, the methods of Application and Graph, the request fields, the outcome
+// fields whose types this package exports, and the meaning of each ErrorKind.
+// Outcome fields typed with names this package does not export carry
+// presentation state for the ergo command and may change in any release.
+// Error messages are for people and may change; branch on ErrorKind.
+package backlog
+
+import "github.com/sandover/ergo/v4/internal/ergo"
+
+// Application runs backlog use cases against one repository.
+type Application = ergo.Application
+
+// Options locates the repository. StartDir is searched upward for .ergo;
+// empty means the working directory.
+type Options = ergo.RepositoryOptions
+
+// New returns an Application for the repository found from options.StartDir.
+// It does not touch the filesystem; each call opens the repository anew.
+func New(options Options) *Application {
+	return ergo.NewApplication(options)
+}
+
+// Backlog model.
+type (
+	Graph         = ergo.Graph
+	Task          = ergo.Task
+	Result        = ergo.Result
+	Message       = ergo.Message
+	TombstoneInfo = ergo.TombstoneInfo
+	JournalEntry  = ergo.JournalEntry
+	JournalFile   = ergo.JournalFile
+)
+
+// Task states as stored in Task.State and reported by Graph.EpicState.
+const (
+	StateTodo     = "todo"
+	StateDraft    = "draft"
+	StateDoing    = "doing"
+	StateBlocked  = "blocked"
+	StateDone     = "done"
+	StateFailed   = "failed"
+	StateCanceled = "canceled"
+	// StateError is a legacy state that current writers never produce.
+	StateError = "error"
+)
+
+// Use-case requests and outcomes.
+type (
+	InitializeRequest  = ergo.InitializeRequest
+	InitializeOutcome  = ergo.InitializeOutcome
+	CreateTaskRequest  = ergo.CreateTaskRequest
+	CreateTaskOutcome  = ergo.CreateTaskOutcome
+	CreateEpicRequest  = ergo.CreateEpicRequest
+	CreateEpicOutcome  = ergo.CreateEpicOutcome
+	ListRequest        = ergo.ListRequest
+	ListOutcome        = ergo.ListOutcome
+	ShowRequest        = ergo.ShowRequest
+	ShowOutcome        = ergo.ShowOutcome
+	ShowBodyRequest    = ergo.ShowBodyRequest
+	ShowBodyOutcome    = ergo.ShowBodyOutcome
+	SearchRequest      = ergo.SearchRequest
+	SearchOutcome      = ergo.SearchOutcome
+	SearchHit          = ergo.SearchHit
+	HistoryRequest     = ergo.HistoryRequest
+	HistoryOutcome     = ergo.HistoryOutcome
+	HistoryEntry       = ergo.HistoryEntry
+	GraphRequest       = ergo.GraphRequest
+	GraphOutcome       = ergo.GraphOutcome
+	PathRequest        = ergo.PathRequest
+	PathOutcome        = ergo.PathOutcome
+	PathLink           = ergo.PathLink
+	StatsRequest       = ergo.StatsRequest
+	StatsOutcome       = ergo.StatsOutcome
+	StatsDay           = ergo.StatsDay
+	StatsAgent         = ergo.StatsAgent
+	WatchRequest       = ergo.WatchRequest
+	WatchEvent         = ergo.WatchEvent
+	ClaimRequest       = ergo.ClaimRequest
+	ClaimOutcome       = ergo.ClaimOutcome
+	HeartbeatRequest   = ergo.HeartbeatRequest
+	HeartbeatOutcome   = ergo.HeartbeatOutcome
+	LifecycleRequest   = ergo.LifecycleRequest
+	LifecycleOutcome   = ergo.LifecycleOutcome
+	ResultRequest      = ergo.ResultRequest
+	ResultOutcome      = ergo.ResultOutcome
+	UpdateTitleRequest = ergo.UpdateTitleRequest
+	UpdateTitleOutcome = ergo.UpdateTitleOutcome
+	UpdateBodyRequest  = ergo.UpdateBodyRequest
+	UpdateBodyOutcome  = ergo.UpdateBodyOutcome
+	TaskText           = ergo.TaskText
+	EditTaskRequest    = ergo.EditTaskRequest
+	EditTaskOutcome    = ergo.EditTaskOutcome
+	SetPriorityRequest = ergo.SetPriorityRequest
+	SetPriorityOutcome = ergo.SetPriorityOutcome
+	LabelRequest       = ergo.LabelRequest
+	LabelOutcome       = ergo.LabelOutcome
+	MoveRequest        = ergo.MoveRequest
+	MoveOutcome        = ergo.MoveOutcome
+	SequenceRequest    = ergo.SequenceRequest
+	SequenceOutcome    = ergo.SequenceOutcome
+	BatchRequest       = ergo.BatchRequest
+	BatchOutcome       = ergo.BatchOutcome
+	BatchCreated       = ergo.BatchCreated
+	ImportBeadsRequest = ergo.ImportBeadsRequest
+	ImportBeadsOutcome = ergo.ImportBeadsOutcome
+	ImportedTask       = ergo.ImportedTask
+	ImportNote         = ergo.ImportNote
+	UndoRequest        = ergo.UndoRequest
+	UndoOutcome        = ergo.UndoOutcome
+	WhereOutcome       = ergo.WhereOutcome
+	InfoRequest        = ergo.InfoRequest
+	InfoOutcome        = ergo.InfoOutcome
+	CompactOutcome     = ergo.CompactOutcome
+	PruneRequest       = ergo.PruneRequest
+	PruneOutcome       = ergo.PruneOutcome
+	PruneItem          = ergo.PruneItem
+	FsckRequest        = ergo.FsckRequest
+	FsckOutcome        = ergo.FsckOutcome
+	FsckProblem        = ergo.FsckProblem
+	MergeDriverRequest = ergo.MergeDriverRequest
+	MergeDriverOutcome = ergo.MergeDriverOutcome
+	VersionRequest     = ergo.VersionRequest
+	VersionOutcome     = ergo.VersionOutcome
+	QuickstartRequest  = ergo.QuickstartRequest
+	QuickstartOutcome  = ergo.QuickstartOutcome
+)
+
+// Error is the classified failure returned by Application methods. Err keeps
+// the precise message.
+type Error = ergo.ApplicationError
+
+// EditConflict is the cause inside a conflict Error from EditTask when the
+// stored text moved on; errors.As finds it.
+type EditConflict = ergo.EditConflict
+
+// ErrorKind is the stable classification of an Error.
+type ErrorKind = ergo.ErrorKind
+
+const (
+	ErrorUsage      = ergo.ErrorUsage
+	ErrorNotFound   = ergo.ErrorNotFound
+	ErrorConflict   = ergo.ErrorConflict
+	ErrorBusy       = ergo.ErrorBusy
+	ErrorTimeout    = ergo.ErrorTimeout
+	ErrorCorruption = ergo.ErrorCorruption
+	ErrorInternal   = ergo.ErrorInternal
+)
+
+// Sentinel causes that errors.Is finds inside an Error.
+var (
+	ErrNoErgoDir         = ergo.ErrNoErgoDir
+	ErrLockBusy          = ergo.ErrLockBusy
+	ErrCorruptRepository = ergo.ErrCorruptRepository
+)
+
+// KindOf reports the ErrorKind of err, or false when err is not an Error.
+func KindOf(err error) (ErrorKind, bool) {
+	return ergo.ApplicationErrorKind(err)
+}
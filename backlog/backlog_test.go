@@ -0,0 +1,54 @@
+package backlog_test
+
+import (
+	"testing"
+
+	"github.com/sandover/ergo/v4/backlog"
+)
+
+func TestStateConstantsMatchStoredStates(t *testing.T) {
+	dir := t.TempDir()
+	app := backlog.New(backlog.Options{StartDir: dir})
+	if _, err := app.Initialize(backlog.InitializeRequest{Dir: dir}); err != nil {
+		t.Fatal(err)
+	}
+	created, err := app.CreateTask(backlog.CreateTaskRequest{Title: "Staged", Draft: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := func() string {
+		t.Helper()
+		shown, err := app.Show(backlog.ShowRequest{ID: created.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return shown.Task.State
+	}
+	if got := state(); got != backlog.StateDraft {
+		t.Fatalf("new draft state = %q", got)
+	}
+	for _, step := range []struct{ kind, want string }{
+		{"open", backlog.StateTodo},
+		{"block", backlog.StateBlocked},
+		{"fail", backlog.StateFailed},
+		{"done", backlog.StateDone},
+		{"cancel", backlog.StateCanceled},
+	} {
+		if _, err := app.Lifecycle(backlog.LifecycleRequest{Kind: step.kind, ID: created.ID}); err != nil {
+			t.Fatalf("%s: %v", step.kind, err)
+		}
+		if got := state(); got != step.want {
+			t.Fatalf("after %s state = %q, want %q", step.kind, got, step.want)
+		}
+	}
+}
+
+func TestKindOfIgnoresUnclassifiedErrors(t *testing.T) {
+	if kind, ok := backlog.KindOf(backlog.ErrLockBusy); ok {
+		t.Fatalf("KindOf(sentinel) = %q, true", kind)
+	}
+	err := error(&backlog.Error{Kind: backlog.ErrorConflict, Err: backlog.ErrLockBusy})
+	if kind, ok := backlog.KindOf(err); !ok || kind != backlog.ErrorConflict {
+		t.Fatalf("KindOf(Error) = %q, %v", kind, ok)
+	}
+}
@@ -0,0 +1,64 @@
+package backlog_test
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/sandover/ergo/v4/backlog"
+)
+
+// Plan two tasks in order, then work through them the way an agent would.
+func Example() {
+	dir, err := os.MkdirTemp("", "backlog-example")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	app := backlog.New(backlog.Options{StartDir: dir})
+	if _, err := app.Initialize(backlog.InitializeRequest{Dir: dir}); err != nil {
+		log.Fatal(err)
+	}
+	plan, err := app.Batch(backlog.BatchRequest{Input: []byte(`{"op":"create","handle":"schema","title":"Design the schema"}
+{"op":"create","handle":"api","title":"Build the API"}
+{"op":"sequence","ids":["$schema","$api"]}
+`)})
+	if err != nil {
+		log.Fatal(err)
+	}
+	schema, api := plan.Handles()["schema"], plan.Handles()["api"]
+	fmt.Println("api ready:", plan.Graph.IsReady(api), "blocked by schema:", plan.Graph.Blockers(api)[0] == schema)
+
+	claimed, err := app.Claim(backlog.ClaimRequest{AgentID: "worker"})
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println("claimed:", claimed.Task.Title)
+
+	done, err := app.Lifecycle(backlog.LifecycleRequest{Kind: "done", ID: claimed.Task.ID})
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println("schema:", done.Task.State, "next:", done.Ready.Title)
+	// Output:
+	// api ready: false blocked by schema: true
+	// claimed: Design the schema
+	// schema: done next: Build the API
+}
+
+// Branch on the error kind rather than the message.
+func ExampleKindOf() {
+	dir, err := os.MkdirTemp("", "backlog-example")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	app := backlog.New(backlog.Options{StartDir: dir})
+	_, err = app.Show(backlog.ShowRequest{ID: "ABCDEF"})
+	kind, _ := backlog.KindOf(err)
+	fmt.Println(kind, errors.Is(err, backlog.ErrNoErgoDir))
+	// Output: not_found true
+}
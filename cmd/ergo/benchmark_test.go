@@ -111,8 +111,11 @@ func TestConcurrentClaimNoDoubles(t *testing.T) {
 			agentID := fmt.Sprintf("agent-%d", agentNum)
 
 			stdout, stderr, exitCode := runTestErgoWithExit(ergo, dir, "", "claim", "--agent", agentID)
+			if exitCode == exitNoReady {
+				return
+			}
 			if exitCode == 0 {
-				if strings.Contains(stdout, "No ready ergo tasks.") || stdout == "" {
+				if stdout == "" {
 					return
 				}
 				id := extractClaimedTaskID(stdout)
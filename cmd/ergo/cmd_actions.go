@@ -1,16 +1,23 @@
 // Purpose: Assemble Ergo's user-facing commands and connect process I/O.
 // Exports: none; NewRootCommand calls addCommands.
-// Role: Parse arguments and flags into application requests, then render outcomes.
+// Role: Parse arguments and flags into backlog package requests, then render outcomes.
 // Invariants: command handlers do not implement persistence or graph rules.
 // Invariants: stdin body bytes pass through unchanged to the application layer.
 package main
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
+	"net"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"github.com/sandover/ergo/v4/backlog"
 	"github.com/sandover/ergo/v4/internal/ergo"
 	"github.com/spf13/cobra"
 )
@@ -26,7 +33,7 @@ func commandRender(cmd *cobra.Command, streams Streams, color colorMode) ergo.Re
 func commandInput(cmd *cobra.Command, streams Streams, required bool, id string) (string, error) {
 	if streams.StdinTerminal {
 		if required {
-			return "", errors.New("body requires piped stdin; example: printf '%s\\n' '## Goal' | ergo body " + id)
+			return "", usageError(errors.New("body requires piped stdin; example: printf '%s\\n' '## Goal' | ergo body " + id))
 		}
 		return "", nil
 	}
@@ -34,42 +41,41 @@ func commandInput(cmd *cobra.Command, streams Streams, required bool, id string)
 	return string(body), err
 }
 
-func addCommands(root *cobra.Command, base *ergo.Application, streams Streams, options *ergo.RepositoryOptions, color *colorMode, buildVersion string) {
-	app := func() *ergo.Application { return base.WithRepository(*options) }
+func addCommands(root *cobra.Command, base *backlog.Application, streams Streams, options *backlog.Options, color *colorMode, buildVersion string) {
+	app := func() *backlog.Application { return base.WithRepository(*options) }
 	render := func(cmd *cobra.Command) ergo.RenderOptions { return commandRender(cmd, streams, *color) }
 
 	initCmd := &cobra.Command{Use: "init [dir]", Short: "Initialize an Ergo graph", Args: cobra.MaximumNArgs(1)}
 	initCmd.Args = func(_ *cobra.Command, args []string) error {
 		if len(args) > 1 {
-			return errors.New("usage: ergo init [dir]")
+			return usageError(errors.New("usage: ergo init [dir] [--git-merge]"))
 		}
 		return nil
 	}
+	initCmd.Flags().Bool("git-merge", false, "Route backlog merges through ergo merge-driver")
 	initCmd.RunE = func(cmd *cobra.Command, args []string) error {
 		dir := ""
 		if len(args) == 1 {
 			dir = args[0]
 		}
-		out, err := app().Initialize(ergo.InitializeRequest{Dir: dir})
+		gitMerge, _ := cmd.Flags().GetBool("git-merge")
+		out, err := app().Initialize(backlog.InitializeRequest{Dir: dir, GitMerge: gitMerge})
 		if err == nil {
-			ergo.RenderInitialize(cmd.OutOrStdout(), out)
+			err = writeOutcome(cmd, out, func(w io.Writer) { ergo.RenderInitialize(w, out) })
 		}
 		return err
 	}
 
 	newCmd := &cobra.Command{Use: "new", Short: "Create tasks and epics"}
-	newCmd.Args = func(cmd *cobra.Command, args []string) error {
-		if len(args) == 0 {
-			return nil
-		}
-		return fmt.Errorf("unknown command %q for %q", args[0], cmd.CommandPath())
-	}
+	newCmd.Args = subcommandArgs
 	newCmd.RunE = func(cmd *cobra.Command, _ []string) error { return cmd.Help() }
 
 	newTaskCmd := &cobra.Command{Use: `task "<title>"`, Short: "Create a task", Args: exactArgs(1, ergo.NewTaskUsage),
 		Annotations: map[string]string{commandInputHelp: "Optional piped stdin becomes the initial task body; no pipe creates an empty body."}}
 	newTaskCmd.Flags().String("epic", "", "Create the task in this epic")
 	newTaskCmd.Flags().Bool("draft", false, "Create the task as unavailable draft work")
+	newTaskCmd.Flags().String("priority", "", "Set the task priority: P0 (most urgent) to P3; default P2")
+	newTaskCmd.Flags().StringArray("label", nil, "Add a label (repeatable)")
 	newTaskCmd.RunE = func(cmd *cobra.Command, args []string) error {
 		if keys := legacyCreationKeys(args[0]); len(keys) > 0 {
 			guidance := `creation JSON is not accepted; use ergo new task "<title>"`
@@ -79,17 +85,19 @@ func addCommands(root *cobra.Command, base *ergo.Application, streams Streams, o
 			if hasAnyString(keys, "state", "claim", "result") {
 				guidance += ", then use claim, done, fail, block, cancel, or open for lifecycle data"
 			}
-			return errors.New(guidance)
+			return usageError(errors.New(guidance))
 		}
 		epic, _ := cmd.Flags().GetString("epic")
 		draft, _ := cmd.Flags().GetBool("draft")
+		priority, _ := cmd.Flags().GetString("priority")
+		labels, _ := cmd.Flags().GetStringArray("label")
 		body, err := commandInput(cmd, streams, false, "")
 		if err != nil {
 			return err
 		}
-		out, err := app().CreateTask(ergo.CreateTaskRequest{Title: args[0], EpicID: epic, Body: body, Draft: draft})
+		out, err := app().CreateTask(backlog.CreateTaskRequest{Title: args[0], EpicID: epic, Body: body, Draft: draft, Priority: priority, Labels: labels})
 		if err == nil {
-			ergo.RenderCreateTask(cmd.OutOrStdout(), out)
+			err = writeOutcome(cmd, out, func(w io.Writer) { ergo.RenderCreateTask(w, out) })
 		}
 		return err
 	}
@@ -100,7 +108,7 @@ func addCommands(root *cobra.Command, base *ergo.Application, streams Streams, o
 	newEpicCmd.Flags().Bool("draft", false, "Create every child as unavailable draft work")
 	newEpicCmd.RunE = func(cmd *cobra.Command, args []string) error {
 		if keys := legacyCreationKeys(args[0]); len(keys) > 0 {
-			return errors.New(`creation JSON is not accepted; use ergo new epic "<title>" --file <path>`)
+			return usageError(errors.New(`creation JSON is not accepted; use ergo new epic "<title>" --file <path>`))
 		}
 		file, _ := cmd.Flags().GetString("file")
 		draft, _ := cmd.Flags().GetBool("draft")
@@ -108,25 +116,33 @@ func addCommands(root *cobra.Command, base *ergo.Application, streams Streams, o
 		if err != nil {
 			return err
 		}
-		out, err := app().CreateEpic(ergo.CreateEpicRequest{Title: args[0], FilePath: file, Body: body, Draft: draft})
+		out, err := app().CreateEpic(backlog.CreateEpicRequest{Title: args[0], FilePath: file, Body: body, Draft: draft})
 		if err == nil {
-			ergo.RenderCreateEpic(cmd.OutOrStdout(), out)
+			err = writeOutcome(cmd, out, func(w io.Writer) { ergo.RenderCreateEpic(w, out) })
 		}
 		return err
 	}
 	newCmd.AddCommand(newTaskCmd, newEpicCmd)
 
-	listCmd := &cobra.Command{Use: "list", Short: "List tasks", Args: noArgs("list [--epic <id>] [--ready | --all]")}
+	listCmd := &cobra.Command{Use: "list", Short: "List tasks", Args: noArgs("list [--epic <id>] [--ready | --all] [--label <label>] [--not-label <label>] [--at <time>]")}
 	listCmd.Flags().String("epic", "", "Filter by epic ID")
 	listCmd.Flags().Bool("ready", false, "Show only ready tasks (conflicts with --all)")
 	listCmd.Flags().Bool("all", false, "Show all tasks, including canceled/done (conflicts with --ready)")
 	listCmd.Flags().Bool("json", false, "Write a versioned JSON task listing")
+	listCmd.Flags().StringArray("label", nil, "Show only tasks with this label (repeatable; all must match)")
+	listCmd.Flags().StringArray("not-label", nil, "Hide tasks with this label (repeatable)")
+	listCmd.Flags().String("at", "", "List the backlog as it stood at an RFC 3339 time or a duration ago (e.g. 36h)")
 	listCmd.RunE = func(cmd *cobra.Command, _ []string) error {
 		epic, _ := cmd.Flags().GetString("epic")
 		ready, _ := cmd.Flags().GetBool("ready")
 		all, _ := cmd.Flags().GetBool("all")
 		jsonOutput, _ := cmd.Flags().GetBool("json")
-		out, err := app().List(ergo.ListRequest{EpicID: epic, ReadyOnly: ready, ShowAll: all, OmitJournal: jsonOutput})
+		labels, _ := cmd.Flags().GetStringArray("label")
+		notLabels, _ := cmd.Flags().GetStringArray("not-label")
+		at, _ := cmd.Flags().GetString("at")
+		out, err := app().List(backlog.ListRequest{
+			EpicID: epic, ReadyOnly: ready, ShowAll: all, OmitJournal: jsonOutput, Labels: labels, NotLabels: notLabels, At: at,
+		})
 		if err == nil {
 			if jsonOutput {
 				return ergo.RenderListJSON(cmd.OutOrStdout(), out)
@@ -138,39 +154,229 @@ func addCommands(root *cobra.Command, base *ergo.Application, streams Streams, o
 
 	showCmd := &cobra.Command{Use: "show <id>", Short: "Show task details", Args: exactArgs(1, "usage: ergo show <id>")}
 	showCmd.Flags().Bool("body", false, "Write only the exact stored body, byte-for-byte")
+	showCmd.Flags().String("at", "", "Show the task as it stood at an RFC 3339 time or a duration ago (e.g. 36h)")
 	showCmd.RunE = func(cmd *cobra.Command, args []string) error {
 		bodyOnly, _ := cmd.Flags().GetBool("body")
+		at, _ := cmd.Flags().GetString("at")
 		if bodyOnly {
-			out, err := app().ShowBody(ergo.ShowBodyRequest{ID: args[0]})
+			out, err := app().ShowBody(backlog.ShowBodyRequest{ID: args[0], At: at})
 			if err != nil {
 				return err
 			}
+			if jsonRequested(cmd) {
+				return ergo.RenderOutcomeJSON(cmd.OutOrStdout(), out)
+			}
 			return ergo.RenderShowBody(cmd.OutOrStdout(), out)
 		}
-		out, err := app().Show(ergo.ShowRequest{ID: args[0]})
+		out, err := app().Show(backlog.ShowRequest{ID: args[0], At: at})
+		if err == nil {
+			err = writeOutcome(cmd, out, func(w io.Writer) { ergo.RenderShow(w, out, render(cmd).Color) })
+		}
+		return err
+	}
+
+	searchCmd := &cobra.Command{Use: "search <query>", Short: "Search task titles, bodies, and journal text", Args: exactArgs(1, "usage: ergo search <query> [--regex] [--state <state>] [--epic <id>] [--json]")}
+	searchCmd.Flags().Bool("regex", false, "Treat the query as a regular expression")
+	searchCmd.Flags().StringArray("state", nil, "Show only tasks in this state (repeatable)")
+	searchCmd.Flags().String("epic", "", "Search only this epic and its children")
+	searchCmd.Flags().Bool("json", false, "Write a versioned JSON result listing")
+	searchCmd.RunE = func(cmd *cobra.Command, args []string) error {
+		regex, _ := cmd.Flags().GetBool("regex")
+		states, _ := cmd.Flags().GetStringArray("state")
+		epic, _ := cmd.Flags().GetString("epic")
+		jsonOutput, _ := cmd.Flags().GetBool("json")
+		out, err := app().Search(backlog.SearchRequest{Query: args[0], Regex: regex, States: states, EpicID: epic})
+		if err != nil {
+			return err
+		}
+		if jsonOutput {
+			return ergo.RenderSearchJSON(cmd.OutOrStdout(), out)
+		}
+		ergo.RenderSearch(cmd.OutOrStdout(), out, render(cmd).Color)
+		return nil
+	}
+
+	historyCmd := &cobra.Command{Use: "history <id>", Short: "Show every recorded change to a task", Args: exactArgs(1, "usage: ergo history <id> [--json]")}
+	historyCmd.Flags().Bool("json", false, "Write a versioned JSON history")
+	historyCmd.RunE = func(cmd *cobra.Command, args []string) error {
+		jsonOutput, _ := cmd.Flags().GetBool("json")
+		out, err := app().History(backlog.HistoryRequest{ID: args[0]})
+		if err != nil {
+			return err
+		}
+		if jsonOutput {
+			return ergo.RenderHistoryJSON(cmd.OutOrStdout(), out)
+		}
+		ergo.RenderHistory(cmd.OutOrStdout(), out, render(cmd).Color)
+		return nil
+	}
+
+	graphCmd := &cobra.Command{Use: "graph", Short: "Export the dependency graph as Graphviz DOT or Mermaid", Args: noArgs("graph [--epic <id>] [--format dot|mermaid] [--all]")}
+	graphCmd.Flags().String("epic", "", "Draw only this epic and its children")
+	graphCmd.Flags().String("format", "dot", "Output format: dot or mermaid")
+	graphCmd.Flags().Bool("all", false, "Include canceled/done work, as list --all does")
+	graphCmd.RunE = func(cmd *cobra.Command, _ []string) error {
+		epic, _ := cmd.Flags().GetString("epic")
+		format, _ := cmd.Flags().GetString("format")
+		all, _ := cmd.Flags().GetBool("all")
+		if err := rejectJSON(cmd, "it writes DOT or Mermaid"); err != nil {
+			return err
+		}
+		out, err := app().ExportGraph(backlog.GraphRequest{EpicID: epic, Format: format, ShowAll: all})
 		if err == nil {
-			ergo.RenderShow(cmd.OutOrStdout(), out, render(cmd).Color)
+			ergo.RenderGraph(cmd.OutOrStdout(), out)
 		}
 		return err
 	}
 
-	claimCmd := &cobra.Command{Use: "claim [<id>]", Short: "Claim a task (or oldest ready task)"}
+	pathCmd := &cobra.Command{Use: "path <id>", Short: "Show the critical path gating a task or epic", Args: exactArgs(1, "usage: ergo path <id> [--json]")}
+	pathCmd.Flags().Bool("json", false, "Write a versioned JSON analysis")
+	pathCmd.RunE = func(cmd *cobra.Command, args []string) error {
+		jsonOutput, _ := cmd.Flags().GetBool("json")
+		out, err := app().Path(backlog.PathRequest{ID: args[0]})
+		if err != nil {
+			return err
+		}
+		if jsonOutput {
+			return ergo.RenderPathJSON(cmd.OutOrStdout(), out)
+		}
+		ergo.RenderPath(cmd.OutOrStdout(), out, render(cmd).Color)
+		return nil
+	}
+
+	statsCmd := &cobra.Command{Use: "stats", Short: "Report backlog state counts and recent throughput", Args: noArgs("stats [--since <time>] [--epic <id>] [--agent <identity>] [--json]")}
+	statsCmd.Flags().String("since", "7d", "Count journal activity since an RFC 3339 time or a duration ago (e.g. 7d)")
+	statsCmd.Flags().String("epic", "", "Report only this epic's children")
+	statsCmd.Flags().String("agent", "", "Report only this agent's activity and the tasks it claimed")
+	statsCmd.Flags().Bool("json", false, "Write a versioned JSON report")
+	statsCmd.RunE = func(cmd *cobra.Command, _ []string) error {
+		since, _ := cmd.Flags().GetString("since")
+		epic, _ := cmd.Flags().GetString("epic")
+		agent, _ := cmd.Flags().GetString("agent")
+		jsonOutput, _ := cmd.Flags().GetBool("json")
+		out, err := app().Stats(backlog.StatsRequest{Since: since, EpicID: epic, Agent: agent})
+		if err != nil {
+			return err
+		}
+		if jsonOutput {
+			return ergo.RenderStatsJSON(cmd.OutOrStdout(), out)
+		}
+		ergo.RenderStats(cmd.OutOrStdout(), out, render(cmd).Color)
+		return nil
+	}
+
+	watchCmd := &cobra.Command{Use: "watch", Short: "Stream backlog changes as they happen", Args: noArgs("watch [--json] [--ready]")}
+	watchCmd.Flags().Bool("json", false, "Write one versioned JSON object per change")
+	watchCmd.Flags().Bool("ready", false, "Report only tasks becoming ready")
+	watchCmd.RunE = func(cmd *cobra.Command, _ []string) error {
+		jsonOutput, _ := cmd.Flags().GetBool("json")
+		ready, _ := cmd.Flags().GetBool("ready")
+		stop, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer cancel()
+		return app().Watch(backlog.WatchRequest{ReadyOnly: ready, Stop: stop.Done()}, func(event backlog.WatchEvent) error {
+			if jsonOutput {
+				return ergo.RenderWatchEventJSON(cmd.OutOrStdout(), event)
+			}
+			ergo.RenderWatchEvent(cmd.OutOrStdout(), event, render(cmd).Color)
+			return nil
+		})
+	}
+
+	claimCmd := &cobra.Command{Use: "claim [<id>]", Short: "Claim a task (or the most urgent ready task)"}
 	claimCmd.Args = func(_ *cobra.Command, args []string) error {
 		if len(args) > 1 {
-			return errors.New("usage: ergo claim [<id>] --agent <identity>")
+			return usageError(errors.New("usage: ergo claim [<id>] --agent <identity> [--lease <duration>] [--label <label>] [--wait [--timeout <duration>]]"))
 		}
 		return nil
 	}
 	claimCmd.Flags().String("agent", "", "Claim identity (required; suggested: model@host)")
+	claimCmd.Flags().Duration("lease", 0, "Release the claim unless renewed within this duration (e.g. 30m)")
+	claimCmd.Flags().StringArray("label", nil, "Claim only ready tasks with this label (repeatable; all must match)")
+	claimCmd.Flags().Bool("wait", false, "When nothing is ready, wait for the backlog to change and retry")
+	claimCmd.Flags().Duration("timeout", 0, "Give up waiting after this duration (default: wait indefinitely)")
+	ifRevisionFlag(claimCmd)
 	claimCmd.RunE = func(cmd *cobra.Command, args []string) error {
 		agent, _ := cmd.Flags().GetString("agent")
+		lease, _ := cmd.Flags().GetDuration("lease")
+		labels, _ := cmd.Flags().GetStringArray("label")
+		wait, _ := cmd.Flags().GetBool("wait")
+		timeout, _ := cmd.Flags().GetDuration("timeout")
 		id := ""
 		if len(args) == 1 {
 			id = args[0]
 		}
-		out, err := app().Claim(ergo.ClaimRequest{ID: id, AgentID: agent})
+		out, err := app().Claim(backlog.ClaimRequest{ID: id, AgentID: agent, Lease: lease, Labels: labels, Wait: wait, Timeout: timeout, IfRevision: ifRevision(cmd)})
+		if err == nil {
+			err = writeOutcome(cmd, out, func(w io.Writer) { ergo.RenderClaim(w, out, render(cmd).Color) })
+		}
+		if err == nil && out.NoReady {
+			return &reportedExit{code: exitNoReady}
+		}
+		return err
+	}
+
+	heartbeatCmd := &cobra.Command{Use: "heartbeat <id>", Short: "Renew a leased claim", Args: exactArgs(1, "usage: ergo heartbeat <id> --agent <identity> [--lease <duration>]")}
+	heartbeatCmd.Flags().String("agent", "", "Identity holding the claim (required)")
+	heartbeatCmd.Flags().Duration("lease", 0, "New lease duration (default: the current lease)")
+	ifRevisionFlag(heartbeatCmd)
+	heartbeatCmd.RunE = func(cmd *cobra.Command, args []string) error {
+		agent, _ := cmd.Flags().GetString("agent")
+		lease, _ := cmd.Flags().GetDuration("lease")
+		out, err := app().Heartbeat(backlog.HeartbeatRequest{ID: args[0], AgentID: agent, Lease: lease, IfRevision: ifRevision(cmd)})
+		if err == nil {
+			err = writeOutcome(cmd, out, func(w io.Writer) { ergo.RenderHeartbeat(w, out) })
+		}
+		return err
+	}
+
+	batchCmd := &cobra.Command{Use: "batch", Short: "Apply JSONL operations from stdin as one transaction", Args: noArgs("batch < operations.jsonl"),
+		Annotations: map[string]string{commandInputHelp: "Piped stdin is required: one JSON operation per line. Every operation applies, or none does."}}
+	batchCmd.RunE = func(cmd *cobra.Command, _ []string) error {
+		if streams.StdinTerminal {
+			return usageError(errors.New(`batch requires piped stdin; example: echo '{"op":"create","title":"Write docs"}' | ergo batch`))
+		}
+		input, err := io.ReadAll(cmd.InOrStdin())
+		if err != nil {
+			return err
+		}
+		out, err := app().Batch(backlog.BatchRequest{Input: input})
 		if err == nil {
-			ergo.RenderClaim(cmd.OutOrStdout(), out, render(cmd).Color)
+			err = writeOutcome(cmd, out, func(w io.Writer) { ergo.RenderBatch(w, out) })
+		}
+		return err
+	}
+
+	importCmd := &cobra.Command{Use: "import", Short: "Import issues from another tracker", Args: noArgs("import beads <path> [--dry-run]")}
+	importCmd.RunE = func(cmd *cobra.Command, _ []string) error { return cmd.Help() }
+	importBeadsCmd := &cobra.Command{Use: "beads <path> [--dry-run]", Short: "Import a beads (bd) issues.jsonl as one transaction", Args: exactArgs(1, "usage: ergo import beads <path> [--dry-run]")}
+	importBeadsCmd.Flags().Bool("dry-run", false, "Report what would be imported and what cannot be mapped, without writing")
+	importBeadsCmd.RunE = func(cmd *cobra.Command, args []string) error {
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		out, err := app().ImportBeads(backlog.ImportBeadsRequest{Path: args[0], DryRun: dryRun})
+		if err == nil {
+			err = writeOutcome(cmd, out, func(w io.Writer) { ergo.RenderImportBeads(w, out) })
+		}
+		return err
+	}
+	importCmd.AddCommand(importBeadsCmd)
+
+	undoCmd := &cobra.Command{Use: "undo [<id>]", Short: "Revert the last transaction (or the last one touching a task)"}
+	undoCmd.Args = func(_ *cobra.Command, args []string) error {
+		if len(args) > 1 {
+			return usageError(errors.New("usage: ergo undo [<id>] [--agent <identity>]"))
+		}
+		return nil
+	}
+	undoCmd.Flags().String("agent", "", "Identity recorded on the undo journal entry")
+	undoCmd.RunE = func(cmd *cobra.Command, args []string) error {
+		agent, _ := cmd.Flags().GetString("agent")
+		id := ""
+		if len(args) == 1 {
+			id = args[0]
+		}
+		out, err := app().Undo(backlog.UndoRequest{ID: id, AgentID: agent})
+		if err == nil {
+			err = writeOutcome(cmd, out, func(w io.Writer) { ergo.RenderUndo(w, out) })
 		}
 		return err
 	}
@@ -182,14 +388,15 @@ func addCommands(root *cobra.Command, base *ergo.Application, streams Streams, o
 			Args:  exactArgs(1, fmt.Sprintf("usage: ergo %s <id> [-m <message>]", kind)),
 		}
 		cmd.Flags().StringArrayP("message", "m", nil, "Append a lifecycle message (repeatable)")
+		ifRevisionFlag(cmd)
 		cmd.RunE = func(cmd *cobra.Command, args []string) error {
 			if !streams.StdinTerminal {
-				return fmt.Errorf("%s does not read stdin; use ergo body %s to replace the body or -m <message> to add a lifecycle note", kind, args[0])
+				return usageError(fmt.Errorf("%s does not read stdin; use ergo body %s to replace the body or -m <message> to add a lifecycle note", kind, args[0]))
 			}
 			messages, _ := cmd.Flags().GetStringArray("message")
-			out, err := app().Lifecycle(ergo.LifecycleRequest{Kind: kind, ID: args[0], Messages: messages})
+			out, err := app().Lifecycle(backlog.LifecycleRequest{Kind: kind, ID: args[0], Messages: messages, IfRevision: ifRevision(cmd)})
 			if err == nil {
-				ergo.RenderLifecycle(cmd.OutOrStdout(), out)
+				err = writeOutcome(cmd, out, func(w io.Writer) { ergo.RenderLifecycle(w, out) })
 			}
 			return err
 		}
@@ -198,48 +405,120 @@ func addCommands(root *cobra.Command, base *ergo.Application, streams Streams, o
 
 	resultCmd := &cobra.Command{Use: `result <id> "<text>"`, Short: "Record a task result", Args: exactArgs(2, `usage: ergo result <id> "<text>" [--file <path>]`)}
 	resultCmd.Flags().String("file", "", "Attach an existing project-relative file")
+	ifRevisionFlag(resultCmd)
 	resultCmd.RunE = func(cmd *cobra.Command, args []string) error {
 		filePath, _ := cmd.Flags().GetString("file")
-		out, err := app().Result(ergo.ResultRequest{ID: args[0], Text: args[1], FilePath: filePath, FileSet: cmd.Flags().Changed("file")})
+		out, err := app().Result(backlog.ResultRequest{ID: args[0], Text: args[1], FilePath: filePath, FileSet: cmd.Flags().Changed("file"), IfRevision: ifRevision(cmd)})
 		if err == nil {
-			ergo.RenderResult(cmd.OutOrStdout(), out)
+			err = writeOutcome(cmd, out, func(w io.Writer) { ergo.RenderResult(w, out) })
 		}
 		return err
 	}
 
 	titleCmd := &cobra.Command{Use: "title <id> <title>", Short: "Replace a task title", Args: exactArgs(2, "usage: ergo title <id> <title>")}
+	ifRevisionFlag(titleCmd)
 	titleCmd.RunE = func(cmd *cobra.Command, args []string) error {
-		out, err := app().UpdateTitle(ergo.UpdateTitleRequest{ID: args[0], Title: args[1]})
+		out, err := app().UpdateTitle(backlog.UpdateTitleRequest{ID: args[0], Title: args[1], IfRevision: ifRevision(cmd)})
 		if err == nil {
-			ergo.RenderTitle(cmd.OutOrStdout(), out)
+			err = writeOutcome(cmd, out, func(w io.Writer) { ergo.RenderTitle(w, out) })
 		}
 		return err
 	}
+	priorityCmd := &cobra.Command{Use: "priority <id> <level>", Short: "Set a task priority (P0 most urgent to P3)", Args: exactArgs(2, "usage: ergo priority <id> <P0|P1|P2|P3>")}
+	ifRevisionFlag(priorityCmd)
+	priorityCmd.RunE = func(cmd *cobra.Command, args []string) error {
+		out, err := app().SetPriority(backlog.SetPriorityRequest{ID: args[0], Priority: args[1], IfRevision: ifRevision(cmd)})
+		if err == nil {
+			err = writeOutcome(cmd, out, func(w io.Writer) { ergo.RenderPriority(w, out) })
+		}
+		return err
+	}
+	labelCmd := &cobra.Command{Use: "label", Short: "Add or remove task labels"}
+	labelCmd.Args = newCmd.Args
+	labelCmd.RunE = func(cmd *cobra.Command, _ []string) error { return cmd.Help() }
+	labelChange := func(action, short string, remove bool) *cobra.Command {
+		cmd := &cobra.Command{Use: action + " <id> <label>...", Short: short}
+		cmd.Args = func(_ *cobra.Command, args []string) error {
+			if len(args) < 2 {
+				return usageError(fmt.Errorf("usage: ergo label %s <id> <label>...", action))
+			}
+			return nil
+		}
+		ifRevisionFlag(cmd)
+		cmd.RunE = func(cmd *cobra.Command, args []string) error {
+			out, err := app().Label(backlog.LabelRequest{ID: args[0], Labels: args[1:], Remove: remove, IfRevision: ifRevision(cmd)})
+			if err == nil {
+				err = writeOutcome(cmd, out, func(w io.Writer) { ergo.RenderLabels(w, out) })
+			}
+			return err
+		}
+		return cmd
+	}
+	labelCmd.AddCommand(labelChange("add", "Add labels to a task", false), labelChange("remove", "Remove labels from a task", true))
 	bodyCmd := &cobra.Command{Use: "body <id> [--append]", Short: "Replace or append to a task body from stdin", Args: exactArgs(1, "usage: printf '%s\\n' '<body>' | ergo body <id> [--append]"),
 		Annotations: map[string]string{commandInputHelp: "Piped stdin is required. By default it replaces the body; --append adds literal bytes, and empty append input is a no-op."}}
 	bodyCmd.Flags().Bool("append", false, "Append stdin bytes to the existing body")
+	ifRevisionFlag(bodyCmd)
 	bodyCmd.RunE = func(cmd *cobra.Command, args []string) error {
 		body, err := commandInput(cmd, streams, true, args[0])
 		if err != nil {
 			return err
 		}
 		appendBody, _ := cmd.Flags().GetBool("append")
-		out, err := app().UpdateBody(ergo.UpdateBodyRequest{ID: args[0], Body: []byte(body), Append: appendBody})
+		out, err := app().UpdateBody(backlog.UpdateBodyRequest{ID: args[0], Body: []byte(body), Append: appendBody, IfRevision: ifRevision(cmd)})
+		if err == nil {
+			err = writeOutcome(cmd, out, func(w io.Writer) { ergo.RenderBody(w, out) })
+		}
+		return err
+	}
+
+	editCmd := &cobra.Command{Use: "edit <id> [--title]", Short: "Edit a task body in $VISUAL or $EDITOR", Args: exactArgs(1, "usage: ergo edit <id> [--title]")}
+	editCmd.Flags().Bool("title", false, "Edit the title too, as the first line of the file")
+	editCmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if streams.Editor == nil {
+			return usageError(errors.New("edit needs an editor; set VISUAL or EDITOR"))
+		}
+		withTitle, _ := cmd.Flags().GetBool("title")
+		shown, err := app().Show(backlog.ShowRequest{ID: args[0]})
+		if err != nil {
+			return err
+		}
+		base := backlog.TaskText{Title: shown.Task.Title, Body: shown.Task.Body}
+		file, err := streams.Editor(ergo.FormatEditText(base, withTitle))
+		if err != nil {
+			return &backlog.Error{Kind: backlog.ErrorInternal, Err: err}
+		}
+		request := backlog.EditTaskRequest{ID: shown.Task.ID, Base: base, Edited: ergo.ParseEditText(file, withTitle), TitleSet: withTitle}
+		out, err := app().EditTask(request)
+		var conflict *backlog.EditConflict
+		if errors.As(err, &conflict) && !jsonRequested(cmd) {
+			ergo.RenderEditConflict(cmd.ErrOrStderr(), conflict, render(cmd).Color)
+			if conflict.Clean && confirm(cmd, streams, "Apply the merged text?") {
+				request.Base, request.Edited = conflict.Current, conflict.Merged
+				out, err = app().EditTask(request)
+			}
+		}
+		if errors.As(err, &conflict) {
+			if saved, saveErr := keepRejectedEdit(file); saveErr == nil {
+				err = fmt.Errorf("%w; your edit is saved in %s", err, saved)
+			}
+		}
 		if err == nil {
-			ergo.RenderBody(cmd.OutOrStdout(), out)
+			err = writeOutcome(cmd, out, func(w io.Writer) { ergo.RenderEdit(w, out) })
 		}
 		return err
 	}
 
 	moveCmd := &cobra.Command{Use: "move <id> <epic-id> | ergo move <id> --root", Short: "Move a task into an epic or to root"}
 	moveCmd.Flags().Bool("root", false, "Move the task out of its epic")
+	ifRevisionFlag(moveCmd)
 	moveCmd.Args = func(cmd *cobra.Command, args []string) error {
 		toRoot, _ := cmd.Flags().GetBool("root")
 		if toRoot && len(args) == 2 {
-			return errors.New("move destination and --root are mutually exclusive")
+			return usageError(errors.New("move destination and --root are mutually exclusive"))
 		}
 		if (toRoot && len(args) != 1) || (!toRoot && len(args) != 2) {
-			return errors.New("usage: ergo move <id> <epic-id> | ergo move <id> --root")
+			return usageError(errors.New("usage: ergo move <id> <epic-id> | ergo move <id> --root"))
 		}
 		return nil
 	}
@@ -249,19 +528,24 @@ func addCommands(root *cobra.Command, base *ergo.Application, streams Streams, o
 		if !rootFlag {
 			dest = args[1]
 		}
-		out, err := app().Move(ergo.MoveRequest{ID: args[0], DestinationID: dest, ToRoot: rootFlag})
+		out, err := app().Move(backlog.MoveRequest{ID: args[0], DestinationID: dest, ToRoot: rootFlag, IfRevision: ifRevision(cmd)})
 		if err == nil {
-			ergo.RenderMove(cmd.OutOrStdout(), out)
+			err = writeOutcome(cmd, out, func(w io.Writer) { ergo.RenderMove(w, out) })
 		}
 		return err
 	}
 
 	sequence := func(command, event, short string) *cobra.Command {
 		cmd := &cobra.Command{Use: command + " <A> <B> [<C>...]", Short: short}
+		cmd.Flags().StringArray("if-revision", nil, "Refuse the change unless task ID is still at revision REV (ID=REV, repeatable)")
 		cmd.RunE = func(cmd *cobra.Command, args []string) error {
-			out, err := app().Sequence(ergo.SequenceRequest{Command: command, EventType: event, IDs: args})
+			revisions, err := ifRevisions(cmd)
+			if err != nil {
+				return err
+			}
+			out, err := app().Sequence(backlog.SequenceRequest{Command: command, EventType: event, IDs: args, IfRevisions: revisions})
 			if err == nil {
-				ergo.RenderSequence(cmd.OutOrStdout(), out)
+				err = writeOutcome(cmd, out, func(w io.Writer) { ergo.RenderSequence(w, out) })
 			}
 			return err
 		}
@@ -271,7 +555,7 @@ func addCommands(root *cobra.Command, base *ergo.Application, streams Streams, o
 	whereCmd.RunE = func(cmd *cobra.Command, _ []string) error {
 		out, err := app().Where()
 		if err == nil {
-			ergo.RenderWhere(cmd.OutOrStdout(), out)
+			err = writeOutcome(cmd, out, func(w io.Writer) { ergo.RenderWhere(w, out) })
 		}
 		return err
 	}
@@ -281,9 +565,9 @@ func addCommands(root *cobra.Command, base *ergo.Application, streams Streams, o
 		if err != nil {
 			return err
 		}
-		out, err := app().Info(ergo.InfoRequest{Executable: executable, Version: buildVersion})
+		out, err := app().Info(backlog.InfoRequest{Executable: executable, Version: buildVersion})
 		if err == nil {
-			ergo.RenderInfo(cmd.OutOrStdout(), out)
+			err = writeOutcome(cmd, out, func(w io.Writer) { ergo.RenderInfo(w, out) })
 		}
 		return err
 	}
@@ -291,34 +575,138 @@ func addCommands(root *cobra.Command, base *ergo.Application, streams Streams, o
 	compactCmd.RunE = func(cmd *cobra.Command, _ []string) error {
 		out, err := app().Compact()
 		if err == nil {
-			ergo.RenderCompact(cmd.OutOrStdout(), out)
+			err = writeOutcome(cmd, out, func(w io.Writer) { ergo.RenderCompact(w, out) })
 		}
 		return err
 	}
-	pruneCmd := &cobra.Command{Use: "prune", Short: "Prune closed work (dry-run by default)", Args: noArgs("prune [--yes]")}
+	pruneCmd := &cobra.Command{Use: "prune", Short: "Prune closed work (dry-run by default)", Args: noArgs("prune [--yes] [--label <label>]")}
 	pruneCmd.Flags().Bool("yes", false, "Apply prune (default is dry-run)")
+	pruneCmd.Flags().StringArray("label", nil, "Prune only finished tasks with this label (repeatable; all must match)")
 	pruneCmd.RunE = func(cmd *cobra.Command, _ []string) error {
 		yes, _ := cmd.Flags().GetBool("yes")
-		out, err := app().Prune(ergo.PruneRequest{Confirm: yes})
+		labels, _ := cmd.Flags().GetStringArray("label")
+		out, err := app().Prune(backlog.PruneRequest{Confirm: yes, Labels: labels})
 		if err == nil {
-			ergo.RenderPrune(cmd.OutOrStdout(), out, render(cmd).Color, render(cmd).Width)
+			err = writeOutcome(cmd, out, func(w io.Writer) { ergo.RenderPrune(w, out, render(cmd).Color, render(cmd).Width) })
 		}
 		return err
 	}
+	fsckCmd := &cobra.Command{Use: "fsck", Short: "Check backlog and journal integrity", Args: noArgs("fsck")}
+	fsckCmd.Flags().Bool("repair", false, "Truncate interrupted writes and drop orphaned journal entries")
+	fsckCmd.Flags().Bool("json", false, "Write a versioned JSON report")
+	fsckCmd.RunE = func(cmd *cobra.Command, _ []string) error {
+		repair, _ := cmd.Flags().GetBool("repair")
+		jsonOutput, _ := cmd.Flags().GetBool("json")
+		out, err := app().Fsck(backlog.FsckRequest{Repair: repair})
+		if err != nil {
+			return err
+		}
+		if jsonOutput {
+			if err := ergo.RenderFsckJSON(cmd.OutOrStdout(), out); err != nil {
+				return err
+			}
+		} else {
+			ergo.RenderFsck(cmd.OutOrStdout(), out, render(cmd).Color)
+		}
+		return out.Failure()
+	}
+	mergeDriverCmd := &cobra.Command{Use: "merge-driver <base> <ours> <theirs>", Short: "Merge backlog or journal files for git",
+		Args: exactArgs(3, "usage: ergo merge-driver <base> <ours> <theirs>")}
+	mergeDriverCmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if err := rejectJSON(cmd, "git reads its exit status"); err != nil {
+			return err
+		}
+		out, err := app().MergeDriver(backlog.MergeDriverRequest{Base: args[0], Ours: args[1], Theirs: args[2]})
+		if err == nil {
+			ergo.RenderMergeDriver(cmd.OutOrStdout(), out)
+		}
+		return err
+	}
+	mcpCmd := &cobra.Command{Use: "mcp", Short: "Serve backlog tools over the Model Context Protocol on stdio", Args: noArgs("mcp")}
+	mcpCmd.RunE = func(cmd *cobra.Command, _ []string) error {
+		if err := rejectJSON(cmd, "it already speaks JSON-RPC"); err != nil {
+			return err
+		}
+		return ergo.NewMCPServer(app(), buildVersion).Serve(cmd.InOrStdin(), cmd.OutOrStdout())
+	}
+	serveCmd := &cobra.Command{Use: "serve", Short: "Serve the backlog over a local HTTP/JSON API", Args: noArgs("serve [--listen <address>] [--allow-remote]")}
+	serveCmd.Flags().String("listen", defaultServeAddress, "Listen on host:port or unix:<socket path>")
+	serveCmd.Flags().Bool("allow-remote", false, "Permit a listen address other than loopback")
+	serveCmd.RunE = func(cmd *cobra.Command, _ []string) error {
+		if err := rejectJSON(cmd, "it always answers with JSON documents"); err != nil {
+			return err
+		}
+		listen, _ := cmd.Flags().GetString("listen")
+		allowRemote, _ := cmd.Flags().GetBool("allow-remote")
+		network, address, err := serveAddress(listen, allowRemote)
+		if err != nil {
+			return err
+		}
+		listener, err := net.Listen(network, address)
+		if err != nil {
+			return &backlog.Error{Kind: backlog.ErrorInternal, Err: err}
+		}
+		host := ""
+		if network == "tcp" {
+			host, _, _ = net.SplitHostPort(address)
+		}
+		stop, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer cancel()
+		server := &http.Server{
+			Handler:           ergo.NewHTTPServer(app(), buildVersion, host),
+			BaseContext:       func(net.Listener) context.Context { return stop },
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+		go func() {
+			<-stop.Done()
+			_ = server.Shutdown(context.Background())
+		}()
+		fmt.Fprintf(cmd.OutOrStdout(), "Serving on %s\n", serveURL(listener.Addr()))
+		if err := server.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	}
+	tuiCmd := &cobra.Command{Use: "tui", Short: "Browse and act on the backlog full-screen", Args: noArgs("tui [--agent <identity>]")}
+	tuiCmd.Flags().String("agent", "", "Identity for claims made from the screen")
+	tuiCmd.RunE = func(cmd *cobra.Command, _ []string) error {
+		if err := rejectJSON(cmd, "it is an interactive screen"); err != nil {
+			return err
+		}
+		if streams.Screen == nil {
+			return usageError(errors.New("tui needs an interactive terminal on stdin and stdout; use ergo list or ergo watch in scripts"))
+		}
+		agent, _ := cmd.Flags().GetString("agent")
+		restore, err := streams.Screen.MakeRaw()
+		if err != nil {
+			return &backlog.Error{Kind: backlog.ErrorInternal, Err: err}
+		}
+		defer restore()
+		stop, cancel := signal.NotifyContext(context.Background(), syscall.SIGTERM)
+		defer cancel()
+		return ergo.RunTUI(app(), ergo.TUIOptions{AgentID: agent, Stop: stop.Done()}, ergo.TUITerminal{
+			In: cmd.InOrStdin(), Out: cmd.OutOrStdout(), Color: render(cmd).Color,
+			Size: streams.Screen.Size, Edit: streams.Screen.Edit,
+		})
+	}
 	quickCmd := &cobra.Command{Use: "quickstart", Short: "Show quickstart guide", Args: noArgs("quickstart")}
 	quickCmd.RunE = func(cmd *cobra.Command, _ []string) error {
-		ergo.RenderQuickstart(cmd.OutOrStdout(), app().Quickstart(ergo.QuickstartRequest{Color: render(cmd).Color}))
+		if err := rejectJSON(cmd, "it is a guide for reading"); err != nil {
+			return err
+		}
+		ergo.RenderQuickstart(cmd.OutOrStdout(), app().Quickstart(backlog.QuickstartRequest{Color: render(cmd).Color}))
 		return nil
 	}
 	versionCmd := &cobra.Command{Use: "version", Short: "Show version", Args: noArgs("version")}
-	versionCmd.Run = func(cmd *cobra.Command, _ []string) {
-		ergo.RenderVersion(cmd.OutOrStdout(), app().Version(ergo.VersionRequest{Version: buildVersion}))
+	versionCmd.RunE = func(cmd *cobra.Command, _ []string) error {
+		out := app().Version(backlog.VersionRequest{Version: buildVersion})
+		return writeOutcome(cmd, out, func(w io.Writer) { ergo.RenderVersion(w, out) })
 	}
 
-	root.AddCommand(initCmd, newCmd, listCmd, showCmd, claimCmd,
+	root.AddCommand(initCmd, newCmd, listCmd, showCmd, searchCmd, historyCmd, graphCmd, pathCmd, statsCmd, watchCmd, claimCmd, heartbeatCmd,
 		lifecycle("done", "Mark a task done"), lifecycle("fail", "Mark finished work failed"), lifecycle("block", "Mark a task blocked"), lifecycle("cancel", "Cancel a task"), lifecycle("open", "Return draft or blocked work to todo"),
-		resultCmd, titleCmd, bodyCmd, moveCmd, sequence("sequence", "link", "Enforce task order (A then B then C)"), sequence("unsequence", "unlink", "Remove task order (A then B then C)"),
-		whereCmd, infoCmd, compactCmd, pruneCmd, quickCmd, versionCmd)
+		resultCmd, titleCmd, priorityCmd, labelCmd, bodyCmd, editCmd, moveCmd, sequence("sequence", "link", "Enforce task order (A then B then C)"), sequence("unsequence", "unlink", "Remove task order (A then B then C)"), batchCmd, importCmd, undoCmd,
+		whereCmd, infoCmd, compactCmd, pruneCmd, fsckCmd, mergeDriverCmd, mcpCmd, serveCmd, tuiCmd, quickCmd, versionCmd)
 }
 
 func hasString(values []string, target string) bool {
@@ -337,10 +725,41 @@ func hasAnyString(values []string, targets ...string) bool {
 	}
 	return false
 }
+func jsonRequested(cmd *cobra.Command) bool {
+	requested, _ := cmd.Flags().GetBool("json")
+	return requested
+}
+
+// writeOutcome renders a successful outcome as its JSON document under --json
+// and as the readable receipt otherwise.
+func writeOutcome(cmd *cobra.Command, outcome any, text func(io.Writer)) error {
+	if jsonRequested(cmd) {
+		return ergo.RenderOutcomeJSON(cmd.OutOrStdout(), outcome)
+	}
+	text(cmd.OutOrStdout())
+	return nil
+}
+
+func rejectJSON(cmd *cobra.Command, reason string) error {
+	if jsonRequested(cmd) {
+		return usageError(fmt.Errorf("%s does not accept --json: %s", cmd.CommandPath(), reason))
+	}
+	return nil
+}
+
+// subcommandArgs lets a command group run bare, to print its help, and
+// rejects any argument that is not one of its subcommands.
+func subcommandArgs(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return nil
+	}
+	return usageError(&unknownCommandError{command: args[0], group: cmd.CommandPath()})
+}
+
 func noArgs(usage string) cobra.PositionalArgs {
 	return func(_ *cobra.Command, args []string) error {
 		if len(args) != 0 {
-			return fmt.Errorf("usage: ergo %s", usage)
+			return usageError(fmt.Errorf("usage: ergo %s", usage))
 		}
 		return nil
 	}
@@ -349,7 +768,7 @@ func noArgs(usage string) cobra.PositionalArgs {
 func exactArgs(count int, usage string) cobra.PositionalArgs {
 	return func(_ *cobra.Command, args []string) error {
 		if len(args) != count {
-			return errors.New(usage)
+			return usageError(errors.New(usage))
 		}
 		return nil
 	}
@@ -1,55 +1,87 @@
 package main
 
 import (
+	"bufio"
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"os"
 	"strings"
 	"syscall"
 
+	"github.com/sandover/ergo/v4/backlog"
 	"github.com/sandover/ergo/v4/internal/ergo"
+	"github.com/spf13/cobra"
 )
 
+// writeCommandError reports a failure as text with hints, or under --json as
+// one error document carrying its kind.
+func writeCommandError(w io.Writer, err error, args []string, jsonOutput bool) {
+	if jsonOutput {
+		_ = ergo.RenderErrorJSON(w, commandErrorKind(err), err)
+		return
+	}
+	writeCLIError(w, err, args)
+}
+
+// commandErrorKind classifies a command failure. The application classifies
+// every error it returns and the CLI marks its own argument rejections with
+// usageError, so anything else, such as a failed write to stdout, is internal.
+func commandErrorKind(err error) backlog.ErrorKind {
+	if kind, ok := backlog.KindOf(err); ok {
+		return kind
+	}
+	return backlog.ErrorInternal
+}
+
+// usageError marks err as the CLI rejecting its own arguments or flags.
+func usageError(err error) error {
+	return &backlog.Error{Kind: backlog.ErrorUsage, Err: err}
+}
+
 func writeCLIError(w io.Writer, err error, args []string) {
 	fmt.Fprintln(w, "error:", err)
 	var removed *removedCommandError
-	if handled := writeApplicationErrorHint(w, err, args); handled {
-	} else if errors.As(err, &removed) {
+	var unknown *unknownCommandError
+	if errors.As(err, &removed) {
 		switch removed.command {
 		case "set":
 			fmt.Fprintln(w, "hint: use claim, done, fail, block, cancel, open, title, body, or move")
 		case "reopen":
 			fmt.Fprintln(w, "hint: use claim <id> --agent <identity> to resume closed work")
 		}
+	} else if errors.As(err, &unknown) {
+		fmt.Fprintf(w, "hint: run `%s --help`\n", unknown.group)
+	} else if handled := writeApplicationErrorHint(w, err, args); handled {
 	} else if strings.HasPrefix(err.Error(), "usage:") {
 		fmt.Fprintf(w, "hint: run `%s --help`\n", helpInvocation(args))
-	} else if errors.Is(err, ergo.ErrNoErgoDir) {
+	} else if errors.Is(err, backlog.ErrNoErgoDir) {
 		fmt.Fprintln(w, "hint: run `ergo init` or target an existing graph with `ergo --dir <path>`")
 	} else if isPermissionError(err) {
 		fmt.Fprintln(w, "hint: permission error accessing .ergo/; check repo permissions (ergo needs read/write)")
 	} else if strings.Contains(err.Error(), ".ergo") && strings.Contains(err.Error(), "exists but is not a directory") {
 		fmt.Fprintln(w, "hint: .ergo must be a directory; delete/rename the file and run `ergo init`")
-	} else if errors.Is(err, ergo.ErrLockBusy) {
+	} else if errors.Is(err, backlog.ErrLockBusy) {
 		fmt.Fprintln(w, "hint: another ergo process is still running; try again in a moment")
 	}
 }
 
 func writeApplicationErrorHint(w io.Writer, err error, args []string) bool {
-	kind, ok := ergo.ApplicationErrorKind(err)
+	kind, ok := backlog.KindOf(err)
 	if !ok {
 		return false
 	}
 	switch kind {
-	case ergo.ErrorUsage:
+	case backlog.ErrorUsage:
 		fmt.Fprintf(w, "hint: run `%s --help`\n", helpInvocation(args))
-	case ergo.ErrorNotFound:
-		if errors.Is(err, ergo.ErrNoErgoDir) {
+	case backlog.ErrorNotFound:
+		if errors.Is(err, backlog.ErrNoErgoDir) {
 			fmt.Fprintln(w, "hint: run `ergo init` or target an existing graph with `ergo --dir <path>`")
 		}
-	case ergo.ErrorBusy:
+	case backlog.ErrorBusy:
 		fmt.Fprintln(w, "hint: another ergo process is still running; try again in a moment")
-	case ergo.ErrorInternal:
+	case backlog.ErrorInternal:
 		if isPermissionError(err) {
 			fmt.Fprintln(w, "hint: permission error accessing .ergo/; check repo permissions (ergo needs read/write)")
 		}
@@ -86,3 +118,95 @@ func isPermissionError(err error) bool {
 	return os.IsPermission(err) || errors.Is(err, os.ErrPermission) ||
 		errors.Is(err, syscall.EPERM) || errors.Is(err, syscall.EACCES)
 }
+
+// defaultServeAddress keeps ergo serve on the local machine.
+const defaultServeAddress = "127.0.0.1:7420"
+
+// serveAddress splits a --listen value into a network and address. TCP
+// addresses must name a loopback host unless allowRemote is set.
+func serveAddress(listen string, allowRemote bool) (string, string, error) {
+	if path, ok := strings.CutPrefix(listen, "unix:"); ok {
+		if path == "" {
+			return "", "", usageError(errors.New("--listen unix: needs a socket path"))
+		}
+		return "unix", path, nil
+	}
+	host, _, err := net.SplitHostPort(listen)
+	if err != nil {
+		return "", "", usageError(fmt.Errorf("invalid --listen %q: use host:port or unix:<path>", listen))
+	}
+	if !allowRemote && !isLoopbackHost(host) {
+		return "", "", usageError(fmt.Errorf("refusing to listen on %q: it is not a loopback address; pass --allow-remote to expose the backlog", listen))
+	}
+	return "tcp", listen, nil
+}
+
+func isLoopbackHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func serveURL(address net.Addr) string {
+	if address.Network() == "unix" {
+		return "unix:" + address.String()
+	}
+	return "http://" + address.String()
+}
+
+// confirm asks a yes/no question on stderr when stdin is a terminal; any
+// other stdin answers no.
+func confirm(cmd *cobra.Command, streams Streams, question string) bool {
+	if !streams.StdinTerminal {
+		return false
+	}
+	fmt.Fprintf(cmd.ErrOrStderr(), "%s [y/N] ", question)
+	answer, _ := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// keepRejectedEdit saves an edit that could not be applied and returns its
+// path, so a conflict never discards the user's work.
+func keepRejectedEdit(text string) (string, error) {
+	file, err := os.CreateTemp("", "ergo-edit-*.md")
+	if err != nil {
+		return "", err
+	}
+	if _, err := file.WriteString(text); err != nil {
+		file.Close()
+		return "", err
+	}
+	return file.Name(), file.Close()
+}
+
+// ifRevisionFlag lets a command that changes one task refuse to write once the
+// task has moved past the revision its caller read.
+func ifRevisionFlag(cmd *cobra.Command) {
+	cmd.Flags().String("if-revision", "", "Refuse the change unless the task is still at this revision")
+}
+
+func ifRevision(cmd *cobra.Command) string {
+	revision, _ := cmd.Flags().GetString("if-revision")
+	return revision
+}
+
+// ifRevisions reads the repeatable ID=REV form of --if-revision used by
+// commands that write more than one task.
+func ifRevisions(cmd *cobra.Command) (map[string]string, error) {
+	values, _ := cmd.Flags().GetStringArray("if-revision")
+	if len(values) == 0 {
+		return nil, nil
+	}
+	revisions := make(map[string]string, len(values))
+	for _, value := range values {
+		id, revision, ok := strings.Cut(value, "=")
+		if !ok || id == "" || revision == "" {
+			return nil, usageError(fmt.Errorf("invalid --if-revision %q: use ID=REV", value))
+		}
+		revisions[id] = revision
+	}
+	return revisions, nil
+}
@@ -48,3 +48,26 @@ func TestApplicationErrorHintPreservesPermissionGuidance(t *testing.T) {
 		t.Fatalf("hint = %q", output.String())
 	}
 }
+
+func TestServeAddressBindsOnlyLoopbackByDefault(t *testing.T) {
+	for _, test := range []struct {
+		listen, network string
+		allowRemote     bool
+	}{
+		{listen: "127.0.0.1:7420", network: "tcp"},
+		{listen: "[::1]:0", network: "tcp"},
+		{listen: "localhost:8080", network: "tcp"},
+		{listen: "unix:/tmp/ergo.sock", network: "unix"},
+		{listen: "0.0.0.0:7420", network: "tcp", allowRemote: true},
+		{listen: "0.0.0.0:7420"},
+		{listen: ":7420"},
+		{listen: "example.com:80"},
+		{listen: "7420"},
+		{listen: "unix:"},
+	} {
+		network, _, err := serveAddress(test.listen, test.allowRemote)
+		if network != test.network || (err == nil) != (test.network != "") {
+			t.Errorf("serveAddress(%q, %v) = %q, %v", test.listen, test.allowRemote, network, err)
+		}
+	}
+}
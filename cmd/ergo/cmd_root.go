@@ -6,6 +6,7 @@ import (
 	"io"
 	"strings"
 
+	"github.com/sandover/ergo/v4/backlog"
 	"github.com/sandover/ergo/v4/internal/ergo"
 	"github.com/spf13/cobra"
 )
@@ -21,16 +22,30 @@ type Streams struct {
 	NoColor        bool
 	Term           string
 	Width          int
+	// Screen is the interactive terminal behind ergo tui; nil unless stdin
+	// and stdout are both terminals.
+	Screen Screen
+	// Editor runs the user's editor on text and returns the saved text.
+	Editor func(text string) (string, error)
 }
 
-func NewRootCommand(app *ergo.Application, streams Streams, buildVersion string) *cobra.Command {
+// Screen is the process terminal as a full-screen program needs it.
+type Screen interface {
+	// MakeRaw switches input to raw mode until restore is called.
+	MakeRaw() (restore func() error, err error)
+	Size() (width, height int)
+	// Edit runs the user's editor on text and returns the saved text.
+	Edit(text string) (string, error)
+}
+
+func NewRootCommand(app *backlog.Application, streams Streams, buildVersion string) *cobra.Command {
 	if app == nil {
 		panic("NewRootCommand requires an application")
 	}
 	if streams.In == nil || streams.Out == nil || streams.Err == nil {
 		panic("NewRootCommand requires input, output, and error streams")
 	}
-	options := ergo.RepositoryOptions{}
+	options := backlog.Options{}
 	color := colorModeAuto
 	root := &cobra.Command{
 		Use: "ergo", Short: "A dependency-aware backlog for coding agents.",
@@ -40,8 +55,12 @@ func NewRootCommand(app *ergo.Application, streams Streams, buildVersion string)
 	root.SetIn(streams.In)
 	root.SetOut(streams.Out)
 	root.SetErr(streams.Err)
+	root.SetFlagErrorFunc(func(_ *cobra.Command, err error) error { return usageError(err) })
+	root.Args = subcommandArgs
+	root.RunE = func(cmd *cobra.Command, _ []string) error { return cmd.Help() }
 	root.PersistentFlags().StringVar(&options.StartDir, "dir", "", "Run in a specific directory")
 	root.PersistentFlags().Var(&color, "color", "Color output: auto, always, or never")
+	root.PersistentFlags().Bool("json", false, "Write a versioned JSON document instead of text")
 	root.SetHelpFunc(func(cmd *cobra.Command, _ []string) {
 		if cmd == root {
 			usage := ergo.UsageText(resolveColor(color, streams))
@@ -102,17 +121,73 @@ func resolveColor(mode colorMode, streams Streams) bool {
 
 func runCommand(root *cobra.Command, args []string, streams Streams) int {
 	if err := removedArgumentError(args); err != nil {
-		writeCLIError(streams.Err, err, args)
-		return 1
+		err = usageError(err)
+		writeCommandError(streams.Err, err, args, hasString(args, "--json"))
+		return exitCode(err)
 	}
 	root.SetArgs(args)
-	if err := root.Execute(); err != nil {
-		writeCLIError(streams.Err, err, args)
-		return 1
+	if cmd, err := root.ExecuteC(); err != nil {
+		var reported *reportedExit
+		if !errors.As(err, &reported) {
+			writeCommandError(streams.Err, err, args, jsonRequested(cmd) || hasString(args, "--json"))
+		}
+		return exitCode(err)
 	}
 	return 0
 }
 
+// Exit codes are part of the scripting contract documented in docs/spec.md;
+// never renumber them. exitTimeout follows timeout(1).
+const (
+	exitInternal   = 1
+	exitUsage      = 2
+	exitNotFound   = 3
+	exitConflict   = 4
+	exitBusy       = 5
+	exitCorruption = 6
+	exitNoReady    = 7
+	exitTimeout    = 124
+)
+
+func exitCode(err error) int {
+	var reported *reportedExit
+	if errors.As(err, &reported) {
+		return reported.code
+	}
+	switch commandErrorKind(err) {
+	case backlog.ErrorUsage:
+		return exitUsage
+	case backlog.ErrorNotFound:
+		return exitNotFound
+	case backlog.ErrorConflict:
+		return exitConflict
+	case backlog.ErrorBusy:
+		return exitBusy
+	case backlog.ErrorCorruption:
+		return exitCorruption
+	case backlog.ErrorTimeout:
+		return exitTimeout
+	default:
+		return exitInternal
+	}
+}
+
+// reportedExit ends a command whose outcome is already written with a nonzero
+// code and no error message, as when automatic claim finds nothing ready.
+type reportedExit struct{ code int }
+
+func (e *reportedExit) Error() string { return fmt.Sprintf("exit status %d", e.code) }
+
+// unknownCommandError names a subcommand its group does not have.
+type unknownCommandError struct {
+	command string
+	group   string
+}
+
+func (e *unknownCommandError) Error() string {
+	return fmt.Sprintf("unknown command %q for %q", e.command, e.group)
+}
+
 type removedCommandError struct {
 	command string
 	err     error
@@ -2,6 +2,8 @@ package main
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -99,8 +101,8 @@ func TestCommandRunnerPreservesRemovedErrorsAndExitCodes(t *testing.T) {
 				In: strings.NewReader(""), Out: &output, Err: &errors,
 				StdinTerminal: true, Width: 80,
 			}
-			if code := runCommand(freshRootWithStreams(streams, "test"), test.args, streams); code != 1 {
-				t.Fatalf("exit=%d, want 1", code)
+			if code := runCommand(freshRootWithStreams(streams, "test"), test.args, streams); code != exitUsage {
+				t.Fatalf("exit=%d, want 2", code)
 			}
 			if output.Len() != 0 {
 				t.Fatalf("stdout=%q, want empty", output.String())
@@ -157,3 +159,31 @@ func freshRoot(t *testing.T, version string) *cobra.Command {
 func freshRootWithStreams(streams Streams, version string) *cobra.Command {
 	return NewRootCommand(ergo.NewApplication(ergo.RepositoryOptions{}), streams, version)
 }
+
+func TestExitCodesFollowErrorKindsAndTheSpec(t *testing.T) {
+	codes := map[ergo.ErrorKind]int{
+		ergo.ErrorInternal: 1, ergo.ErrorUsage: 2, ergo.ErrorNotFound: 3, ergo.ErrorConflict: 4,
+		ergo.ErrorBusy: 5, ergo.ErrorCorruption: 6, ergo.ErrorTimeout: 124,
+	}
+	spec, err := os.ReadFile(filepath.Join("..", "..", "docs", "spec.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for kind, want := range codes {
+		if got := exitCode(&ergo.ApplicationError{Kind: kind, Err: errors.New("failed")}); got != want {
+			t.Errorf("exitCode(%s) = %d, want %d", kind, got, want)
+		}
+		if row := fmt.Sprintf("| `%d` | `%s` |", want, kind); !strings.Contains(string(spec), row) {
+			t.Errorf("docs/spec.md lacks exit code row %q", row)
+		}
+	}
+	if got := exitCode(usageError(errors.New("usage: ergo show <id>"))); got != exitUsage {
+		t.Errorf("CLI argument rejection exits %d, want %d", got, exitUsage)
+	}
+	if got := exitCode(errors.New("write /dev/stdout: broken pipe")); got != exitInternal {
+		t.Errorf("unclassified CLI error exits %d, want %d", got, exitInternal)
+	}
+	if got := exitCode(&reportedExit{code: exitNoReady}); got != 7 || !strings.Contains(string(spec), "| `7` | |") {
+		t.Errorf("no-ready claim exits %d, or the spec lacks its row", got)
+	}
+}
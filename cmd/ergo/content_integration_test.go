@@ -1,4 +1,4 @@
-// Purpose: Exercise direct title and body edits through the compiled CLI.
+// Purpose: Exercise direct title, body, priority, and label edits through the compiled CLI.
 // Exports: none.
 // Role: Black-box coverage for literal stdin, empty bodies, and containers.
 // Invariants: same-value edits append no events and affect no other fields.
@@ -168,3 +168,79 @@ func runErgoWithEmptyPipe(t *testing.T, dir string, args ...string) (string, str
 	t.Fatalf("run ergo: %v", err)
 	return "", "", -1
 }
+
+func TestPriorityCommandOrdersClaims(t *testing.T) {
+	dir := setupErgo(t)
+	first := createLifecycleTask(t, dir)
+	stdout, stderr, code := runNewTask(t, dir, "Urgent", "--priority", "p1")
+	if code != 0 {
+		t.Fatalf("create prioritized task failed: %s", stderr)
+	}
+	urgent := strings.TrimSpace(stdout)
+
+	stdout, stderr, code = runErgo(t, dir, "", "priority", first, "P0")
+	if code != 0 || stdout != first+" priority: P0\n" {
+		t.Fatalf("priority failed: stdout=%q stderr=%q", stdout, stderr)
+	}
+	before := countEventLines(t, dir)
+	stdout, _, code = runErgo(t, dir, "", "priority", first, "0")
+	if code != 0 || stdout != first+" priority unchanged: P0\n" || countEventLines(t, dir) != before {
+		t.Fatalf("priority no-op failed: code=%d stdout=%q", code, stdout)
+	}
+	_, stderr, code = runErgo(t, dir, "", "priority", urgent, "high")
+	if code == 0 || !strings.Contains(stderr, "invalid priority") {
+		t.Fatalf("invalid priority was accepted: code=%d stderr=%q", code, stderr)
+	}
+
+	stdout, _, code = runErgo(t, dir, "", "list", "--json")
+	if code != 0 || !strings.Contains(stdout, `"priority":"P0"`) || !strings.Contains(stdout, `"priority":"P1"`) {
+		t.Fatalf("list --json priorities: %s", stdout)
+	}
+	for _, want := range []string{first, urgent} {
+		stdout, stderr, code = runErgo(t, dir, "", "claim", "--agent", "agent")
+		if code != 0 || !strings.Contains(stdout, want) {
+			t.Fatalf("claim order: want %s, stdout=%s stderr=%s", want, stdout, stderr)
+		}
+	}
+}
+
+func TestLabelCommandsFilterListAndClaim(t *testing.T) {
+	dir := setupErgo(t)
+	stdout, stderr, code := runNewTask(t, dir, "Frontend", "--label", "frontend")
+	if code != 0 {
+		t.Fatalf("create labeled task failed: %s", stderr)
+	}
+	frontend := strings.TrimSpace(stdout)
+	backend := createLifecycleTask(t, dir)
+
+	stdout, stderr, code = runErgo(t, dir, "", "label", "add", backend, "Backend", "infra")
+	if code != 0 || stdout != backend+" labels: backend, infra\n" {
+		t.Fatalf("label add failed: stdout=%q stderr=%q", stdout, stderr)
+	}
+	before := countEventLines(t, dir)
+	stdout, _, code = runErgo(t, dir, "", "label", "remove", backend, "missing")
+	if code != 0 || stdout != backend+" labels unchanged: backend, infra\n" || countEventLines(t, dir) != before {
+		t.Fatalf("label no-op failed: code=%d stdout=%q", code, stdout)
+	}
+	_, stderr, code = runErgo(t, dir, "", "label", "add", backend)
+	if code == 0 || !strings.Contains(stderr, "usage: ergo label add <id> <label>...") {
+		t.Fatalf("label add without labels: code=%d stderr=%q", code, stderr)
+	}
+
+	stdout, _, code = runErgo(t, dir, "", "list", "--json", "--not-label", "infra")
+	if code != 0 || !strings.Contains(stdout, frontend) || strings.Contains(stdout, backend) {
+		t.Fatalf("list --not-label: %s", stdout)
+	}
+	stdout, _, code = runErgo(t, dir, "", "list", "--label", "backend")
+	if code != 0 || !strings.Contains(stdout, "#backend") || strings.Contains(stdout, frontend) {
+		t.Fatalf("list --label: %s", stdout)
+	}
+	stdout, stderr, code = runErgo(t, dir, "", "claim", "--agent", "agent", "--label", "frontend")
+	if code != 0 || !strings.Contains(stdout, frontend) || !strings.Contains(stdout, `labels: ["frontend"]`) {
+		t.Fatalf("claim --label: stdout=%s stderr=%s", stdout, stderr)
+	}
+	stdout, _, code = runErgo(t, dir, "", "claim", "--agent", "agent", "--label", "frontend")
+	if code != 7 || stdout != "No ready ergo tasks labeled frontend.\n" {
+		t.Fatalf("exhausted claim --label: %q", stdout)
+	}
+}
@@ -680,7 +680,7 @@ func TestCreationMigrationErrorsAndBraceTitles(t *testing.T) {
 	before := countEventLines(t, dir)
 
 	_, stderr, code := runErgo(t, dir, "x", "new", "task")
-	if code != 1 || !strings.Contains(stderr, `usage: ergo new task "<title>"`) {
+	if code != 2 || !strings.Contains(stderr, `usage: ergo new task "<title>"`) {
 		t.Fatalf("missing title: code=%d stderr=%q", code, stderr)
 	}
 	for _, input := range []string{
@@ -688,7 +688,7 @@ func TestCreationMigrationErrorsAndBraceTitles(t *testing.T) {
 		`{"claim":"agent@local"}`, `{"result":"result.txt"}`,
 	} {
 		_, stderr, code = runErgo(t, dir, "", "new", "task", input)
-		if code != 1 || !strings.Contains(stderr, "creation JSON is not accepted") {
+		if code != 2 || !strings.Contains(stderr, "creation JSON is not accepted") {
 			t.Fatalf("input=%q code=%d stderr=%q", input, code, stderr)
 		}
 	}
@@ -714,7 +714,7 @@ func TestNewEpicRejectsLegacyCreationJSON(t *testing.T) {
 
 func TestParentCommandsRejectUnexpectedArguments(t *testing.T) {
 	dir := setupErgo(t)
-	for _, args := range [][]string{{"list", "extra"}, {"info", "extra"}, {"quickstart", "extra"}, {"version", "extra"}, {"new", "extra"}} {
+	for _, args := range [][]string{{"list", "extra"}, {"info", "extra"}, {"quickstart", "extra"}, {"version", "extra"}, {"new", "extra"}, {"label", "extra"}} {
 		_, stderr, code := runErgo(t, dir, "", args...)
 		if code == 0 {
 			t.Fatalf("%v accepted unexpected arguments; stderr=%q", args, stderr)
@@ -784,7 +784,7 @@ func TestPathAndCreationConfirmations(t *testing.T) {
 func TestNoReadyClaimIsReadable(t *testing.T) {
 	dir := setupErgo(t)
 	stdout, stderr, code := runErgo(t, dir, "", "claim", "--agent", "agent@local")
-	if code != 0 || stdout != "No ready ergo tasks.\n" || stderr != "" {
+	if code != 7 || stdout != "No ready ergo tasks.\n" || stderr != "" {
 		t.Fatalf("code=%d stdout=%q stderr=%q", code, stdout, stderr)
 	}
 }
@@ -1027,6 +1027,579 @@ func TestListJSONEmptyAndPopulated(t *testing.T) {
 	}
 }
 
+func TestSearchTextAndJSON(t *testing.T) {
+	dir := setupErgo(t)
+	stdout, stderr, code := runErgo(t, dir, "", "search", "anything")
+	if code != 0 || stdout != "No tasks match \"anything\".\n" {
+		t.Fatalf("empty search: code=%d stdout=%q stderr=%q", code, stdout, stderr)
+	}
+	bodyOutput, _, _ := runErgo(t, dir, "Apply the Migration.\n", "new", "task", "Deploy")
+	bodyID := strings.TrimSpace(bodyOutput)
+	titleOutput, _, _ := runNewTask(t, dir, "Migration plan")
+	titleID := strings.TrimSpace(titleOutput)
+
+	stdout, stderr, code = runErgo(t, dir, "", "search", "MIGRATION")
+	want := titleID + "  todo  Migration plan  (title)\n" +
+		bodyID + "  todo  Deploy  (body)\n" +
+		"        body: Apply the Migration.\n\n2 matching tasks\n"
+	if code != 0 || stdout != want {
+		t.Fatalf("search: code=%d stdout=%q stderr=%q", code, stdout, stderr)
+	}
+
+	stdout, stderr, code = runErgo(t, dir, "", "search", "--json", "--regex", "apply|plan")
+	var document struct {
+		Version int    `json:"version"`
+		Query   string `json:"query"`
+		Items   []struct {
+			ID      string   `json:"id"`
+			Matches []string `json:"matches"`
+		} `json:"items"`
+	}
+	if code != 0 {
+		t.Fatalf("search --json: %s", stderr)
+	}
+	if err := json.Unmarshal([]byte(stdout), &document); err != nil {
+		t.Fatalf("decode search: %v", err)
+	}
+	if document.Version != 1 || document.Query != "apply|plan" || len(document.Items) != 2 || document.Items[0].ID != titleID {
+		t.Fatalf("document = %#v", document)
+	}
+}
+
+func TestGitMergeDriverMergesDivergedBranches(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	git := func(args ...string) string {
+		t.Helper()
+		output, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, output)
+		}
+		return string(output)
+	}
+	git("init", "-b", "main")
+	git("config", "user.name", "Ergo Test")
+	git("config", "user.email", "ergo@example.invalid")
+	stdout, stderr, code := runErgo(t, dir, "", "init", "--git-merge")
+	if code != 0 || !strings.Contains(stdout, `Configured git merge driver "ergo"`) {
+		t.Fatalf("init --git-merge: code=%d stdout=%q stderr=%q", code, stdout, stderr)
+	}
+	// The installed driver runs "ergo" from PATH; point it at the test build.
+	git("config", "merge.ergo.driver", ergoBinary+" merge-driver %O %A %B")
+	firstOutput, _, _ := runNewTask(t, dir, "First")
+	first := strings.TrimSpace(firstOutput)
+	git("add", "-A")
+	git("commit", "-m", "base")
+
+	git("checkout", "-b", "feature")
+	featureOutput, _, _ := runNewTask(t, dir, "Feature work")
+	feature := strings.TrimSpace(featureOutput)
+	git("commit", "-am", "feature")
+	git("checkout", "main")
+	if _, stderr, code := runErgo(t, dir, "", "claim", first, "--agent", "main@host"); code != 0 {
+		t.Fatalf("claim: %s", stderr)
+	}
+	git("commit", "-am", "claim")
+
+	git("merge", "--no-edit", "feature")
+	stdout, stderr, code = runErgo(t, dir, "", "list", "--all")
+	if code != 0 || !strings.Contains(stdout, first) || !strings.Contains(stdout, feature) {
+		t.Fatalf("merged list: code=%d stdout=%q stderr=%q", code, stdout, stderr)
+	}
+	if fields := showTaskFields(t, dir, first); fields["claimed_by"] != "main@host" {
+		t.Fatalf("merged claim fields = %v", fields)
+	}
+
+	git("checkout", "-b", "rival")
+	if _, stderr, code := runErgo(t, dir, "", "claim", feature, "--agent", "rival@host"); code != 0 {
+		t.Fatalf("rival claim: %s", stderr)
+	}
+	git("commit", "-am", "rival claim")
+	git("checkout", "main")
+	if _, stderr, code := runErgo(t, dir, "", "claim", feature, "--agent", "main@host"); code != 0 {
+		t.Fatalf("main claim: %s", stderr)
+	}
+	git("commit", "-am", "main claim")
+	output, err := exec.Command("git", "-C", dir, "merge", "--no-edit", "rival").CombinedOutput()
+	if err == nil || !strings.Contains(string(output), "claimed on both branches") {
+		t.Fatalf("double-claim merge succeeded or lacked the conflict: %v\n%s", err, output)
+	}
+}
+
+func TestFsckExitStatusAndJSONReport(t *testing.T) {
+	dir := setupErgo(t)
+	runNewTask(t, dir, "Healthy")
+	stdout, stderr, code := runErgo(t, dir, "", "fsck")
+	if code != 0 || stdout != "Checked 1 backlog record and 1 journal entry: no problems\n" {
+		t.Fatalf("healthy fsck: code=%d stdout=%q stderr=%q", code, stdout, stderr)
+	}
+
+	backlogPath := filepath.Join(dir, ".ergo", "backlog.jsonl")
+	file, err := os.OpenFile(backlogPath, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := file.WriteString("not json\n{\"type\":\"trans"); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+	stdout, stderr, code = runErgo(t, dir, "", "fsck", "--json", "--repair")
+	if code == 0 || !strings.Contains(stderr, "fsck found 1 error") {
+		t.Fatalf("corrupt fsck: code=%d stderr=%q", code, stderr)
+	}
+	var report struct {
+		Version  int `json:"version"`
+		Errors   int `json:"errors"`
+		Warnings int `json:"warnings"`
+		Problems []struct {
+			Line     int    `json:"line"`
+			Code     string `json:"code"`
+			Repaired bool   `json:"repaired"`
+		} `json:"problems"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("decode fsck report: %v\n%s", err, stdout)
+	}
+	if report.Version != 1 || report.Errors != 1 || report.Warnings != 0 || len(report.Problems) != 2 ||
+		report.Problems[0].Code != "malformed_record" || report.Problems[0].Line != 2 || !report.Problems[1].Repaired {
+		t.Fatalf("fsck report = %+v", report)
+	}
+}
+
+func TestUndoRevertsTheLastTransaction(t *testing.T) {
+	dir := setupErgo(t)
+	stdout, _, _ := runNewTask(t, dir, "Original")
+	id := strings.TrimSpace(stdout)
+	if _, stderr, code := runErgo(t, dir, "", "title", id, "Renamed"); code != 0 {
+		t.Fatalf("title: %s", stderr)
+	}
+	stdout, stderr, code := runErgo(t, dir, "", "undo", "--agent", "reviewer")
+	if code != 0 || !strings.HasPrefix(stdout, "Undid title from ") || !strings.Contains(stdout, id+" restored: title\n") {
+		t.Fatalf("undo: code=%d stdout=%q stderr=%q", code, stdout, stderr)
+	}
+	if fields := showTaskFields(t, dir, id); fields["title"] != "Original" {
+		t.Fatalf("restored fields = %v", fields)
+	}
+	if _, stderr, code := runErgo(t, dir, "", "undo", id, "extra"); code == 0 || !strings.Contains(stderr, "usage: ergo undo") {
+		t.Fatalf("extra args: code=%d stderr=%q", code, stderr)
+	}
+}
+
+func TestListAtShowsThePastBacklog(t *testing.T) {
+	dir := setupErgo(t)
+	runNewTask(t, dir, "Early")
+	before := time.Now().UTC().Format(time.RFC3339Nano)
+	runNewTask(t, dir, "Late")
+	stdout, stderr, code := runErgo(t, dir, "", "list", "--json", "--at", before)
+	if code != 0 || !strings.Contains(stdout, `"Early"`) || strings.Contains(stdout, `"Late"`) {
+		t.Fatalf("list --at: code=%d stdout=%q stderr=%q", code, stdout, stderr)
+	}
+	if _, stderr, code := runErgo(t, dir, "", "list", "--at", "last week"); code == 0 || !strings.Contains(stderr, "invalid --at") {
+		t.Fatalf("bad --at: code=%d stderr=%q", code, stderr)
+	}
+}
+
+func TestHistoryShowsTitleDiffs(t *testing.T) {
+	dir := setupErgo(t)
+	stdout, _, _ := runNewTask(t, dir, "Before")
+	id := strings.TrimSpace(stdout)
+	if _, stderr, code := runErgo(t, dir, "", "title", id, "After"); code != 0 {
+		t.Fatalf("title: %s", stderr)
+	}
+	stdout, stderr, code := runErgo(t, dir, "", "history", id)
+	if code != 0 || !strings.Contains(stdout, "backlog.jsonl:2  title  title changed\n    -Before\n    +After\n") {
+		t.Fatalf("history: code=%d stdout=%q stderr=%q", code, stdout, stderr)
+	}
+	if _, stderr, code := runErgo(t, dir, "", "history", "ZZZZZZ"); code == 0 || !strings.Contains(stderr, "unknown task id ZZZZZZ") {
+		t.Fatalf("unknown history: code=%d stderr=%q", code, stderr)
+	}
+}
+
+func TestMCPServesToolsOverStdio(t *testing.T) {
+	dir := setupErgo(t)
+	stdin := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18"}}
+{"jsonrpc":"2.0","method":"notifications/initialized"}
+{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"create_task","arguments":{"title":"From MCP"}}}
+`
+	stdout, stderr, code := runErgo(t, dir, stdin, "mcp")
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	if code != 0 || len(lines) != 2 || !strings.Contains(lines[0], `"serverInfo":{"name":"ergo"`) || !strings.Contains(lines[1], `"isError":false`) {
+		t.Fatalf("mcp: code=%d stdout=%q stderr=%q", code, stdout, stderr)
+	}
+	stdout, _, _ = runErgo(t, dir, "", "list")
+	if !strings.Contains(stdout, "From MCP") {
+		t.Fatalf("list after mcp create = %q", stdout)
+	}
+}
+
+func TestNewEpicAfterLinesCreateDependencies(t *testing.T) {
+	dir := setupErgo(t)
+	stdout, stderr, code := runNewEpic(t, dir, "# Schema\n---\n# Endpoints\nAfter: Schema\n", "Auth")
+	if code != 0 {
+		t.Fatalf("new epic failed: exit %d, stderr=%s", code, stderr)
+	}
+	ids := outputIDs(stdout)
+	if len(ids) < 3 || !strings.Contains(stdout, ids[2]+" depends on "+ids[1]) || !strings.Contains(stdout, "2 tasks, 1 dependencies") {
+		t.Fatalf("unexpected epic receipt: %s", stdout)
+	}
+
+	_, stderr, code = runNewEpic(t, dir, "# Orphan\nAfter: Nowhere\n", "Rejected")
+	if code == 0 || !strings.Contains(stderr, `"Nowhere"`) {
+		t.Fatalf("unknown After reference: exit %d, stderr=%s", code, stderr)
+	}
+}
+
+func TestGraphExportsMermaid(t *testing.T) {
+	dir := setupErgo(t)
+	stdout, stderr, code := runNewEpic(t, dir, "# Schema\n---\n# Endpoints\nAfter: Schema\n", "Auth")
+	if code != 0 {
+		t.Fatalf("new epic failed: exit %d, stderr=%s", code, stderr)
+	}
+	ids := outputIDs(stdout)
+	stdout, stderr, code = runErgo(t, dir, "", "graph", "--format", "mermaid", "--epic", ids[0])
+	if code != 0 {
+		t.Fatalf("graph failed: exit %d, stderr=%s", code, stderr)
+	}
+	if !strings.HasPrefix(stdout, "```mermaid\nflowchart LR\n") || !strings.Contains(stdout, ids[1]+" --> "+ids[2]) {
+		t.Fatalf("unexpected mermaid graph:\n%s", stdout)
+	}
+}
+
+func TestPathShowsTheCriticalPath(t *testing.T) {
+	dir := setupErgo(t)
+	stdout, stderr, code := runNewEpic(t, dir, "# Schema\n---\n# Endpoints\nAfter: Schema\n---\n# Docs\n", "Auth")
+	if code != 0 {
+		t.Fatalf("new epic failed: exit %d, stderr=%s", code, stderr)
+	}
+	ids := outputIDs(stdout)
+	stdout, stderr, code = runErgo(t, dir, "", "path", ids[0])
+	if code != 0 {
+		t.Fatalf("path failed: exit %d, stderr=%s", code, stderr)
+	}
+	for _, fact := range []string{"Critical path: 2 tasks", "* " + ids[1] + " → " + ids[2], "Ready now: 2 of 3 unfinished tasks"} {
+		if !strings.Contains(stdout, fact) {
+			t.Fatalf("path output lacks %q:\n%s", fact, stdout)
+		}
+	}
+}
+
+func TestStatsReportsThroughput(t *testing.T) {
+	dir := setupErgo(t)
+	stdout, _, code := runNewTask(t, dir, "Ship it")
+	if code != 0 {
+		t.Fatalf("new task failed: exit %d", code)
+	}
+	id := strings.TrimSpace(stdout)
+	if _, stderr, code := runErgo(t, dir, "", "claim", id, "--agent", "worker"); code != 0 {
+		t.Fatalf("claim failed: %s", stderr)
+	}
+	if _, stderr, code := runErgo(t, dir, "", "done", id); code != 0 {
+		t.Fatalf("done failed: %s", stderr)
+	}
+	stdout, stderr, code := runErgo(t, dir, "", "stats", "--since", "2d", "--json")
+	if code != 0 {
+		t.Fatalf("stats failed: exit %d, stderr=%s", code, stderr)
+	}
+	for _, fact := range []string{`"done":1`, `"agent":"worker","claims":1,"done":1`} {
+		if !strings.Contains(stdout, fact) {
+			t.Fatalf("stats JSON lacks %s: %s", fact, stdout)
+		}
+	}
+}
+
+func TestClaimWaitTimesOutWithDistinctExitCode(t *testing.T) {
+	dir := setupErgo(t)
+	_, stderr, code := runErgo(t, dir, "", "claim", "--agent", "worker", "--wait", "--timeout", "50ms")
+	if code != 124 || !strings.Contains(stderr, "no ready task within 50ms") {
+		t.Fatalf("claim --wait timeout: exit %d, stderr=%s", code, stderr)
+	}
+	if _, stderr, code := runErgo(t, dir, "", "claim", "--agent", "worker", "--timeout", "1s"); code != 2 || !strings.Contains(stderr, "--timeout requires --wait") {
+		t.Fatalf("--timeout without --wait: exit %d, stderr=%s", code, stderr)
+	}
+}
+
+func TestGlobalJSONFlagWritesDocumentsAndErrors(t *testing.T) {
+	dir := setupErgo(t)
+	stdout, _, code := runNewTask(t, dir, "Ship it")
+	if code != 0 {
+		t.Fatalf("new task failed: exit %d", code)
+	}
+	id := strings.TrimSpace(stdout)
+	stdout, stderr, code := runErgo(t, dir, "", "--json", "claim", "--agent", "worker")
+	if code != 0 || !strings.HasPrefix(stdout, `{"version":1,"task":{"id":"`+id+`"`) || !strings.Contains(stdout, `"claimed_by":"worker"`) {
+		t.Fatalf("claim --json: exit %d, stdout=%s, stderr=%s", code, stdout, stderr)
+	}
+	stdout, stderr, code = runErgo(t, dir, "", "show", "ZZZZZZ", "--json")
+	if code == 0 || stdout != "" || stderr != `{"version":1,"error":{"kind":"not_found","message":"unknown task id ZZZZZZ"}}`+"\n" {
+		t.Fatalf("show --json failure: exit %d, stdout=%s, stderr=%s", code, stdout, stderr)
+	}
+	if _, stderr, code := runErgo(t, dir, "", "graph", "--json"); code == 0 || !strings.Contains(stderr, `"kind":"usage"`) {
+		t.Fatalf("graph --json: exit %d, stderr=%s", code, stderr)
+	}
+}
+
+func TestFailuresExitWithTheirKindCode(t *testing.T) {
+	dir := setupErgo(t)
+	stdout, _, code := runNewTask(t, dir, "Owned")
+	if code != 0 {
+		t.Fatalf("new task failed: exit %d", code)
+	}
+	id := strings.TrimSpace(stdout)
+	if _, stderr, code := runErgo(t, dir, "", "claim", id, "--agent", "first"); code != 0 {
+		t.Fatalf("claim failed: %s", stderr)
+	}
+	for _, test := range []struct {
+		args []string
+		want int
+	}{
+		{[]string{"show"}, 2},
+		{[]string{"list", "--no-such-flag"}, 2},
+		{[]string{"no-such-command"}, 2},
+		{[]string{"new", "no-such-kind"}, 2},
+		{[]string{"serve", "--listen", "nowhere"}, 2},
+		{[]string{"show", "ZZZZZZ"}, 3},
+		{[]string{"claim", id, "--agent", "second"}, 4},
+		{[]string{"claim", "--agent", "second"}, 7},
+	} {
+		if _, stderr, code := runErgo(t, dir, "", test.args...); code != test.want {
+			t.Errorf("ergo %s exited %d, want %d; stderr=%s", strings.Join(test.args, " "), code, test.want, stderr)
+		}
+	}
+	stdout, _, code = runNewTask(t, dir, "Other")
+	if code != 0 {
+		t.Fatalf("new task failed: exit %d", code)
+	}
+	other := strings.TrimSpace(stdout)
+	for _, test := range []struct {
+		args []string
+		want int
+	}{
+		{[]string{"sequence", id, "ZZZZZZ"}, 3},
+		{[]string{"unsequence", "ZZZZZZ", id}, 3},
+		{[]string{"sequence", id, id}, 2},
+		{[]string{"sequence", id, other}, 0},
+		{[]string{"sequence", other, id}, 4},
+	} {
+		if _, stderr, code := runErgo(t, dir, "", test.args...); code != test.want {
+			t.Errorf("ergo %s exited %d, want %d; stderr=%s", strings.Join(test.args, " "), code, test.want, stderr)
+		}
+	}
+	if _, stderr, code := runErgo(t, dir, `{"op":"sequence","ids":["`+other+`","`+id+`"]}`+"\n", "batch"); code != 4 || !strings.Contains(stderr, "line 1: sequence: dependency would create a cycle") {
+		t.Errorf("cyclic batch line exited %d, want 4; stderr=%s", code, stderr)
+	}
+	if _, _, code := runErgo(t, t.TempDir(), "", "list"); code != 3 {
+		t.Errorf("list without .ergo exited %d, want 3", code)
+	}
+	backlog := filepath.Join(dir, ".ergo", "backlog.jsonl")
+	file, err := os.OpenFile(backlog, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := file.WriteString("not json\n"); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+	if _, stderr, code := runErgo(t, dir, "", "list"); code != 6 {
+		t.Errorf("list on a corrupt backlog exited %d, want 6; stderr=%s", code, stderr)
+	}
+}
+
+func TestBatchMapsHandlesAndRefusesPartialWrites(t *testing.T) {
+	dir := setupErgo(t)
+	input := `{"op":"create","handle":"api","title":"Build the API"}
+{"op":"create","handle":"docs","title":"Document the API"}
+{"op":"sequence","ids":["$api","$docs"]}
+`
+	stdout, stderr, code := runErgo(t, dir, input, "--json", "batch")
+	if code != 0 {
+		t.Fatalf("batch failed: exit %d, stderr=%s", code, stderr)
+	}
+	var receipt struct {
+		Operations int               `json:"operations"`
+		Handles    map[string]string `json:"handles"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &receipt); err != nil || receipt.Operations != 3 || len(receipt.Handles) != 2 {
+		t.Fatalf("batch receipt = %s (%v)", stdout, err)
+	}
+	stdout, _, _ = runErgo(t, dir, "", "show", receipt.Handles["docs"])
+	if !strings.Contains(stdout, receipt.Handles["api"]) {
+		t.Fatalf("docs does not show its dependency on api:\n%s", stdout)
+	}
+
+	failing := `{"op":"create","title":"Never written"}
+{"op":"done","id":"ZZZZZZ"}
+`
+	if _, stderr, code := runErgo(t, dir, failing, "batch"); code != 3 || !strings.Contains(stderr, "line 2: done: unknown task id ZZZZZZ") {
+		t.Fatalf("failing batch: exit %d, stderr=%s", code, stderr)
+	}
+	if stdout, _, _ := runErgo(t, dir, "", "list", "--all"); strings.Contains(stdout, "Never written") {
+		t.Fatalf("failed batch wrote a task:\n%s", stdout)
+	}
+	if _, stderr, code := runErgo(t, dir, `{"op":"sequence","ids":["$missing","ABCDEF"]}`, "batch"); code != 2 || !strings.Contains(stderr, "unknown handle $missing") {
+		t.Fatalf("unknown handle: exit %d, stderr=%s", code, stderr)
+	}
+}
+
+func TestTUIRefusesWithoutATerminal(t *testing.T) {
+	dir := setupErgo(t)
+	stdout, stderr, code := runErgo(t, dir, "", "tui")
+	if code != 2 || stdout != "" || !strings.Contains(stderr, "tui needs an interactive terminal") {
+		t.Fatalf("tui without a terminal: exit %d, stdout=%q, stderr=%s", code, stdout, stderr)
+	}
+}
+
+func TestEditAppliesEditorChangesAndRefusesStaleBases(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("editor scripts need sh")
+	}
+	dir := setupErgo(t)
+	stdout, stderr, code := runNewTaskWithBody(t, dir, "Old body\n", "Old title")
+	if code != 0 {
+		t.Fatalf("new task failed: %s", stderr)
+	}
+	id := strings.TrimSpace(stdout)
+	editor := func(script string) {
+		path := filepath.Join(t.TempDir(), "editor.sh")
+		if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		t.Setenv("VISUAL", "")
+		t.Setenv("EDITOR", path)
+	}
+
+	editor(`printf 'New title\n\nNew body\n' > "$1"` + "\n")
+	stdout, stderr, code = runErgo(t, dir, "", "edit", id, "--title")
+	if code != 0 || !strings.Contains(stdout, "New title (title, body)") {
+		t.Fatalf("edit = %d %q %q", code, stdout, stderr)
+	}
+	if body, _, _ := runErgo(t, dir, "", "show", id, "--body"); body != "New body\n" {
+		t.Fatalf("body after edit = %q", body)
+	}
+
+	// The editor stores a change of its own before saving, as a second
+	// writer would; the edit must see the stale base and keep the lock free.
+	t.Setenv("ERGO", ergoBinary)
+	t.Setenv("ERGO_DIR", dir)
+	t.Setenv("TASK_ID", id)
+	editor(`cd "$ERGO_DIR" && printf 'Theirs\n' | "$ERGO" body "$TASK_ID" || exit 1
+printf 'Mine\n' > "$1"
+`)
+	stdout, stderr, code = runErgo(t, dir, "", "edit", id)
+	if code != 4 || !strings.Contains(stderr, "changed while you were editing") || !strings.Contains(stderr, "your edit is saved in") {
+		t.Fatalf("stale edit = %d %q %q", code, stdout, stderr)
+	}
+	if body, _, _ := runErgo(t, dir, "", "show", id, "--body"); body != "Theirs\n" {
+		t.Fatalf("stale edit wrote %q", body)
+	}
+}
+
+func TestIfRevisionRefusesWritesOverNewerTasks(t *testing.T) {
+	dir := setupErgo(t)
+	stdout, stderr, code := runNewTask(t, dir, "Shared")
+	if code != 0 {
+		t.Fatalf("new task failed: %s", stderr)
+	}
+	id := strings.TrimSpace(stdout)
+	revision := func() string {
+		t.Helper()
+		stdout, stderr, code := runErgo(t, dir, "", "list", "--json")
+		var document struct {
+			Items []struct{ ID, Revision string }
+		}
+		if code != 0 || json.Unmarshal([]byte(stdout), &document) != nil || len(document.Items) != 1 || document.Items[0].ID != id {
+			t.Fatalf("list --json = %d %q %q", code, stdout, stderr)
+		}
+		return document.Items[0].Revision
+	}
+	read := revision()
+	if shown, _, _ := runErgo(t, dir, "", "show", id); !strings.Contains(shown, `revision: "`+read+`"`) {
+		t.Fatalf("show lacks revision %s:\n%s", read, shown)
+	}
+
+	if _, stderr, code := runErgo(t, dir, "", "title", id, "Mine", "--if-revision", read); code != 0 {
+		t.Fatalf("title over current revision failed: %s", stderr)
+	}
+	_, stderr, code = runErgo(t, dir, "Theirs\n", "body", id, "--if-revision", read)
+	if code != 4 || !strings.Contains(stderr, "is at revision "+revision()) {
+		t.Fatalf("body over stale revision = %d %q", code, stderr)
+	}
+	if body, _, _ := runErgo(t, dir, "", "show", id, "--body"); body != "" {
+		t.Fatalf("stale write stored %q", body)
+	}
+	_, stderr, code = runErgo(t, dir, "", "claim", "--agent", "a", "--if-revision", read)
+	if code != 2 || !strings.Contains(stderr, "only to a claim by ID") {
+		t.Fatalf("automatic claim with --if-revision = %d %q", code, stderr)
+	}
+
+	stdout, _, code = runNewTask(t, dir, "Earlier")
+	if code != 0 {
+		t.Fatal("new task failed")
+	}
+	earlier := strings.TrimSpace(stdout)
+	_, stderr, code = runErgo(t, dir, "", "sequence", earlier, id, "--if-revision", id+"="+read)
+	if code != 4 || !strings.Contains(stderr, "is at revision") {
+		t.Fatalf("sequence over stale revision = %d %q", code, stderr)
+	}
+	_, stderr, code = runErgo(t, dir, "", "sequence", earlier, id, "--if-revision", read)
+	if code != 2 || !strings.Contains(stderr, "use ID=REV") {
+		t.Fatalf("sequence with a bare --if-revision = %d %q", code, stderr)
+	}
+	shown, _, _ := runErgo(t, dir, "", "--json", "show", id)
+	var document struct{ Task struct{ Revision string } }
+	if err := json.Unmarshal([]byte(shown), &document); err != nil || document.Task.Revision == "" {
+		t.Fatalf("show --json = %q (%v)", shown, err)
+	}
+	if _, stderr, code := runErgo(t, dir, "", "sequence", earlier, id, "--if-revision", id+"="+document.Task.Revision); code != 0 {
+		t.Fatalf("sequence over current revision failed: %s", stderr)
+	}
+}
+
+func TestImportBeadsPreviewsThenImportsFromDisk(t *testing.T) {
+	dir := setupErgo(t)
+	source := filepath.Join(t.TempDir(), "issues.jsonl")
+	issues := `{"id":"bd-a1","title":"Launch","status":"open","issue_type":"epic"}
+{"id":"bd-a2","title":"Build","status":"open","priority":1,"sprint":"7","dependencies":[{"issue_id":"bd-a2","depends_on_id":"bd-a1","type":"parent-child"}]}
+{"id":"bd-a3","title":"Ship","status":"closed","dependencies":[{"issue_id":"bd-a3","depends_on_id":"bd-a1","type":"parent-child"},{"issue_id":"bd-a3","depends_on_id":"bd-a2","type":"blocks"}]}
+`
+	if err := os.WriteFile(source, []byte(issues), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout, stderr, code := runErgo(t, dir, "", "import", "beads", source, "--dry-run")
+	if code != 0 || !strings.Contains(stdout, "Would import 3 issues from "+source+": 1 epic, 2 tasks, 1 dependency") || !strings.Contains(stdout, "sprint: no ergo equivalent (bd-a2)") {
+		t.Fatalf("import --dry-run = %d %q %q", code, stdout, stderr)
+	}
+	if listed, _, _ := runErgo(t, dir, "", "list", "--all"); strings.Contains(listed, "Launch") {
+		t.Fatalf("dry run wrote tasks:\n%s", listed)
+	}
+
+	stdout, stderr, code = runErgo(t, dir, "", "import", "beads", source, "--json")
+	var receipt struct {
+		Created []struct{ SourceID, ID, Kind string } `json:"created"`
+	}
+	if code != 0 || json.Unmarshal([]byte(stdout), &receipt) != nil || len(receipt.Created) != 3 || receipt.Created[0].Kind != "epic" {
+		t.Fatalf("import --json = %d %q %q", code, stdout, stderr)
+	}
+	ship := receipt.Created[2].ID
+	shown, _, _ := runErgo(t, dir, "", "show", ship)
+	for _, want := range []string{`state: "done"`, "Beads-ID: bd-a3", receipt.Created[1].ID} {
+		if !strings.Contains(shown, want) {
+			t.Fatalf("imported task lacks %q:\n%s", want, shown)
+		}
+	}
+
+	_, stderr, code = runErgo(t, dir, "", "import", "beads", filepath.Join(source, "missing"))
+	if code == 0 || !strings.Contains(stderr, "missing") {
+		t.Fatalf("import of a missing path = %d %q", code, stderr)
+	}
+	_, stderr, code = runErgo(t, dir, "", "import", "jira", source)
+	if code != 2 || !strings.Contains(stderr, "usage: ergo import beads <path>") {
+		t.Fatalf("import from an unknown tracker = %d %q", code, stderr)
+	}
+}
+
 func TestRemovedMutationCommandsGiveDirectHints(t *testing.T) {
 	dir := setupErgo(t)
 	for _, test := range []struct {
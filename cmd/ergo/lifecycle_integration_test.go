@@ -359,6 +359,31 @@ func TestClaimDoneTaskReusesOriginalID(t *testing.T) {
 	}
 }
 
+func TestLeasedClaimHeartbeatsThroughTheCLI(t *testing.T) {
+	t.Parallel()
+	dir := setupErgo(t)
+	id := createLifecycleTask(t, dir)
+	stdout, stderr, code := runErgo(t, dir, "", "claim", "--agent", "lease@local", "--lease", "30m")
+	if code != 0 {
+		t.Fatalf("leased claim failed: %s", stderr)
+	}
+	if !strings.Contains(stdout, "lease_expires_at: ") || !strings.Contains(stdout, "ergo heartbeat "+id+" --agent lease@local") {
+		t.Fatalf("leased claim output lacks lease facts:\n%s", stdout)
+	}
+	_, stderr, code = runErgo(t, dir, "", "heartbeat", id, "--agent", "other@local")
+	if code == 0 || !strings.Contains(stderr, "claimed by lease@local") {
+		t.Fatalf("foreign heartbeat exit=%d stderr=%q", code, stderr)
+	}
+	stdout, stderr, code = runErgo(t, dir, "", "heartbeat", id, "--agent", "lease@local", "--lease", "1h")
+	if code != 0 || !strings.Contains(stdout, id+" - lease held by lease@local until ") {
+		t.Fatalf("heartbeat exit=%d stdout=%q stderr=%q", code, stdout, stderr)
+	}
+	_, stderr, code = runErgo(t, dir, "", "claim", "--agent", "lease@local", "--lease", "-5m")
+	if code == 0 || !strings.Contains(stderr, "--lease must be positive") {
+		t.Fatalf("negative lease exit=%d stderr=%q", code, stderr)
+	}
+}
+
 func createLifecycleTask(t *testing.T, dir string) string {
 	t.Helper()
 	stdout, stderr, code := runNewTask(t, dir, "Lifecycle task")
@@ -6,11 +6,13 @@
 package main
 
 import (
+	"fmt"
 	"os"
+	"os/exec"
 	"runtime/debug"
 	"strings"
 
-	"github.com/sandover/ergo/v4/internal/ergo"
+	"github.com/sandover/ergo/v4/backlog"
 	"golang.org/x/term"
 )
 
@@ -19,7 +21,7 @@ var version = "dev"
 
 func main() {
 	streams := processStreams()
-	root := NewRootCommand(ergo.NewApplication(ergo.RepositoryOptions{}), streams, effectiveVersion())
+	root := NewRootCommand(backlog.New(backlog.Options{}), streams, effectiveVersion())
 	os.Exit(runCommand(root, os.Args[1:], streams))
 }
 
@@ -53,6 +55,10 @@ func processStreams() Streams {
 			width = columns
 		}
 	}
+	var screen Screen
+	if stdinTerminal && stdoutTerminal {
+		screen = &processScreen{}
+	}
 	return Streams{
 		In:             os.Stdin,
 		Out:            os.Stdout,
@@ -62,7 +68,86 @@ func processStreams() Streams {
 		NoColor:        envPresent("NO_COLOR"),
 		Term:           os.Getenv("TERM"),
 		Width:          width,
+		Screen:         screen,
+		Editor:         editText,
+	}
+}
+
+// processScreen drives the controlling terminal through stdin and stdout.
+type processScreen struct {
+	// cooked is the mode MakeRaw replaced; Edit returns to it while the
+	// editor runs.
+	cooked *term.State
+}
+
+func (screen *processScreen) MakeRaw() (func() error, error) {
+	state, err := term.MakeRaw(int(os.Stdin.Fd()))
+	if err != nil {
+		return nil, err
+	}
+	screen.cooked = state
+	return func() error {
+		screen.cooked = nil
+		return term.Restore(int(os.Stdin.Fd()), state)
+	}, nil
+}
+
+func (screen *processScreen) Size() (int, int) {
+	width, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil || width <= 0 || height <= 0 {
+		return 80, 24
+	}
+	return width, height
+}
+
+// Edit runs editText with the terminal back in the mode MakeRaw replaced.
+func (screen *processScreen) Edit(text string) (string, error) {
+	if screen.cooked == nil {
+		return editText(text)
+	}
+	raw, err := term.GetState(int(os.Stdin.Fd()))
+	if err != nil {
+		return "", err
+	}
+	if err := term.Restore(int(os.Stdin.Fd()), screen.cooked); err != nil {
+		return "", err
+	}
+	defer term.Restore(int(os.Stdin.Fd()), raw)
+	return editText(text)
+}
+
+// editText opens text in $VISUAL, then $EDITOR, then vi, and returns what
+// the editor saved. The editor shares this process's terminal.
+func editText(text string) (string, error) {
+	file, err := os.CreateTemp("", "ergo-edit-*.md")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.WriteString(text); err != nil {
+		file.Close()
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		return "", err
+	}
+	editor := strings.Fields(os.Getenv("VISUAL"))
+	if len(editor) == 0 {
+		editor = strings.Fields(os.Getenv("EDITOR"))
+	}
+	if len(editor) == 0 {
+		editor = []string{"vi"}
+	}
+	command := exec.Command(editor[0], append(editor[1:], file.Name())...)
+	command.Stdin, command.Stdout, command.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := command.Run(); err != nil {
+		return "", fmt.Errorf("editor %s: %w", editor[0], err)
+	}
+	edited, err := os.ReadFile(file.Name())
+	if err != nil {
+		return "", err
 	}
+	return string(edited), nil
 }
 
 func envPresent(name string) bool {
@@ -19,9 +19,9 @@ import (
 )
 
 var publicCommandPaths = []string{
-	"init", "new", "new task", "new epic", "list", "show", "claim", "done",
-	"fail", "block", "cancel", "open", "result", "title", "body", "move", "sequence",
-	"unsequence", "where", "info", "compact", "prune", "quickstart", "version",
+	"init", "new", "new task", "new epic", "list", "show", "search", "history", "graph", "path", "stats", "watch", "claim", "heartbeat", "done",
+	"fail", "block", "cancel", "open", "result", "title", "priority", "label", "label add", "label remove", "body", "edit", "move", "sequence",
+	"unsequence", "batch", "import", "import beads", "undo", "where", "info", "compact", "prune", "fsck", "merge-driver", "mcp", "serve", "tui", "quickstart", "version",
 }
 
 func TestRootHelpIsTheFrontDoor(t *testing.T) {
@@ -294,16 +294,19 @@ func TestShowBodyDocumentationHasClearOwnership(t *testing.T) {
 	}
 }
 
-func TestAgentFlagBelongsOnlyToClaim(t *testing.T) {
+func TestAgentFlagBelongsOnlyToIdentityCommands(t *testing.T) {
 	root := newManualTestRoot()
 	if root.PersistentFlags().Lookup("agent") != nil {
 		t.Fatal("--agent remains a global flag")
 	}
-	if findCommand(t, root, "claim").Flags().Lookup("agent") == nil {
-		t.Fatal("claim lacks --agent")
+	agentCommands := []string{"claim", "heartbeat", "undo", "stats", "tui"}
+	for _, path := range agentCommands {
+		if findCommand(t, root, path).Flags().Lookup("agent") == nil {
+			t.Fatalf("%s lacks --agent", path)
+		}
 	}
 	for _, path := range publicCommandPaths {
-		if path == "claim" {
+		if containsExact(agentCommands, path) {
 			continue
 		}
 		if strings.Contains(renderCommandHelp(t, findCommand(t, root, path)), "--agent") {
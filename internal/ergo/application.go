@@ -41,10 +41,12 @@ func (a *Application) Version(request VersionRequest) VersionOutcome {
 }
 
 type CreateTaskRequest struct {
-	Title  string
-	EpicID string
-	Body   string
-	Draft  bool
+	Title    string
+	EpicID   string
+	Body     string
+	Draft    bool
+	Priority string
+	Labels   []string
 }
 
 type CreateTaskOutcome struct {
@@ -56,11 +58,26 @@ func (a *Application) CreateTask(request CreateTaskRequest) (CreateTaskOutcome,
 	if title == "" {
 		return CreateTaskOutcome{}, classified(ErrorUsage, errors.New(NewTaskUsage))
 	}
+	priority := ""
+	if strings.TrimSpace(request.Priority) != "" {
+		level, err := normalizePriority(request.Priority)
+		if err != nil {
+			return CreateTaskOutcome{}, classified(ErrorUsage, err)
+		}
+		priority = level
+	}
+	labels, err := normalizeLabels(request.Labels)
+	if err != nil {
+		return CreateTaskOutcome{}, classified(ErrorUsage, err)
+	}
 	dir, err := ergoDir(a.repository)
 	if err != nil {
 		return CreateTaskOutcome{}, classifyRepositoryError(err)
 	}
-	created, err := createTask(dir, a.repository, request.EpicID, title, request.Body, request.Draft)
+	created, err := createTask(dir, a.repository, newTaskInput{
+		EpicID: request.EpicID, Title: title, Body: request.Body, Priority: priority, Labels: labels,
+		Draft: request.Draft,
+	})
 	if err != nil {
 		return CreateTaskOutcome{}, classifyRepositoryError(err)
 	}
@@ -69,6 +86,9 @@ func (a *Application) CreateTask(request CreateTaskRequest) (CreateTaskOutcome,
 
 type ShowRequest struct {
 	ID string
+	// At, when set, shows the task as it stood then: an RFC 3339 timestamp or
+	// a duration ago.
+	At string
 }
 
 type ShowOutcome struct {
@@ -82,6 +102,7 @@ type ShowOutcome struct {
 // ShowBodyRequest selects the lossless body projection of one task or epic.
 type ShowBodyRequest struct {
 	ID string
+	At string
 }
 
 // ShowBodyOutcome contains only the stored body bytes represented as text.
@@ -94,13 +115,13 @@ func (a *Application) Show(request ShowRequest) (ShowOutcome, error) {
 	if id == "" {
 		return ShowOutcome{}, classified(ErrorUsage, errors.New("usage: ergo show <id>"))
 	}
-	var repository Repository
-	if err := repository.Open(a.repository); err != nil {
-		return ShowOutcome{}, classifyRepositoryError(err)
+	repository, err := a.openView(request.At)
+	if err != nil {
+		return ShowOutcome{}, err
 	}
 	graph, journal, err := repository.ViewWithJournal()
 	if err != nil {
-		return ShowOutcome{}, classifyRepositoryError(err)
+		return ShowOutcome{}, classifyViewError(err)
 	}
 	if _, ok := graph.Tombstones[id]; ok {
 		return ShowOutcome{}, classified(ErrorNotFound, prunedErr(id))
@@ -127,13 +148,13 @@ func (a *Application) ShowBody(request ShowBodyRequest) (ShowBodyOutcome, error)
 	if id == "" {
 		return ShowBodyOutcome{}, classified(ErrorUsage, errors.New("usage: ergo show <id> --body"))
 	}
-	var repository Repository
-	if err := repository.Open(a.repository); err != nil {
-		return ShowBodyOutcome{}, classifyRepositoryError(err)
+	repository, err := a.openView(request.At)
+	if err != nil {
+		return ShowBodyOutcome{}, err
 	}
 	graph, err := repository.ViewGraph()
 	if err != nil {
-		return ShowBodyOutcome{}, classifyRepositoryError(err)
+		return ShowBodyOutcome{}, classifyViewError(err)
 	}
 	if _, ok := graph.Tombstones[id]; ok {
 		return ShowBodyOutcome{}, classified(ErrorNotFound, prunedErr(id))
@@ -149,6 +170,9 @@ type LifecycleRequest struct {
 	Kind     string
 	ID       string
 	Messages []string
+	// IfRevision, when set, refuses the change unless the task is still at
+	// that revision, as shown by show and list --json.
+	IfRevision string
 }
 
 type LifecycleOutcome struct {
@@ -162,6 +186,7 @@ type LifecycleOutcome struct {
 type ResultRequest struct {
 	ID, Text, FilePath string
 	FileSet            bool
+	IfRevision         string
 }
 
 type ResultOutcome struct {
@@ -193,6 +218,9 @@ func (a *Application) Result(request ResultRequest) (ResultOutcome, error) {
 		if task == nil {
 			return nil, nil, classified(ErrorNotFound, fmt.Errorf("unknown task id %s", id))
 		}
+		if err := checkRevision(graph, id, request.IfRevision); err != nil {
+			return nil, nil, err
+		}
 		if graph.IsEpic(id) {
 			return nil, nil, classified(ErrorConflict, errors.New("epics cannot have results"))
 		}
@@ -234,18 +262,8 @@ func (a *Application) Lifecycle(request LifecycleRequest) (LifecycleOutcome, err
 	if err != nil {
 		return LifecycleOutcome{}, classifyRepositoryError(err)
 	}
-	mutation := taskMutation{
-		Kind: request.Kind, State: targetState, StateSet: true,
-		MessageKind: request.Kind, MessageText: message, MessageSet: messageSet,
-	}
-	switch request.Kind {
-	case "open":
-		mutation.AllowedStates = []string{stateTodo, stateDraft, stateDoing, stateBlocked}
-	case "done", "fail", "block":
-		mutation.AllowedStates = []string{stateTodo, stateDoing, stateBlocked, stateDone, stateFailed, stateCanceled, stateError}
-	case "cancel":
-		mutation.AllowedStates = []string{stateTodo, stateDraft, stateDoing, stateBlocked, stateDone, stateFailed, stateCanceled, stateError}
-	}
+	mutation := lifecycleMutation(request.Kind, targetState, message, messageSet)
+	mutation.ExpectRevision = request.IfRevision
 	mutated, err := applyTaskMutation(dir, a.repository, id, mutation, "")
 	if err != nil {
 		return LifecycleOutcome{}, classifyRepositoryError(err)
@@ -263,6 +281,16 @@ func (a *Application) Lifecycle(request LifecycleRequest) (LifecycleOutcome, err
 type ClaimRequest struct {
 	ID      string
 	AgentID string
+	// Lease, when positive, lets the claim lapse unless the agent heartbeats.
+	Lease time.Duration
+	// Labels restricts automatic claim to ready tasks carrying every label.
+	Labels []string
+	// Wait makes automatic claim retry whenever the backlog changes until a
+	// task is claimed or Timeout passes; a zero Timeout waits indefinitely.
+	Wait    bool
+	Timeout time.Duration
+	// IfRevision applies only to a claim by ID.
+	IfRevision string
 }
 
 type ClaimOutcome struct {
@@ -270,6 +298,7 @@ type ClaimOutcome struct {
 	Task       *Task
 	ProjectDir string
 	NoReady    bool
+	Labels     []string
 	Journal    []JournalEntry
 }
 
@@ -278,15 +307,31 @@ func (a *Application) Claim(request ClaimRequest) (ClaimOutcome, error) {
 	if agentID == "" {
 		return ClaimOutcome{}, classified(ErrorUsage, errors.New("claim requires --agent"))
 	}
+	if err := validateLease(request.Lease); err != nil {
+		return ClaimOutcome{}, classified(ErrorUsage, err)
+	}
+	filter, err := newLabelFilter(request.Labels, nil)
+	if err != nil {
+		return ClaimOutcome{}, classified(ErrorUsage, err)
+	}
 	dir, err := ergoDir(a.repository)
 	if err != nil {
 		return ClaimOutcome{}, classifyRepositoryError(err)
 	}
 	id := strings.TrimSpace(request.ID)
+	if id != "" && filter.active() {
+		return ClaimOutcome{}, classified(ErrorUsage, errors.New("--label applies only to automatic claim; omit the task ID"))
+	}
+	if err := validateClaimWait(id, request); err != nil {
+		return ClaimOutcome{}, classified(ErrorUsage, err)
+	}
+	if id == "" && request.IfRevision != "" {
+		return ClaimOutcome{}, classified(ErrorUsage, errors.New("--if-revision applies only to a claim by ID"))
+	}
 	if id != "" {
 		mutation := taskMutation{
 			Kind: "claim", State: stateDoing, StateSet: true,
-			Claim: agentID, ClaimSet: true, ClaimConflict: true,
+			Claim: agentID, ClaimSet: true, ClaimConflict: true, Lease: request.Lease, ExpectRevision: request.IfRevision,
 			AllowedStates: []string{stateTodo, stateDoing, stateDone, stateFailed, stateCanceled, stateError},
 		}
 		mutated, err := applyTaskMutation(dir, a.repository, id, mutation, agentID)
@@ -301,25 +346,43 @@ func (a *Application) Claim(request ClaimRequest) (ClaimOutcome, error) {
 	if err := repository.openAt(dir, a.repository, systemRepositoryIO()); err != nil {
 		return ClaimOutcome{}, classifyRepositoryError(err)
 	}
+	if request.Wait {
+		return waitForClaim(dir, request.Timeout, func() (ClaimOutcome, error) {
+			return claimReady(&repository, filter, agentID, request.Lease)
+		})
+	}
+	return claimReady(&repository, filter, agentID, request.Lease)
+}
+
+// claimReady claims the first ready task matching filter in priority order,
+// then oldest first, in one update, or reports that none is ready.
+func claimReady(repository *Repository, filter labelFilter, agentID string, lease time.Duration) (ClaimOutcome, error) {
 	var chosenID string
 	update, err := repository.UpdateWithJournal(func(graph *Graph) ([]Event, []JournalEntry, error) {
-		ready := readyTasks(graph)
+		var ready []*Task
+		for _, task := range readyTasks(graph) {
+			if filter.matches(task) {
+				ready = append(ready, task)
+			}
+		}
 		if len(ready) == 0 {
 			return nil, nil, nil
 		}
 		chosenID = ready[0].ID
-		mutation := taskMutation{Kind: "claim", State: stateDoing, StateSet: true, Claim: agentID, ClaimSet: true}
-		events, _, err := buildMutationEvents(chosenID, ready[0], mutation, agentID, time.Now().UTC())
+		now := time.Now().UTC()
+		mutation := taskMutation{Kind: "claim", State: stateDoing, StateSet: true, Claim: agentID, ClaimSet: true, Lease: lease}
+		events, _, err := buildMutationEvents(chosenID, ready[0], mutation, agentID, now)
 		if err != nil {
 			return nil, nil, err
 		}
-		return events, []JournalEntry{newJournalEntry(chosenID, "claim", agentID, "", time.Now().UTC())}, nil
+		journal := leaseExpiryJournal(ready[0], now)
+		return events, append(journal, newJournalEntry(chosenID, "claim", agentID, "", now)), nil
 	})
 	if err != nil {
 		return ClaimOutcome{}, classifyRepositoryError(err)
 	}
 	if chosenID == "" {
-		return ClaimOutcome{NoReady: true}, nil
+		return ClaimOutcome{NoReady: true, Labels: filter.Include}, nil
 	}
 	task := update.Graph.Tasks[chosenID]
 	if task == nil {
@@ -0,0 +1,334 @@
+// Purpose: Apply many task operations as one atomic backlog change.
+// Exports: BatchRequest, BatchOutcome, BatchCreated, and Application.Batch.
+// Role: Parse JSONL operations, validate each against a working graph that
+// already holds the earlier ones, and append everything as one transaction.
+// Invariants: either every operation applies or nothing is written. A create
+// may name a handle; later operations refer to the new task as $handle.
+// Notes: each operation reuses the builder behind its single command, so a
+// batch accepts and refuses exactly what the commands would in that order.
+package ergo
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+type BatchRequest struct {
+	// Input holds one JSON operation per line; blank lines are skipped.
+	Input []byte
+}
+
+type BatchOutcome struct {
+	Graph      *Graph
+	Operations int
+	// Created lists created tasks in input order.
+	Created []BatchCreated
+}
+
+type BatchCreated struct {
+	Handle, ID, Title string
+}
+
+// Handles maps each named handle to the ID generated for it.
+func (o BatchOutcome) Handles() map[string]string {
+	handles := map[string]string{}
+	for _, created := range o.Created {
+		if created.Handle != "" {
+			handles[created.Handle] = created.ID
+		}
+	}
+	return handles
+}
+
+// batchOperation is one input line. Which fields apply depends on Op.
+type batchOperation struct {
+	Op       string   `json:"op"`
+	Handle   string   `json:"handle"`
+	ID       string   `json:"id"`
+	IDs      []string `json:"ids"`
+	Title    string   `json:"title"`
+	Body     *string  `json:"body"`
+	Append   bool     `json:"append"`
+	Epic     string   `json:"epic"`
+	Root     bool     `json:"root"`
+	Draft    bool     `json:"draft"`
+	Priority string   `json:"priority"`
+	Labels   []string `json:"labels"`
+	Message  *string  `json:"message"`
+	// IfRevision is checked against the backlog as it stood before the batch,
+	// so earlier lines touching the same task do not invalidate it.
+	IfRevision string `json:"if_revision"`
+	// IfRevisions is the same check for a sequence, keyed by task ID.
+	IfRevisions map[string]string `json:"if_revisions"`
+
+	line int
+}
+
+var batchHandlePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+func (a *Application) Batch(request BatchRequest) (BatchOutcome, error) {
+	operations, err := parseBatch(request.Input)
+	if err != nil {
+		return BatchOutcome{}, classified(ErrorUsage, err)
+	}
+	var repository Repository
+	if err := repository.Open(a.repository); err != nil {
+		return BatchOutcome{}, classifyRepositoryError(err)
+	}
+	outcome := BatchOutcome{Operations: len(operations)}
+	update, err := repository.UpdateWithJournal(func(graph *Graph) ([]Event, []JournalEntry, error) {
+		events, journal, created, err := applyBatch(graph, operations, time.Now().UTC())
+		outcome.Created = created
+		return events, journal, err
+	})
+	if err != nil {
+		return BatchOutcome{}, classifyRepositoryError(err)
+	}
+	outcome.Graph = update.Graph
+	return outcome, nil
+}
+
+// applyBatch validates operations in order against graph and returns the
+// events and journal entries applying them all, with the created tasks in
+// input order. graph itself is left unchanged.
+func applyBatch(graph *Graph, operations []batchOperation, now time.Time) ([]Event, []JournalEntry, []BatchCreated, error) {
+	working := graph
+	handles := map[string]string{}
+	var events []Event
+	var journal []JournalEntry
+	var created []BatchCreated
+	for _, operation := range operations {
+		var opEvents []Event
+		var opJournal []JournalEntry
+		var opCreated *BatchCreated
+		err := operation.checkRevision(graph)
+		if err == nil {
+			opEvents, opJournal, opCreated, err = operation.build(working, handles, now)
+		}
+		if err == nil {
+			working, err = applyTransaction(working, opEvents)
+		}
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("line %d: %s: %w", operation.line, operation.Op, err)
+		}
+		if opCreated != nil {
+			created = append(created, *opCreated)
+		}
+		events = append(events, opEvents...)
+		journal = append(journal, opJournal...)
+	}
+	return events, journal, created, nil
+}
+
+// parseBatch decodes every line and checks what can be checked without the
+// backlog, so a malformed batch never takes the lock.
+func parseBatch(input []byte) ([]batchOperation, error) {
+	var operations []batchOperation
+	declared := map[string]bool{}
+	scanner := bufio.NewScanner(bytes.NewReader(input))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxBatchLine)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var operation batchOperation
+		decoder := json.NewDecoder(strings.NewReader(text))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&operation); err != nil {
+			return nil, fmt.Errorf("line %d: invalid operation: %w", line, err)
+		}
+		if decoder.More() {
+			return nil, fmt.Errorf("line %d: expected one JSON object per line", line)
+		}
+		operation.line = line
+		if err := operation.validate(declared); err != nil {
+			return nil, fmt.Errorf("line %d: %s: %w", line, operation.Op, err)
+		}
+		operations = append(operations, operation)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(operations) == 0 {
+		return nil, errors.New("batch has no operations; pipe one JSON operation per line")
+	}
+	return operations, nil
+}
+
+const maxBatchLine = 16 << 20
+
+func (o *batchOperation) validate(declared map[string]bool) error {
+	refs := []string{o.ID}
+	switch o.Op {
+	case "create":
+		o.Title = strings.TrimSpace(o.Title)
+		if o.Title == "" {
+			return errors.New("title is required")
+		}
+		if o.Priority != "" {
+			priority, err := normalizePriority(o.Priority)
+			if err != nil {
+				return err
+			}
+			o.Priority = priority
+		}
+		labels, err := normalizeLabels(o.Labels)
+		if err != nil {
+			return err
+		}
+		o.Labels = labels
+		if o.ID != "" {
+			return errors.New("create takes no id; name the new task with handle")
+		}
+		refs = []string{o.Epic}
+	case "sequence", "unsequence":
+		if len(o.IDs) < 2 {
+			return errors.New("ids needs at least two tasks")
+		}
+		refs = o.IDs
+	case "move":
+		if o.Root == (o.Epic != "") {
+			return errors.New("move needs exactly one of epic or root")
+		}
+		refs = append(refs, o.Epic)
+	case "title":
+		o.Title = strings.TrimSpace(o.Title)
+		if o.Title == "" {
+			return errors.New("title cannot be empty")
+		}
+	case "body":
+		if o.Body == nil {
+			return errors.New("body is required")
+		}
+	case "done", "fail", "block", "cancel", "open":
+		if o.Message != nil && strings.TrimSpace(*o.Message) == "" {
+			return errors.New("message cannot be blank")
+		}
+	default:
+		return fmt.Errorf("unknown op %q; use create, sequence, unsequence, move, title, body, done, fail, block, cancel, or open", o.Op)
+	}
+	if o.Op != "create" && o.Op != "sequence" && o.Op != "unsequence" && strings.TrimSpace(o.ID) == "" {
+		return errors.New("id is required")
+	}
+	for _, ref := range refs {
+		if handle, ok := strings.CutPrefix(strings.TrimSpace(ref), "$"); ok && !declared[handle] {
+			return fmt.Errorf("unknown handle $%s; a handle must be created on an earlier line", handle)
+		}
+	}
+	if o.IfRevision != "" {
+		if o.Op == "sequence" || o.Op == "unsequence" {
+			return errors.New("if_revision applies to one task; give a sequence if_revisions keyed by ID")
+		}
+		if o.Op == "create" {
+			return errors.New("if_revision applies only to operations on one existing task")
+		}
+		if strings.HasPrefix(strings.TrimSpace(o.ID), "$") {
+			return errors.New("if_revision cannot name a task created in this batch")
+		}
+	}
+	if len(o.IfRevisions) > 0 {
+		if o.Op != "sequence" && o.Op != "unsequence" {
+			return errors.New("if_revisions applies only to sequence and unsequence")
+		}
+		for id := range o.IfRevisions {
+			if strings.HasPrefix(id, "$") {
+				return errors.New("if_revisions cannot name a task created in this batch")
+			}
+		}
+		if err := checkRevisionTargets(o.IDs, o.IfRevisions); err != nil {
+			return err
+		}
+	}
+	if o.Handle != "" {
+		if o.Op != "create" {
+			return errors.New("only create names a handle")
+		}
+		if !batchHandlePattern.MatchString(o.Handle) {
+			return fmt.Errorf("invalid handle %q: use letters, digits, - and _", o.Handle)
+		}
+		if declared[o.Handle] {
+			return fmt.Errorf("handle %s is already used", o.Handle)
+		}
+		declared[o.Handle] = true
+	}
+	return nil
+}
+
+// checkRevision applies o's if_revision or if_revisions to the backlog before
+// the batch. An unknown ID is left for build to report.
+func (o batchOperation) checkRevision(before *Graph) error {
+	id := strings.TrimSpace(o.ID)
+	if o.IfRevision == "" || before.Tasks[id] == nil {
+		return checkRevisions(before, o.IfRevisions)
+	}
+	return checkRevision(before, id, o.IfRevision)
+}
+
+// build returns the events and journal entries applying o to graph, which
+// already reflects every earlier operation.
+func (o batchOperation) build(graph *Graph, handles map[string]string, now time.Time) ([]Event, []JournalEntry, *BatchCreated, error) {
+	resolve := func(ref string) string {
+		ref = strings.TrimSpace(ref)
+		if handle, ok := strings.CutPrefix(ref, "$"); ok {
+			return handles[handle]
+		}
+		return ref
+	}
+	id := resolve(o.ID)
+	var mutation taskMutation
+	switch o.Op {
+	case "create":
+		event, created, err := buildNewTask(graph, newTaskInput{
+			EpicID: resolve(o.Epic), Title: o.Title, Body: stringValue(o.Body), Priority: o.Priority, Labels: o.Labels,
+			Draft: o.Draft,
+		}, now)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if o.Handle != "" {
+			handles[o.Handle] = created.ID
+		}
+		return []Event{event}, []JournalEntry{newJournalEntry(created.ID, "created", "", "", now)},
+			&BatchCreated{Handle: o.Handle, ID: created.ID, Title: created.Title}, nil
+	case "sequence", "unsequence":
+		ids := make([]string, len(o.IDs))
+		for i, ref := range o.IDs {
+			ids[i] = resolve(ref)
+		}
+		eventType := "link"
+		if o.Op == "unsequence" {
+			eventType = "unlink"
+		}
+		events, _, err := buildLinkEvents(cloneGraph(graph), eventType, buildSequenceEdges(ids), now)
+		return events, nil, nil, err
+	case "move":
+		mutation = taskMutation{Kind: "move", EpicID: resolve(o.Epic), EpicSet: true, ValidateMove: true}
+	case "title":
+		mutation = taskMutation{Kind: "title", Title: o.Title, TitleSet: true}
+	case "body":
+		mutation = taskMutation{Kind: "body", Body: *o.Body, BodySet: true, BodyAppend: o.Append}
+	default:
+		targetState, err := lifecycleTargetState(o.Op)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		message := strings.TrimSpace(stringValue(o.Message))
+		mutation = lifecycleMutation(o.Op, targetState, message, o.Message != nil)
+	}
+	events, journal, _, err := buildTaskMutation(graph, id, mutation, "", now)
+	return events, journal, nil, err
+}
+
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
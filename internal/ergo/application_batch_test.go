@@ -0,0 +1,105 @@
+package ergo
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBatchAppliesOperationsAsOneTransaction(t *testing.T) {
+	app := newTestApplication(t)
+	existing, err := app.CreateTask(CreateTaskRequest{Title: "Existing"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	input := strings.Join([]string{
+		`{"op":"create","handle":"epic","title":"Launch"}`,
+		`{"op":"create","handle":"api","title":"Build the API","epic":"$epic","priority":"p1"}`,
+		``,
+		`{"op":"create","handle":"docs","title":"Write docs","epic":"$epic","body":"Draft"}`,
+		`{"op":"sequence","ids":["$api","$docs"]}`,
+		`{"op":"title","id":"` + existing.ID + `","title":"Renamed"}`,
+		`{"op":"move","id":"` + existing.ID + `","epic":"$epic"}`,
+		`{"op":"cancel","id":"` + existing.ID + `","message":"Folded into the launch"}`,
+	}, "\n")
+	before := backlogRecords(t, app)
+	outcome, err := app.Batch(BatchRequest{Input: []byte(input)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if outcome.Operations != 7 || len(outcome.Created) != 3 {
+		t.Fatalf("outcome = %#v", outcome)
+	}
+	if got := backlogRecords(t, app); got != before+1 {
+		t.Fatalf("batch appended %d records, want 1", got-before)
+	}
+	handles := outcome.Handles()
+	graph := outcome.Graph
+	api, docs := graph.Tasks[handles["api"]], graph.Tasks[handles["docs"]]
+	if api == nil || docs == nil || api.EpicID != handles["epic"] || docs.EpicID != handles["epic"] || api.Priority != "P1" || docs.Body != "Draft" {
+		t.Fatalf("created tasks = %#v, %#v", api, docs)
+	}
+	if _, ok := graph.Deps[docs.ID][api.ID]; !ok {
+		t.Fatalf("docs does not depend on api: %#v", graph.Deps)
+	}
+	moved := graph.Tasks[existing.ID]
+	if moved.Title != "Renamed" || moved.EpicID != handles["epic"] || moved.State != stateCanceled {
+		t.Fatalf("existing task = %#v", moved)
+	}
+}
+
+func TestBatchWritesNothingWhenAnyOperationFails(t *testing.T) {
+	app := newTestApplication(t)
+	before := backlogRecords(t, app)
+	input := `{"op":"create","handle":"a","title":"First"}
+{"op":"create","handle":"b","title":"Second"}
+{"op":"sequence","ids":["$a","$b"]}
+{"op":"sequence","ids":["$b","$a"]}`
+	_, err := app.Batch(BatchRequest{Input: []byte(input)})
+	if err == nil || !strings.Contains(err.Error(), "line 4: sequence:") || !strings.Contains(err.Error(), "cycle") {
+		t.Fatalf("cyclic batch err = %v", err)
+	}
+	_, err = app.Batch(BatchRequest{Input: []byte(`{"op":"create","title":"Lonely"}` + "\n" + `{"op":"done","id":"ZZZZZZ"}`)})
+	requireApplicationError(t, err, ErrorNotFound)
+	if !strings.HasPrefix(err.Error(), "line 2: done:") {
+		t.Fatalf("unknown task err = %v", err)
+	}
+	if got := backlogRecords(t, app); got != before {
+		t.Fatalf("failed batches appended %d records", got-before)
+	}
+	list, err := app.List(ListRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Graph.Tasks) != 0 {
+		t.Fatalf("failed batches created tasks: %#v", list.Graph.Tasks)
+	}
+}
+
+func TestBatchRejectsMalformedInputBeforeWriting(t *testing.T) {
+	app := newTestApplication(t)
+	for _, input := range []string{
+		"",
+		`{"op":"create"}`,
+		`{"op":"create","title":"A","bogus":true}`,
+		`{"op":"rename","id":"ABCDEF"}`,
+		`{"op":"sequence","ids":["$later","ABCDEF"]}`,
+		`{"op":"create","handle":"x","title":"A"}` + "\n" + `{"op":"create","handle":"x","title":"B"}`,
+		`{"op":"move","id":"ABCDEF"}`,
+		`{"op":"done","id":"ABCDEF","message":" "}`,
+		`{"op":"title","title":"Missing id"}`,
+	} {
+		_, err := app.Batch(BatchRequest{Input: []byte(input)})
+		requireApplicationError(t, err, ErrorUsage)
+	}
+}
+
+func backlogRecords(t *testing.T, app *Application) int {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(app.repository.StartDir, ".ergo", backlogFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Count(string(data), "\n")
+}
@@ -0,0 +1,50 @@
+// Purpose: Let automatic claim block until work becomes ready.
+// Role: Retry loop behind `claim --wait`, built on the watch change waiter.
+// Invariants: each attempt is a fresh locked update; the lock is never held
+// while waiting, so other writers proceed. Waits are bounded by the watch
+// interval, so a lapsing lease is noticed without any write, and by the
+// timeout, so the deadline is not overshot.
+package ergo
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+func validateClaimWait(id string, request ClaimRequest) error {
+	switch {
+	case request.Timeout < 0:
+		return errors.New("--timeout must be positive")
+	case request.Timeout > 0 && !request.Wait:
+		return errors.New("--timeout requires --wait")
+	case request.Wait && id != "":
+		return errors.New("--wait applies only to automatic claim; omit the task ID")
+	}
+	return nil
+}
+
+// waitForClaim repeats attempt after each change to dir until it claims a
+// task. An attempt that finds the lock busy is retried like one that finds no
+// ready work. It fails with ErrorTimeout once timeout passes without a claim,
+// and no wait runs past that deadline.
+func waitForClaim(dir string, timeout time.Duration, attempt func() (ClaimOutcome, error)) (ClaimOutcome, error) {
+	var deadline time.Time
+	interval := defaultWatchInterval
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+		interval = min(interval, timeout)
+	}
+	waiter := newChangeWaiter(dir, interval)
+	defer waiter.close()
+	for {
+		outcome, err := attempt()
+		if kind, _ := ApplicationErrorKind(err); kind != ErrorBusy && (err != nil || !outcome.NoReady) {
+			return outcome, err
+		}
+		if !deadline.IsZero() && !time.Now().Before(deadline) {
+			return ClaimOutcome{}, classified(ErrorTimeout, fmt.Errorf("no ready task within %s", timeout))
+		}
+		waiter.wait(deadline)
+	}
+}
@@ -0,0 +1,82 @@
+package ergo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestClaimWaitReturnsOnceWorkBecomesReady(t *testing.T) {
+	app := newTestApplication(t)
+	type result struct {
+		outcome ClaimOutcome
+		err     error
+	}
+	claimed := make(chan result, 1)
+	go func() {
+		outcome, err := app.Claim(ClaimRequest{AgentID: "worker", Wait: true, Timeout: 10 * time.Second})
+		claimed <- result{outcome, err}
+	}()
+	time.Sleep(50 * time.Millisecond)
+	// The waiting claim must not hold the lock, or this write would stall.
+	created, err := app.CreateTask(CreateTaskRequest{Title: "Late arrival"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case got := <-claimed:
+		if got.err != nil {
+			t.Fatal(got.err)
+		}
+		if got.outcome.NoReady || got.outcome.Task.ID != created.ID || got.outcome.Task.ClaimedBy != "worker" {
+			t.Fatalf("claim outcome = %#v", got.outcome)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("waiting claim did not return")
+	}
+}
+
+func TestClaimWaitTimeoutAndValidation(t *testing.T) {
+	app := newTestApplication(t)
+	started := time.Now()
+	_, err := app.Claim(ClaimRequest{AgentID: "worker", Wait: true, Timeout: 30 * time.Millisecond})
+	requireApplicationError(t, err, ErrorTimeout)
+	if elapsed := time.Since(started); elapsed < 30*time.Millisecond {
+		t.Fatalf("timed out after %s", elapsed)
+	}
+	_, err = app.Claim(ClaimRequest{ID: "ABCDEF", AgentID: "worker", Wait: true})
+	requireApplicationError(t, err, ErrorUsage)
+	_, err = app.Claim(ClaimRequest{AgentID: "worker", Timeout: time.Second})
+	requireApplicationError(t, err, ErrorUsage)
+}
+
+func TestClaimWaitRetriesBusyAttemptsAndKeepsItsDeadline(t *testing.T) {
+	dir := t.TempDir()
+	attempts := 0
+	outcome, err := waitForClaim(dir, 5*time.Second, func() (ClaimOutcome, error) {
+		attempts++
+		if attempts == 1 {
+			return ClaimOutcome{}, classified(ErrorBusy, ErrLockBusy)
+		}
+		return ClaimOutcome{Task: &Task{ID: "ABCDEF"}}, nil
+	})
+	if err != nil || outcome.Task == nil || attempts != 2 {
+		t.Fatalf("after a busy attempt: %#v, %v after %d attempts", outcome, err, attempts)
+	}
+
+	// A change shortly before the deadline wakes the loop; the next wait must
+	// end at the deadline rather than a full interval later.
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		_ = os.WriteFile(filepath.Join(dir, "events.jsonl"), []byte("{}\n"), 0o644)
+	}()
+	started := time.Now()
+	_, err = waitForClaim(dir, 300*time.Millisecond, func() (ClaimOutcome, error) {
+		return ClaimOutcome{NoReady: true}, nil
+	})
+	requireApplicationError(t, err, ErrorTimeout)
+	if elapsed := time.Since(started); elapsed > 450*time.Millisecond {
+		t.Fatalf("300ms timeout returned after %s", elapsed)
+	}
+}
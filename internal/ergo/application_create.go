@@ -6,7 +6,12 @@ import (
 	"strings"
 )
 
-type InitializeRequest struct{ Dir string }
+// InitializeRequest creates or repairs .ergo in Dir. GitMerge also registers
+// the backlog merge driver for the project.
+type InitializeRequest struct {
+	Dir      string
+	GitMerge bool
+}
 type InitializeResult = InitializeOutcome
 
 func (a *Application) Initialize(request InitializeRequest) (InitializeResult, error) {
@@ -15,6 +20,9 @@ func (a *Application) Initialize(request InitializeRequest) (InitializeResult, e
 		dir = "."
 	}
 	outcome, err := InitializeRepository(dir)
+	if err == nil && request.GitMerge {
+		err = configureGitMerge(dir, &outcome)
+	}
 	return outcome, classifyRepositoryError(err)
 }
 
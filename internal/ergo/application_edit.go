@@ -0,0 +1,192 @@
+// Purpose: Apply an editor session to a task only if nobody changed it meanwhile.
+// Exports: TaskText, EditTaskRequest, EditTaskOutcome, EditConflict, and
+// Application.EditTask.
+// Role: The read-edit-write cycle behind ergo edit. The caller reads the task,
+// edits without any lock, and submits the text it started from; the write
+// checks that base under the lock.
+// Invariants: a stale base never writes. The conflict carries the stored text
+// and a line-based three-way merge, so the caller can show the divergence and
+// retry against the stored text.
+// Notes: overlapping or adjacent changes do not merge, matching git.
+package ergo
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// TaskText is the editable text of a task.
+type TaskText struct {
+	Title string
+	Body  string
+}
+
+type EditTaskRequest struct {
+	ID string
+	// Base is the text the edit started from.
+	Base TaskText
+	// Edited is the text to store. Its title is used only when TitleSet.
+	Edited   TaskText
+	TitleSet bool
+}
+
+type EditTaskOutcome struct {
+	ID            string
+	Title         string
+	Bytes         int
+	ChangedFields []string
+}
+
+// EditConflict reports that the stored text no longer equals the edit's base.
+// Merged is the three-way merge of Current and Edited over Base; it is usable
+// only when Clean.
+type EditConflict struct {
+	ID       string
+	TitleSet bool
+	Base     TaskText
+	Current  TaskText
+	Edited   TaskText
+	Merged   TaskText
+	Clean    bool
+}
+
+func (conflict *EditConflict) Error() string {
+	return fmt.Sprintf("task %s changed since the edit began", conflict.ID)
+}
+
+// EditTask stores request.Edited if the task still holds request.Base. An
+// edit that changes nothing writes nothing and cannot conflict.
+func (a *Application) EditTask(request EditTaskRequest) (EditTaskOutcome, error) {
+	if !request.TitleSet {
+		request.Edited.Title = request.Base.Title
+	}
+	if request.TitleSet && strings.TrimSpace(request.Edited.Title) == "" {
+		return EditTaskOutcome{}, classified(ErrorUsage, errors.New("title cannot be empty"))
+	}
+	if request.Edited == request.Base {
+		return EditTaskOutcome{ID: request.ID, Title: request.Base.Title, Bytes: len(request.Base.Body)}, nil
+	}
+	dir, err := ergoDir(a.repository)
+	if err != nil {
+		return EditTaskOutcome{}, classifyRepositoryError(err)
+	}
+	outcome, err := applyTaskMutation(dir, a.repository, request.ID, taskMutation{
+		Kind: "edit", Body: request.Edited.Body, BodySet: true, Title: request.Edited.Title, TitleSet: request.TitleSet,
+		ExpectText: true, ExpectTitle: request.Base.Title, ExpectBody: request.Base.Body,
+	}, "")
+	var conflict *EditConflict
+	if errors.As(err, &conflict) {
+		conflict.TitleSet, conflict.Base, conflict.Edited = request.TitleSet, request.Base, request.Edited
+		conflict.Merged, conflict.Clean = mergeTaskText(request.Base, conflict.Current, request.Edited, request.TitleSet)
+	}
+	if err != nil {
+		return EditTaskOutcome{}, classifyRepositoryError(err)
+	}
+	task := outcome.Graph.Tasks[request.ID]
+	return EditTaskOutcome{ID: task.ID, Title: task.Title, Bytes: len(task.Body), ChangedFields: outcome.ChangedFields}, nil
+}
+
+func mergeTaskText(base, current, edited TaskText, titleSet bool) (TaskText, bool) {
+	body, clean := mergeLines(base.Body, edited.Body, current.Body)
+	title := current.Title
+	if titleSet {
+		switch {
+		case edited.Title == base.Title || edited.Title == current.Title:
+		case current.Title == base.Title:
+			title = edited.Title
+		default:
+			clean = false
+		}
+	}
+	return TaskText{Title: title, Body: body}, clean
+}
+
+// mergeLines merges the changes from base to ours and from base to theirs.
+// It reports false, with ours unchanged, when the changes overlap.
+func mergeLines(base, ours, theirs string) (string, bool) {
+	if ours == theirs || theirs == base {
+		return ours, true
+	}
+	if ours == base {
+		return theirs, true
+	}
+	baseLines := splitKeepingNewlines(base)
+	left := diffHunks(baseLines, splitKeepingNewlines(ours))
+	right := diffHunks(baseLines, splitKeepingNewlines(theirs))
+	var merged strings.Builder
+	position := 0
+	for len(left) > 0 || len(right) > 0 {
+		// Gather the next group of hunks that touch, from either side.
+		var group [2][]textHunk
+		start, end := len(baseLines)+1, -1
+		take := func(side int, hunks *[]textHunk) {
+			hunk := (*hunks)[0]
+			*hunks = (*hunks)[1:]
+			group[side] = append(group[side], hunk)
+			start, end = min(start, hunk.start), max(end, hunk.end)
+		}
+		if len(right) == 0 || (len(left) > 0 && left[0].start <= right[0].start) {
+			take(0, &left)
+		} else {
+			take(1, &right)
+		}
+		for {
+			if len(left) > 0 && left[0].start <= end {
+				take(0, &left)
+			} else if len(right) > 0 && right[0].start <= end {
+				take(1, &right)
+			} else {
+				break
+			}
+		}
+		for _, line := range baseLines[position:start] {
+			merged.WriteString(line)
+		}
+		leftText := applyHunks(baseLines, start, end, group[0])
+		rightText := applyHunks(baseLines, start, end, group[1])
+		switch {
+		case len(group[1]) == 0:
+			merged.WriteString(leftText)
+		case len(group[0]) == 0 || leftText == rightText:
+			merged.WriteString(rightText)
+		default:
+			return ours, false
+		}
+		position = end
+	}
+	for _, line := range baseLines[position:] {
+		merged.WriteString(line)
+	}
+	return merged.String(), true
+}
+
+// applyHunks returns base lines [start, end) with hunks applied; a side with
+// no hunks keeps the base text.
+func applyHunks(base []string, start, end int, hunks []textHunk) string {
+	var text strings.Builder
+	position := start
+	for _, hunk := range hunks {
+		for _, line := range base[position:hunk.start] {
+			text.WriteString(line)
+		}
+		for _, line := range hunk.lines {
+			text.WriteString(line)
+		}
+		position = hunk.end
+	}
+	for _, line := range base[position:end] {
+		text.WriteString(line)
+	}
+	return text.String()
+}
+
+// splitKeepingNewlines splits text into lines that keep their newline, so
+// joining them restores the exact bytes.
+func splitKeepingNewlines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
@@ -0,0 +1,80 @@
+package ergo
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEditTaskAppliesOnlyOverAnUnchangedBase(t *testing.T) {
+	app := newTestApplication(t)
+	created, err := app.CreateTask(CreateTaskRequest{Title: "Draft", Body: "one\ntwo\nthree\n"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	base := TaskText{Title: "Draft", Body: "one\ntwo\nthree\n"}
+	outcome, err := app.EditTask(EditTaskRequest{ID: created.ID, Base: base, Edited: base})
+	if err != nil || len(outcome.ChangedFields) != 0 {
+		t.Fatalf("unchanged edit = %#v, %v", outcome, err)
+	}
+
+	edited := TaskText{Title: "Final", Body: "one\ntwo\nthree\nfour\n"}
+	outcome, err = app.EditTask(EditTaskRequest{ID: created.ID, Base: base, Edited: edited, TitleSet: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task := mustList(t, app).Tasks[created.ID]; task.Title != "Final" || task.Body != edited.Body {
+		t.Fatalf("edited task = %q %q", task.Title, task.Body)
+	}
+
+	// The stored text is now edited; an edit that began from base is stale.
+	_, err = app.EditTask(EditTaskRequest{ID: created.ID, Base: base, Edited: TaskText{Body: "ONE\ntwo\nthree\n"}})
+	requireApplicationError(t, err, ErrorConflict)
+	var conflict *EditConflict
+	if !errors.As(err, &conflict) {
+		t.Fatalf("error %v is not an EditConflict", err)
+	}
+	if conflict.Current != edited || !conflict.Clean || conflict.Merged.Body != "ONE\ntwo\nthree\nfour\n" || conflict.Merged.Title != "Final" {
+		t.Fatalf("conflict = %#v", conflict)
+	}
+	if task := mustList(t, app).Tasks[created.ID]; task.Body != edited.Body {
+		t.Fatalf("stale edit wrote %q", task.Body)
+	}
+
+	_, err = app.EditTask(EditTaskRequest{ID: created.ID, Base: base, Edited: TaskText{Body: "one\ntwo\nthree\n5\n"}})
+	if !errors.As(err, &conflict) || conflict.Clean {
+		t.Fatalf("overlapping edit = %v", err)
+	}
+	_, err = app.EditTask(EditTaskRequest{ID: created.ID, Base: edited, Edited: TaskText{Title: " "}, TitleSet: true})
+	requireApplicationError(t, err, ErrorUsage)
+}
+
+func TestMergeLines(t *testing.T) {
+	for _, test := range []struct {
+		name, base, ours, theirs, want string
+		clean                          bool
+	}{
+		{"only ours", "a\nb\n", "a\nB\n", "a\nb\n", "a\nB\n", true},
+		{"only theirs", "a\nb\n", "a\nb\n", "A\nb\n", "A\nb\n", true},
+		{"apart", "a\nb\nc\nd\n", "A\nb\nc\nd\n", "a\nb\nc\nD\n", "A\nb\nc\nD\n", true},
+		{"same change", "a\nb\n", "a\nB\n", "a\nB\n", "a\nB\n", true},
+		{"no final newline", "a\nb\nc", "A\nb\nc", "a\nb\nc!", "A\nb\nc!", true},
+		{"both append", "a\n", "a\nb\n", "a\nc\n", "a\nb\n", false},
+		{"adjacent", "a\nb\n", "A\nb\n", "a\nB\n", "A\nb\n", false},
+	} {
+		got, clean := mergeLines(test.base, test.ours, test.theirs)
+		if got != test.want || clean != test.clean {
+			t.Errorf("%s: mergeLines = %q, %v; want %q, %v", test.name, got, clean, test.want, test.clean)
+		}
+	}
+}
+
+func TestEditTextRoundTrips(t *testing.T) {
+	for _, text := range []TaskText{{Title: "T", Body: "body\n"}, {Title: "T", Body: ""}, {Title: "T", Body: "\nleading blank\n"}} {
+		if got := ParseEditText(FormatEditText(text, true), true); got != text {
+			t.Errorf("round trip of %#v = %#v", text, got)
+		}
+		if got := ParseEditText(FormatEditText(text, false), false); got.Body != text.Body {
+			t.Errorf("body round trip of %q = %q", text.Body, got.Body)
+		}
+	}
+}
@@ -19,6 +19,7 @@ const (
 	ErrorNotFound   ErrorKind = "not_found"
 	ErrorConflict   ErrorKind = "conflict"
 	ErrorBusy       ErrorKind = "busy"
+	ErrorTimeout    ErrorKind = "timeout"
 	ErrorCorruption ErrorKind = "corruption"
 	ErrorInternal   ErrorKind = "internal"
 )
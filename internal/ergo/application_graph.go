@@ -0,0 +1,44 @@
+// Purpose: Define the dependency-graph export use case.
+// Exports: GraphRequest, GraphOutcome, and Application.ExportGraph.
+// Role: Reuse the list view's tree and visibility rules for diagram output.
+// Invariants: only nodes visible in the equivalent list appear, and edges are
+// drawn only between visible nodes.
+package ergo
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Graph export formats.
+const (
+	graphFormatDOT     = "dot"
+	graphFormatMermaid = "mermaid"
+)
+
+type GraphRequest struct {
+	EpicID  string
+	Format  string
+	ShowAll bool
+}
+
+type GraphOutcome struct {
+	Format string
+	Graph  *Graph
+	Roots  []*treeNode
+}
+
+func (a *Application) ExportGraph(request GraphRequest) (GraphOutcome, error) {
+	format := strings.ToLower(strings.TrimSpace(request.Format))
+	if format == "" {
+		format = graphFormatDOT
+	}
+	if format != graphFormatDOT && format != graphFormatMermaid {
+		return GraphOutcome{}, classified(ErrorUsage, fmt.Errorf("unknown graph format %q; use dot or mermaid", request.Format))
+	}
+	listed, err := a.List(ListRequest{EpicID: request.EpicID, ShowAll: request.ShowAll, OmitJournal: true})
+	if err != nil {
+		return GraphOutcome{}, err
+	}
+	return GraphOutcome{Format: format, Graph: listed.Graph, Roots: listed.Roots}, nil
+}
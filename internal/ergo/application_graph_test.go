@@ -0,0 +1,88 @@
+package ergo
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestExportGraphRendersVisibleNodesAndEdges(t *testing.T) {
+	app := newTestApplication(t)
+	epic, err := app.CreateTask(CreateTaskRequest{Title: "Auth"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema, err := app.CreateTask(CreateTaskRequest{Title: `Schema "v2"`, EpicID: epic.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	endpoints, err := app.CreateTask(CreateTaskRequest{Title: "Endpoints", EpicID: epic.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	loose, err := app.CreateTask(CreateTaskRequest{Title: "Loose"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	finished, err := app.CreateTask(CreateTaskRequest{Title: "Finished"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.Sequence(SequenceRequest{Command: "sequence", EventType: "link", IDs: []string{schema.ID, endpoints.ID}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.Sequence(SequenceRequest{Command: "sequence", EventType: "link", IDs: []string{epic.ID, loose.ID}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.Sequence(SequenceRequest{Command: "sequence", EventType: "link", IDs: []string{finished.ID, loose.ID}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.Lifecycle(LifecycleRequest{Kind: "done", ID: finished.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	dot, err := app.ExportGraph(GraphRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var output bytes.Buffer
+	RenderGraph(&output, dot)
+	for _, fact := range []string{
+		`subgraph "cluster_` + epic.ID + `" {`,
+		`"` + schema.ID + `" [label="` + schema.ID + ` ○ Schema \"v2\""`,
+		`"` + schema.ID + `" -> "` + endpoints.ID + `";`,
+		`"` + schema.ID + `" -> "` + loose.ID + `" [ltail="cluster_` + epic.ID + `"];`,
+	} {
+		if !strings.Contains(output.String(), fact) {
+			t.Fatalf("DOT lacks %s:\n%s", fact, output.String())
+		}
+	}
+	if strings.Contains(output.String(), finished.ID) {
+		t.Fatalf("DOT draws hidden done task:\n%s", output.String())
+	}
+
+	mermaid, err := app.ExportGraph(GraphRequest{Format: "mermaid", ShowAll: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	output.Reset()
+	RenderGraph(&output, mermaid)
+	for _, fact := range []string{
+		"```mermaid\nflowchart LR\n",
+		"  subgraph " + epic.ID + `["` + epic.ID + ` ◈ Auth"]`,
+		"    " + schema.ID + `["` + schema.ID + ` ○ Schema #quot;v2#quot;"]:::ready`,
+		"  " + epic.ID + " --> " + loose.ID,
+		"  " + finished.ID + " --> " + loose.ID,
+		"  class " + epic.ID + " epic_active",
+		"  classDef done ",
+	} {
+		if !strings.Contains(output.String(), fact) {
+			t.Fatalf("Mermaid lacks %q:\n%s", fact, output.String())
+		}
+	}
+
+	_, err = app.ExportGraph(GraphRequest{Format: "svg"})
+	requireApplicationError(t, err, ErrorUsage)
+	_, err = app.ExportGraph(GraphRequest{EpicID: loose.ID})
+	requireApplicationError(t, err, ErrorNotFound)
+}
@@ -0,0 +1,231 @@
+// Purpose: Define the per-task audit use case over the backlog and journal.
+// Exports: HistoryRequest, HistoryOutcome, HistoryEntry, and Application.History.
+// Role: Decode every event touching one ID and interleave its journal entries.
+// Invariants: entries keep their physical provenance; ties in time keep file
+// order with backlog events first.
+// Notes: history behind a compaction snapshot is gone; the outcome says so.
+package ergo
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// History entry origins.
+const (
+	historyBacklog = "backlog"
+	historyJournal = "journal"
+)
+
+type HistoryRequest struct {
+	ID string
+}
+
+type HistoryOutcome struct {
+	ID    string
+	Title string
+	// Compacted reports that the log begins with a snapshot, so events before
+	// the last compaction are gone.
+	Compacted bool
+	Entries   []HistoryEntry
+}
+
+type HistoryEntry struct {
+	At     string
+	Origin string
+	Path   string
+	Line   int
+	Kind   string
+	Agent  string
+	Text   string
+	// Diff holds removed ("-") and added ("+") lines for title and body edits.
+	Diff []string
+}
+
+// historyFields tracks the values a task held before each event so edits can
+// be shown as changes.
+type historyFields struct {
+	title, body, state, priority string
+}
+
+func (a *Application) History(request HistoryRequest) (HistoryOutcome, error) {
+	id := strings.TrimSpace(request.ID)
+	if id == "" {
+		return HistoryOutcome{}, classified(ErrorUsage, errors.New("usage: ergo history <id>"))
+	}
+	var repository Repository
+	if err := repository.Open(a.repository); err != nil {
+		return HistoryOutcome{}, classifyRepositoryError(err)
+	}
+	events, journal, err := repository.viewLog()
+	if err != nil {
+		return HistoryOutcome{}, classifyRepositoryError(err)
+	}
+	outcome := HistoryOutcome{ID: id, Compacted: events.snapshot != nil}
+	var prior historyFields
+	seen := false
+	if events.snapshot != nil {
+		if task := events.snapshot.Tasks[id]; task != nil {
+			prior = historyFields{title: task.Title, body: task.Body, state: task.State, priority: effectivePriority(task)}
+			seen = true
+		}
+		if _, pruned := events.snapshot.Tombstones[id]; pruned {
+			seen = true
+		}
+	}
+	for index, event := range events.events {
+		decoded, err := decodeEvent(event, index)
+		if err != nil {
+			return HistoryOutcome{}, classified(ErrorCorruption, err)
+		}
+		if !containsString(eventTaskIDs(decoded.payload), id) {
+			continue
+		}
+		seen = true
+		entry := HistoryEntry{
+			At: event.TS, Origin: historyBacklog, Path: event.Source.Path, Line: event.Source.Line, Kind: decoded.kind,
+		}
+		describeHistoryEvent(&entry, decoded.payload, id, &prior)
+		outcome.Entries = append(outcome.Entries, entry)
+	}
+	for index, entry := range journal.entries {
+		if entry.TaskID != id {
+			continue
+		}
+		seen = true
+		text := entry.Text
+		if entry.File != nil {
+			text = strings.TrimSpace(text + " [" + entry.File.Path + "]")
+		}
+		outcome.Entries = append(outcome.Entries, HistoryEntry{
+			At: entry.At, Origin: historyJournal, Path: repository.journalPath, Line: journal.lines[index],
+			Kind: entry.Kind, Agent: entry.Agent, Text: text,
+		})
+	}
+	if !seen {
+		return HistoryOutcome{}, classified(ErrorNotFound, fmt.Errorf("unknown task id %s", id))
+	}
+	outcome.Title = prior.title
+	sort.SliceStable(outcome.Entries, func(i, j int) bool {
+		left, leftErr := parseTime(outcome.Entries[i].At)
+		right, rightErr := parseTime(outcome.Entries[j].At)
+		if leftErr != nil || rightErr != nil {
+			return outcome.Entries[i].At < outcome.Entries[j].At
+		}
+		return left.Before(right)
+	})
+	return outcome, nil
+}
+
+// describeHistoryEvent fills the entry's agent, text, and diff from the point
+// of view of id, and advances prior past the event.
+func describeHistoryEvent(entry *HistoryEntry, payload any, id string, prior *historyFields) {
+	switch data := payload.(type) {
+	case NewTaskEvent:
+		if data.ID != id {
+			entry.Text = fmt.Sprintf("gained child %s %q", data.ID, data.Title)
+			return
+		}
+		*prior = historyFields{title: data.Title, body: data.Body, state: data.State, priority: data.Priority}
+		if prior.priority == "" {
+			prior.priority = defaultPriority
+		}
+		entry.Text = fmt.Sprintf("created %q as %s", data.Title, data.State)
+		if data.EpicID != "" {
+			entry.Text += " in epic " + data.EpicID
+		}
+		entry.Diff = diffLines("", data.Body)
+	case StateEvent:
+		entry.Text = historyChange("state", prior.state, data.NewState)
+		prior.state = data.NewState
+	case ClaimEvent:
+		entry.Agent = data.AgentID
+		entry.Text = "claimed"
+	case UnclaimEvent:
+		entry.Text = "claim released"
+	case LeaseEvent:
+		entry.Agent = data.AgentID
+		entry.Text = "lease until " + data.ExpiresAt
+	case LinkEvent:
+		verb := "now depends on"
+		if entry.Kind == eventUnlink {
+			verb = "no longer depends on"
+		}
+		if data.FromID == id {
+			entry.Text = verb + " " + data.ToID
+		} else {
+			entry.Text = data.FromID + " " + verb + " this task"
+		}
+	case TitleUpdateEvent:
+		entry.Text = "title changed"
+		entry.Diff = diffLines(prior.title, data.Title)
+		prior.title = data.Title
+	case BodyUpdateEvent:
+		entry.Text = fmt.Sprintf("body replaced (%d bytes)", len(data.Body))
+		entry.Diff = diffLines(prior.body, data.Body)
+		prior.body = data.Body
+	case EpicAssignEvent:
+		switch {
+		case data.ID != id:
+			entry.Text = "gained child " + data.ID
+		case data.EpicID == "":
+			entry.Text = "moved to the root"
+		default:
+			entry.Text = "moved into epic " + data.EpicID
+		}
+	case TombstoneEvent:
+		entry.Agent = data.AgentID
+		entry.Text = "pruned"
+	case ResultEvent:
+		entry.Text = data.Summary
+		if data.Path != "" {
+			entry.Text += " [" + data.Path + "]"
+		}
+	case MessageEvent:
+		entry.Text = data.Kind + ": " + data.Text
+	case PriorityEvent:
+		entry.Text = historyChange("priority", prior.priority, data.Priority)
+		prior.priority = data.Priority
+	case LabelEvent:
+		if entry.Kind == eventUnlabel {
+			entry.Text = "unlabeled " + data.Label
+		} else {
+			entry.Text = "labeled " + data.Label
+		}
+	}
+}
+
+func historyChange(field, before, after string) string {
+	if before == "" {
+		return field + " " + after
+	}
+	return field + " " + before + " → " + after
+}
+
+// diffLines returns a minimal line diff of before and after, keeping only
+// removed ("-") and added ("+") lines.
+func diffLines(before, after string) []string {
+	if before == after {
+		return nil
+	}
+	old := splitDiffLines(before)
+	var diff []string
+	for _, hunk := range diffHunks(old, splitDiffLines(after)) {
+		for _, line := range old[hunk.start:hunk.end] {
+			diff = append(diff, "-"+line)
+		}
+		for _, line := range hunk.lines {
+			diff = append(diff, "+"+line)
+		}
+	}
+	return diff
+}
+
+func splitDiffLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
@@ -0,0 +1,99 @@
+package ergo
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestHistoryInterleavesEventsAndJournalWithDiffs(t *testing.T) {
+	app := newTestApplication(t)
+	epic, err := app.CreateTask(CreateTaskRequest{Title: "Epic"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	task, err := app.CreateTask(CreateTaskRequest{Title: "Draft title", Body: "one\ntwo\n"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.UpdateTitle(UpdateTitleRequest{ID: task.ID, Title: "Final title"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.UpdateBody(UpdateBodyRequest{ID: task.ID, Body: []byte("one\n2\nthree\n")}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.Move(MoveRequest{ID: task.ID, DestinationID: epic.ID}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.Claim(ClaimRequest{ID: task.ID, AgentID: "worker"}); err != nil {
+		t.Fatal(err)
+	}
+
+	history, err := app.History(HistoryRequest{ID: task.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var kinds []string
+	for _, entry := range history.Entries {
+		kinds = append(kinds, entry.Origin+":"+entry.Kind)
+	}
+	want := []string{
+		"backlog:new_task", "journal:created", "backlog:title", "backlog:body", "backlog:epic",
+		"backlog:claim", "backlog:state", "journal:claim",
+	}
+	if !equalStrings(kinds, want) {
+		t.Fatalf("history kinds = %v, want %v", kinds, want)
+	}
+	if history.Title != "Final title" || history.Compacted {
+		t.Fatalf("history header = %q compacted=%v", history.Title, history.Compacted)
+	}
+	if diff := history.Entries[2].Diff; !equalStrings(diff, []string{"-Draft title", "+Final title"}) {
+		t.Fatalf("title diff = %v", diff)
+	}
+	if diff := history.Entries[3].Diff; !equalStrings(diff, []string{"-two", "+2", "+three"}) {
+		t.Fatalf("body diff = %v", diff)
+	}
+	if entry := history.Entries[6]; entry.Text != "state todo → doing" || entry.Line != 6 {
+		t.Fatalf("state entry = %#v", entry)
+	}
+
+	parent, err := app.History(HistoryRequest{ID: epic.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last := parent.Entries[len(parent.Entries)-1]; last.Text != "gained child "+task.ID {
+		t.Fatalf("epic history ends with %#v", last)
+	}
+	_, err = app.History(HistoryRequest{ID: "ZZZZZZ"})
+	requireApplicationError(t, err, ErrorNotFound)
+}
+
+func TestHistoryJSONAndCompaction(t *testing.T) {
+	app := newTestApplication(t)
+	task, err := app.CreateTask(CreateTaskRequest{Title: "Compacted"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.Compact(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.UpdateTitle(UpdateTitleRequest{ID: task.ID, Title: "Retitled"}); err != nil {
+		t.Fatal(err)
+	}
+	history, err := app.History(HistoryRequest{ID: task.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !history.Compacted || !equalStrings(history.Entries[len(history.Entries)-1].Diff, []string{"-Compacted", "+Retitled"}) {
+		t.Fatalf("history after compaction = %#v", history)
+	}
+	var output bytes.Buffer
+	if err := RenderHistoryJSON(&output, history); err != nil {
+		t.Fatal(err)
+	}
+	for _, fact := range []string{`"version":1`, `"compacted":true`, `"origin":"backlog"`, `"diff":["-Compacted","+Retitled"]`} {
+		if !strings.Contains(output.String(), fact) {
+			t.Fatalf("history JSON lacks %s: %s", fact, output.String())
+		}
+	}
+}
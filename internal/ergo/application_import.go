@@ -0,0 +1,101 @@
+// Purpose: Import another tracker's export as one atomic backlog change.
+// Exports: ImportBeadsRequest, ImportBeadsOutcome, ImportedTask, ImportNote,
+// and Application.ImportBeads.
+// Role: Resolve and read the export, plan it as batch operations, and either
+// append them in one transaction or, for a dry run, validate them against the
+// current backlog without writing.
+// Invariants: an import writes everything or nothing; a dry run never writes.
+package ergo
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type ImportBeadsRequest struct {
+	// Path is a beads issues.jsonl file, a .beads directory, or a project
+	// directory containing one.
+	Path   string
+	DryRun bool
+}
+
+type ImportBeadsOutcome struct {
+	// Source is the issues file that was read.
+	Source string
+	DryRun bool
+	// Created lists imported issues in creation order: epics, then tasks.
+	// IDs are empty in a dry run.
+	Created      []ImportedTask
+	Epics, Tasks int
+	Dependencies int
+	// Skipped counts deleted issues, which are not imported.
+	Skipped int
+	Notes   []ImportNote
+}
+
+type ImportedTask struct {
+	SourceID, ID, Title string
+	Epic                bool
+}
+
+// ImportNote reports data that ergo could not carry over as it was.
+type ImportNote struct {
+	Field, Detail string
+	Issues        []string
+}
+
+func (a *Application) ImportBeads(request ImportBeadsRequest) (ImportBeadsOutcome, error) {
+	if strings.TrimSpace(request.Path) == "" {
+		return ImportBeadsOutcome{}, classified(ErrorUsage, errors.New("usage: ergo import beads <path> [--dry-run]"))
+	}
+	source, err := findBeadsIssues(request.Path)
+	if err != nil {
+		return ImportBeadsOutcome{}, err
+	}
+	issues, err := readBeadsIssues(source)
+	if err != nil {
+		return ImportBeadsOutcome{}, fmt.Errorf("%s: %w", source, err)
+	}
+	plan, err := planBeadsImport(issues)
+	if err != nil {
+		return ImportBeadsOutcome{}, fmt.Errorf("%s: %w", source, err)
+	}
+	outcome := ImportBeadsOutcome{
+		Source: source, DryRun: request.DryRun, Epics: plan.epics, Tasks: plan.tasks,
+		Dependencies: plan.dependencies, Skipped: plan.skipped, Notes: plan.notes.notes,
+	}
+	var repository Repository
+	if err := repository.Open(a.repository); err != nil {
+		return ImportBeadsOutcome{}, classifyRepositoryError(err)
+	}
+	var created []BatchCreated
+	if request.DryRun {
+		graph, err := repository.View()
+		if err == nil {
+			_, _, created, err = applyBatch(graph, plan.operations, time.Now().UTC())
+		}
+		if err != nil {
+			return ImportBeadsOutcome{}, fmt.Errorf("%s: %w", source, classifyRepositoryError(err))
+		}
+	} else {
+		_, err := repository.UpdateWithJournal(func(graph *Graph) ([]Event, []JournalEntry, error) {
+			events, journal, batchCreated, err := applyBatch(graph, plan.operations, time.Now().UTC())
+			created = batchCreated
+			return events, journal, err
+		})
+		if err != nil {
+			return ImportBeadsOutcome{}, fmt.Errorf("%s: %w", source, classifyRepositoryError(err))
+		}
+	}
+	for _, task := range created {
+		issue := plan.sources[task.Handle]
+		imported := ImportedTask{SourceID: issue.ID, ID: task.ID, Title: task.Title, Epic: issue.epic}
+		if request.DryRun {
+			imported.ID = ""
+		}
+		outcome.Created = append(outcome.Created, imported)
+	}
+	return outcome, nil
+}
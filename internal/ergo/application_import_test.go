@@ -0,0 +1,137 @@
+package ergo
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const beadsFixture = `{"id":"bd-1","title":"Launch","description":"Ship v1","status":"open","priority":1,"issue_type":"epic","created_at":"2025-01-02T03:04:05Z"}
+{"id":"bd-2","title":"Design","description":"Sketch the API","design":"REST","status":"closed","close_reason":"Agreed in review","priority":0,"issue_type":"task","labels":["API"],"created_at":"2025-01-03T00:00:00Z","closed_at":"2025-01-05T00:00:00Z","dependencies":[{"issue_id":"bd-2","depends_on_id":"bd-1","type":"parent-child"}],"comments":[{"author":"ana","text":"Looks good","created_at":"2025-01-04T00:00:00Z"}]}
+{"id":"bd-3","title":"Build","status":"in_progress","priority":4,"assignee":"bob","estimated_minutes":90,"dependencies":[{"issue_id":"bd-3","depends_on_id":"bd-1","type":"parent-child"},{"issue_id":"bd-3","depends_on_id":"bd-2","type":"blocks"},{"issue_id":"bd-3","depends_on_id":"bd-9","type":"related"}]}
+
+{"id":"bd-4","title":"Polish","status":"deferred","dependencies":[{"issue_id":"bd-4","depends_on_id":"bd-3","type":"parent-child"}]}
+{"id":"bd-5","title":"Waiting on vendor","status":"blocked","priority":2}
+{"id":"bd-6","title":"Removed","status":"tombstone"}
+`
+
+func writeBeadsFixture(t *testing.T, content string) string {
+	t.Helper()
+	project := t.TempDir()
+	if err := os.Mkdir(filepath.Join(project, ".beads"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(project, ".beads", "issues.jsonl"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return project
+}
+
+func TestImportBeadsMapsIssuesInOneTransaction(t *testing.T) {
+	app := newTestApplication(t)
+	project := writeBeadsFixture(t, beadsFixture)
+	before := backlogRecords(t, app)
+
+	preview, err := app.ImportBeads(ImportBeadsRequest{Path: project, DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := backlogRecords(t, app); got != before {
+		t.Fatalf("dry run appended %d records", got-before)
+	}
+	if preview.Epics != 1 || preview.Tasks != 4 || preview.Dependencies != 1 || preview.Skipped != 1 || preview.Created[0].ID != "" {
+		t.Fatalf("dry run = %#v", preview)
+	}
+	notes := map[string][]string{}
+	for _, note := range preview.Notes {
+		notes[note.Field+": "+note.Detail] = note.Issues
+	}
+	for _, want := range []string{
+		"estimated_minutes: no ergo equivalent",
+		"priority: 4 (backlog) imported as P3",
+		"status: in_progress imported as todo; claims are not imported",
+		"dependencies: related has no ergo equivalent",
+		"dependencies: nested parent-child; joined the top-level epic",
+	} {
+		if len(notes[want]) == 0 {
+			t.Errorf("dry run lacks note %q in %v", want, notes)
+		}
+	}
+
+	outcome, err := app.ImportBeads(ImportBeadsRequest{Path: project})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := backlogRecords(t, app); got != before+1 {
+		t.Fatalf("import appended %d records, want 1", got-before)
+	}
+	ids := map[string]string{}
+	for _, task := range outcome.Created {
+		ids[task.SourceID] = task.ID
+	}
+	graph := mustList(t, app)
+	epic, design, build, polish, vendor := graph.Tasks[ids["bd-1"]], graph.Tasks[ids["bd-2"]], graph.Tasks[ids["bd-3"]], graph.Tasks[ids["bd-4"]], graph.Tasks[ids["bd-5"]]
+	if !graph.IsEpic(epic.ID) || design.EpicID != epic.ID || build.EpicID != epic.ID || polish.EpicID != epic.ID || vendor.EpicID != "" {
+		t.Fatalf("placement = %#v", ids)
+	}
+	if design.State != stateDone || build.State != stateTodo || polish.State != stateDraft || vendor.State != stateBlocked {
+		t.Fatalf("states = %s %s %s %s", design.State, build.State, polish.State, vendor.State)
+	}
+	if design.Priority != "P0" || build.Priority != "P3" || len(design.Labels) != 1 || design.Labels[0] != "api" {
+		t.Fatalf("design = %#v, build = %#v", design, build)
+	}
+	if blockers := graph.Deps[build.ID]; len(blockers) != 1 {
+		t.Fatalf("build depends on %v", blockers)
+	} else if _, ok := blockers[design.ID]; !ok {
+		t.Fatalf("build depends on %v, want %s", blockers, design.ID)
+	}
+	for _, want := range []string{"Sketch the API\n", "## Design\n\nREST\n", "- **ana** — 2025-01-04T00:00:00Z\n  Looks good\n", "Beads-ID: bd-2\n", "Beads-Closed-At: 2025-01-05T00:00:00Z\n"} {
+		if !strings.Contains(design.Body, want) {
+			t.Errorf("design body lacks %q:\n%s", want, design.Body)
+		}
+	}
+	if len(design.Messages) != 1 || design.Messages[0].Text != "Agreed in review" {
+		t.Fatalf("design messages = %#v", design.Messages)
+	}
+}
+
+func TestImportBeadsDropsBlocksThatCloseACycle(t *testing.T) {
+	app := newTestApplication(t)
+	project := writeBeadsFixture(t, `{"id":"bd-1","title":"A","status":"open","dependencies":[{"issue_id":"bd-1","depends_on_id":"bd-3","type":"blocks"}]}
+{"id":"bd-2","title":"B","status":"open","dependencies":[{"issue_id":"bd-2","depends_on_id":"bd-1","type":"blocks"}]}
+{"id":"bd-3","title":"C","status":"open","dependencies":[{"issue_id":"bd-3","depends_on_id":"bd-2","type":"blocks"},{"issue_id":"bd-3","depends_on_id":"bd-3","type":"blocks"}]}
+`)
+	for _, dryRun := range []bool{true, false} {
+		outcome, err := app.ImportBeads(ImportBeadsRequest{Path: project, DryRun: dryRun})
+		if err != nil {
+			t.Fatalf("cyclic import (dry run %v): %v", dryRun, err)
+		}
+		if outcome.Dependencies != 2 || len(outcome.Notes) != 1 || outcome.Notes[0].Detail != "blocks cycle; edge not imported" || !equalStrings(outcome.Notes[0].Issues, []string{"bd-3"}) {
+			t.Fatalf("cyclic import (dry run %v) = %#v", dryRun, outcome)
+		}
+		if dryRun {
+			continue
+		}
+		ids := map[string]string{}
+		for _, task := range outcome.Created {
+			ids[task.SourceID] = task.ID
+		}
+		graph := mustList(t, app)
+		if len(graph.Deps[ids["bd-1"]]) != 1 || len(graph.Deps[ids["bd-2"]]) != 1 || len(graph.Deps[ids["bd-3"]]) != 0 {
+			t.Fatalf("imported deps = %v for %v", graph.Deps, ids)
+		}
+	}
+}
+
+func TestImportBeadsWritesNothingWhenRefused(t *testing.T) {
+	app := newTestApplication(t)
+	before := backlogRecords(t, app)
+	_, err := app.ImportBeads(ImportBeadsRequest{Path: t.TempDir()})
+	requireApplicationError(t, err, ErrorNotFound)
+	_, err = app.ImportBeads(ImportBeadsRequest{Path: writeBeadsFixture(t, "{\"id\":\"bd-1\"}\n{\"id\":\"bd-1\"}\n")})
+	requireApplicationError(t, err, ErrorUsage)
+	if got := backlogRecords(t, app); got != before {
+		t.Fatalf("refused import appended %d records", got-before)
+	}
+}
@@ -0,0 +1,122 @@
+package ergo
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestApplicationLabelAddRemoveAndCompaction(t *testing.T) {
+	app := newTestApplication(t)
+	epic, err := app.CreateTask(CreateTaskRequest{Title: "Epic"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	child, err := app.CreateTask(CreateTaskRequest{Title: "Child", EpicID: epic.ID, Labels: []string{"Backend", "api"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = app.CreateTask(CreateTaskRequest{Title: "Bad", Labels: []string{"has space"}})
+	requireApplicationError(t, err, ErrorUsage)
+
+	added, err := app.Label(LabelRequest{ID: child.ID, Labels: []string{"infra", "backend"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !added.Changed || !reflect.DeepEqual(added.Labels, []string{"api", "backend", "infra"}) {
+		t.Fatalf("label add outcome = %#v", added)
+	}
+	unchanged, err := app.Label(LabelRequest{ID: child.ID, Labels: []string{"missing"}, Remove: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if unchanged.Changed {
+		t.Fatal("removing an absent label changed the task")
+	}
+	removed, err := app.Label(LabelRequest{ID: child.ID, Labels: []string{"api"}, Remove: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !removed.Changed || !reflect.DeepEqual(removed.Labels, []string{"backend", "infra"}) {
+		t.Fatalf("label remove outcome = %#v", removed)
+	}
+	_, err = app.Label(LabelRequest{ID: epic.ID, Labels: []string{"backend"}})
+	requireApplicationError(t, err, ErrorConflict)
+
+	if _, err := app.Compact(); err != nil {
+		t.Fatal(err)
+	}
+	shown, err := app.Show(ShowRequest{ID: child.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(shown.Task.Labels, []string{"backend", "infra"}) {
+		t.Fatalf("compacted labels = %v", shown.Task.Labels)
+	}
+}
+
+func TestLabelFiltersPartitionListClaimAndPrune(t *testing.T) {
+	app := newTestApplication(t)
+	create := func(title string, labels ...string) string {
+		t.Helper()
+		created, err := app.CreateTask(CreateTaskRequest{Title: title, Labels: labels})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return created.ID
+	}
+	frontend := create("Frontend", "frontend")
+	backend := create("Backend", "backend")
+	shared := create("Shared", "backend", "frontend")
+
+	listed, err := app.List(ListRequest{Labels: []string{"backend"}, NotLabels: []string{"frontend"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(listed.Roots) != 1 || listed.Roots[0].task.ID != backend || len(listed.ReadyTasks) != 1 {
+		t.Fatalf("filtered list roots = %d, ready = %d", len(listed.Roots), len(listed.ReadyTasks))
+	}
+	_, err = app.List(ListRequest{Labels: []string{"backend"}, NotLabels: []string{"Backend"}})
+	requireApplicationError(t, err, ErrorUsage)
+
+	_, err = app.Claim(ClaimRequest{ID: frontend, AgentID: "agent", Labels: []string{"frontend"}})
+	requireApplicationError(t, err, ErrorUsage)
+	claimed := map[string]bool{}
+	for range 2 {
+		outcome, err := app.Claim(ClaimRequest{AgentID: "backend-agent", Labels: []string{"backend"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		claimed[outcome.Task.ID] = true
+	}
+	if !claimed[backend] || !claimed[shared] {
+		t.Fatalf("backend agent claimed %v", claimed)
+	}
+	none, err := app.Claim(ClaimRequest{AgentID: "backend-agent", Labels: []string{"backend"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !none.NoReady || !reflect.DeepEqual(none.Labels, []string{"backend"}) {
+		t.Fatalf("exhausted label claim = %#v", none)
+	}
+
+	for _, id := range []string{frontend, backend, shared} {
+		if id == frontend {
+			if _, err := app.Claim(ClaimRequest{ID: id, AgentID: "frontend-agent"}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err := app.Lifecycle(LifecycleRequest{Kind: "done", ID: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pruned, err := app.Prune(PruneRequest{Confirm: true, Labels: []string{"frontend"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pruned.Items) != 2 {
+		t.Fatalf("label prune items = %#v", pruned.Items)
+	}
+	if _, err := app.Show(ShowRequest{ID: backend}); err != nil {
+		t.Fatalf("unlabeled finished task was pruned: %v", err)
+	}
+}
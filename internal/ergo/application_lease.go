@@ -0,0 +1,105 @@
+// Purpose: Define claim leases and the heartbeat use case that renews them.
+// Exports: HeartbeatRequest, HeartbeatOutcome, and Application.Heartbeat.
+// Role: Keep leased claims alive and record when an abandoned lease is reclaimed.
+// Invariants: only the current claimant may renew; expiry itself never writes.
+// Notes: readiness treats lapsed leases as unclaimed; the next claim records it.
+package ergo
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type HeartbeatRequest struct {
+	ID      string
+	AgentID string
+	// Lease replaces the current lease duration when positive.
+	Lease      time.Duration
+	IfRevision string
+}
+
+type HeartbeatOutcome struct {
+	ID        string
+	AgentID   string
+	Lease     time.Duration
+	ExpiresAt time.Time
+}
+
+func (a *Application) Heartbeat(request HeartbeatRequest) (HeartbeatOutcome, error) {
+	id := strings.TrimSpace(request.ID)
+	agentID := strings.TrimSpace(request.AgentID)
+	if id == "" || agentID == "" {
+		return HeartbeatOutcome{}, classified(ErrorUsage, errors.New("usage: ergo heartbeat <id> --agent <identity> [--lease <duration>]"))
+	}
+	if err := validateLease(request.Lease); err != nil {
+		return HeartbeatOutcome{}, classified(ErrorUsage, err)
+	}
+	var repository Repository
+	if err := repository.Open(a.repository); err != nil {
+		return HeartbeatOutcome{}, classifyRepositoryError(err)
+	}
+	outcome := HeartbeatOutcome{ID: id, AgentID: agentID}
+	_, err := repository.Update(func(graph *Graph) ([]Event, error) {
+		if _, pruned := graph.Tombstones[id]; pruned {
+			return nil, classified(ErrorNotFound, prunedErr(id))
+		}
+		task := graph.Tasks[id]
+		if task == nil {
+			return nil, classified(ErrorNotFound, fmt.Errorf("unknown task id %s", id))
+		}
+		if err := checkRevision(graph, id, request.IfRevision); err != nil {
+			return nil, err
+		}
+		if task.State != stateDoing || task.ClaimedBy == "" {
+			return nil, classified(ErrorConflict, fmt.Errorf("task %s is not claimed; use claim %s --agent <identity> --lease <duration>", id, id))
+		}
+		if task.ClaimedBy != agentID {
+			return nil, classified(ErrorConflict, fmt.Errorf("task %s is claimed by %s", id, task.ClaimedBy))
+		}
+		kind, lease := eventHeartbeat, task.Lease
+		if lease == 0 {
+			kind = eventLease
+		}
+		if request.Lease > 0 {
+			lease = request.Lease
+		}
+		if lease == 0 {
+			return nil, classified(ErrorConflict, fmt.Errorf("task %s has no lease; pass --lease <duration>", id))
+		}
+		now := time.Now().UTC()
+		outcome.Lease, outcome.ExpiresAt = lease, now.Add(lease)
+		event, err := newEvent(kind, now, LeaseEvent{
+			ID: id, AgentID: agentID, ExpiresAt: formatTime(outcome.ExpiresAt), TS: formatTime(now),
+		})
+		if err != nil {
+			return nil, err
+		}
+		return []Event{event}, nil
+	})
+	if err != nil {
+		return HeartbeatOutcome{}, classifyRepositoryError(err)
+	}
+	return outcome, nil
+}
+
+func validateLease(lease time.Duration) error {
+	if lease < 0 {
+		return errors.New("--lease must be positive")
+	}
+	if lease > 0 && lease < time.Second {
+		return errors.New("--lease must be at least 1s")
+	}
+	return nil
+}
+
+// leaseExpiryJournal records the lapsed lease a claim is about to replace, so
+// the reclaim story stays visible in show after the old claim is gone.
+func leaseExpiryJournal(task *Task, now time.Time) []JournalEntry {
+	if !task.leaseExpired(now) {
+		return nil
+	}
+	text := "lease expired at " + formatTime(task.LeaseExpiresAt)
+	return []JournalEntry{newJournalEntry(task.ID, "expire", task.ClaimedBy, text, now)}
+}
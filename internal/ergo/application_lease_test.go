@@ -0,0 +1,165 @@
+package ergo
+
+import (
+	"testing"
+	"time"
+)
+
+// expireLease rewrites a claimant's lease into the past through the ordinary
+// write path, standing in for an agent that stopped heartbeating.
+func expireLease(t *testing.T, app *Application, id, agent string) {
+	t.Helper()
+	var repository Repository
+	if err := repository.Open(app.repository); err != nil {
+		t.Fatal(err)
+	}
+	granted := time.Now().UTC().Add(-2 * time.Hour)
+	_, err := repository.Update(func(*Graph) ([]Event, error) {
+		return []Event{mustNewEvent(eventHeartbeat, granted, LeaseEvent{
+			ID: id, AgentID: agent, ExpiresAt: formatTime(granted.Add(time.Hour)), TS: formatTime(granted),
+		})}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestApplicationHeartbeatRenewsOnlyTheClaimantsLease(t *testing.T) {
+	app := newTestApplication(t)
+	leased, err := app.CreateTask(CreateTaskRequest{Title: "Leased"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	unleased, err := app.CreateTask(CreateTaskRequest{Title: "Unleased"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	claimed, err := app.Claim(ClaimRequest{ID: leased.ID, AgentID: "one", Lease: 30 * time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claimed.Task.Lease != 30*time.Minute || claimed.Task.LeaseExpiresAt.IsZero() {
+		t.Fatalf("claimed lease = %v until %v", claimed.Task.Lease, claimed.Task.LeaseExpiresAt)
+	}
+	if _, err := app.Claim(ClaimRequest{ID: unleased.ID, AgentID: "one"}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = app.Heartbeat(HeartbeatRequest{ID: leased.ID, AgentID: "two"})
+	requireApplicationError(t, err, ErrorConflict)
+	_, err = app.Heartbeat(HeartbeatRequest{ID: unleased.ID, AgentID: "one"})
+	requireApplicationError(t, err, ErrorConflict)
+	_, err = app.Claim(ClaimRequest{AgentID: "one", Lease: -time.Minute})
+	requireApplicationError(t, err, ErrorUsage)
+
+	renewed, err := app.Heartbeat(HeartbeatRequest{ID: leased.ID, AgentID: "one", Lease: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if renewed.Lease != time.Hour || !renewed.ExpiresAt.After(claimed.Task.LeaseExpiresAt) {
+		t.Fatalf("heartbeat outcome = %#v", renewed)
+	}
+	shown, err := app.Show(ShowRequest{ID: leased.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !shown.Task.LeaseExpiresAt.Equal(renewed.ExpiresAt) || shown.Graph.LeaseExpired(leased.ID) {
+		t.Fatalf("renewed lease = %v, expired=%v", shown.Task.LeaseExpiresAt, shown.Graph.LeaseExpired(leased.ID))
+	}
+}
+
+func TestExpiredLeaseReturnsWorkToOldestReadyClaim(t *testing.T) {
+	app := newTestApplication(t)
+	created, err := app.CreateTask(CreateTaskRequest{Title: "Abandoned"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.Claim(ClaimRequest{ID: created.ID, AgentID: "crashed", Lease: time.Minute}); err != nil {
+		t.Fatal(err)
+	}
+	listed, err := app.List(ListRequest{ReadyOnly: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(listed.Roots) != 0 {
+		t.Fatal("live lease was listed as ready")
+	}
+
+	expireLease(t, app, created.ID, "crashed")
+	shown, err := app.Show(ShowRequest{ID: created.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !shown.Graph.LeaseExpired(created.ID) || !shown.Graph.IsReady(created.ID) {
+		t.Fatal("expired lease was not treated as unclaimed")
+	}
+
+	reclaimed, err := app.Claim(ClaimRequest{AgentID: "rescuer"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reclaimed.NoReady || reclaimed.Task.ID != created.ID || reclaimed.Task.ClaimedBy != "rescuer" {
+		t.Fatalf("reclaim outcome = %#v", reclaimed)
+	}
+	if !reclaimed.Task.LeaseExpiresAt.IsZero() {
+		t.Fatal("unleased reclaim kept the stale lease")
+	}
+	journal := journalForTask(reclaimed.Journal, created.ID)
+	if len(journal) < 2 {
+		t.Fatalf("journal = %#v", journal)
+	}
+	expiry, claim := journal[len(journal)-2], journal[len(journal)-1]
+	if expiry.Kind != "expire" || expiry.Agent != "crashed" || claim.Kind != "claim" || claim.Agent != "rescuer" {
+		t.Fatalf("reclaim journal = %#v, %#v", expiry, claim)
+	}
+}
+
+func TestExplicitClaimMayTakeOverAnExpiredLease(t *testing.T) {
+	app := newTestApplication(t)
+	created, err := app.CreateTask(CreateTaskRequest{Title: "Take over"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.Claim(ClaimRequest{ID: created.ID, AgentID: "one", Lease: time.Minute}); err != nil {
+		t.Fatal(err)
+	}
+	_, err = app.Claim(ClaimRequest{ID: created.ID, AgentID: "two"})
+	requireApplicationError(t, err, ErrorConflict)
+
+	expireLease(t, app, created.ID, "one")
+	claimed, err := app.Claim(ClaimRequest{ID: created.ID, AgentID: "two", Lease: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claimed.Task.ClaimedBy != "two" || claimed.Task.Lease != time.Hour {
+		t.Fatalf("takeover = %#v", claimed.Task)
+	}
+	if entries := journalForTask(claimed.Journal, created.ID); entries[len(entries)-2].Kind != "expire" {
+		t.Fatalf("takeover journal = %#v", entries)
+	}
+}
+
+func TestCompactionPreservesLeases(t *testing.T) {
+	app := newTestApplication(t)
+	created, err := app.CreateTask(CreateTaskRequest{Title: "Leased"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	claimed, err := app.Claim(ClaimRequest{ID: created.ID, AgentID: "one", Lease: 45 * time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.Compact(); err != nil {
+		t.Fatal(err)
+	}
+	shown, err := app.Show(ShowRequest{ID: created.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shown.Task.Lease != 45*time.Minute || !shown.Task.LeaseExpiresAt.Equal(claimed.Task.LeaseExpiresAt) {
+		t.Fatalf("compacted lease = %v until %v", shown.Task.Lease, shown.Task.LeaseExpiresAt)
+	}
+	if _, err := app.Heartbeat(HeartbeatRequest{ID: created.ID, AgentID: "one"}); err != nil {
+		t.Fatalf("heartbeat after compaction: %v", err)
+	}
+}
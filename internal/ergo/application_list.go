@@ -21,19 +21,22 @@ func (a *Application) List(request ListRequest) (ListOutcome, error) {
 	if request.ReadyOnly && request.ShowAll {
 		return ListOutcome{}, classified(ErrorUsage, errors.New("conflicting flags: --ready and --all"))
 	}
-	var repository Repository
-	if err := repository.Open(a.repository); err != nil {
-		return ListOutcome{}, classifyRepositoryError(err)
+	filter, err := newLabelFilter(request.Labels, request.NotLabels)
+	if err != nil {
+		return ListOutcome{}, classified(ErrorUsage, err)
+	}
+	repository, err := a.openView(request.At)
+	if err != nil {
+		return ListOutcome{}, err
 	}
 	var graph *Graph
-	var err error
 	if request.OmitJournal {
 		graph, err = repository.ViewGraph()
 	} else {
 		graph, err = repository.View()
 	}
 	if err != nil {
-		return ListOutcome{}, classifyRepositoryError(err)
+		return ListOutcome{}, classifyViewError(err)
 	}
 	graph.prepareDerivedQueries()
 	if request.EpicID != "" {
@@ -42,15 +45,23 @@ func (a *Application) List(request ListRequest) (ListOutcome, error) {
 			return ListOutcome{}, classified(ErrorNotFound, fmt.Errorf("no such epic: %s", request.EpicID))
 		}
 	}
-	all := collectNonContainerTasks(graph)
+	all := filterTasksByLabels(collectNonContainerTasks(graph), filter)
+	roots := buildListRoots(graph, request.ShowAll, request.ReadyOnly, request.EpicID)
+	if filter.active() {
+		if request.EpicID != "" && len(roots) == 1 {
+			roots[0].children = filterNodesByLabels(roots[0].children, filter)
+		} else {
+			roots = filterNodesByLabels(roots, filter)
+		}
+	}
 	outcome := ListOutcome{
 		Options: request, Graph: graph,
-		Roots:       buildListRoots(graph, request.ShowAll, request.ReadyOnly, request.EpicID),
+		Roots:       roots,
 		AllTasks:    all,
 		ActiveTasks: filterActiveTasks(all), ReadyTasks: filterReadyTasks(all, graph),
 	}
 	if request.EpicID != "" {
-		outcome.EpicChildren = collectEpicChildren(request.EpicID, graph)
+		outcome.EpicChildren = filterTasksByLabels(collectEpicChildren(request.EpicID, graph), filter)
 		outcome.EpicReady = filterReadyTasks(outcome.EpicChildren, graph)
 	}
 	return outcome, nil
@@ -16,6 +16,20 @@ func (a *Application) Compact() (CompactOutcomeResult, error) {
 	return outcome, classifyRepositoryError(err)
 }
 
+// FsckRequest checks the repository; Repair also truncates interrupted tails
+// and drops orphaned journal entries.
+type FsckRequest struct{ Repair bool }
+type FsckOutcome = FsckReport
+
+func (a *Application) Fsck(request FsckRequest) (FsckOutcome, error) {
+	var repository Repository
+	if err := repository.Open(a.repository); err != nil {
+		return FsckOutcome{}, classifyRepositoryError(err)
+	}
+	outcome, err := repository.Fsck(request.Repair)
+	return outcome, classifyRepositoryError(err)
+}
+
 type WhereOutcome struct{ Path string }
 
 func (a *Application) Where() (WhereOutcome, error) {
@@ -37,7 +51,11 @@ func (a *Application) Where() (WhereOutcome, error) {
 	return WhereOutcome{Path: path}, nil
 }
 
-type PruneRequest struct{ Confirm bool }
+type PruneRequest struct {
+	Confirm bool
+	// Labels limits pruning to finished tasks carrying every label.
+	Labels []string
+}
 type PruneOutcome struct {
 	Confirmed      bool
 	Items          []PruneItem
@@ -45,15 +63,19 @@ type PruneOutcome struct {
 }
 
 func (a *Application) Prune(request PruneRequest) (PruneOutcome, error) {
+	filter, err := newLabelFilter(request.Labels, nil)
+	if err != nil {
+		return PruneOutcome{}, classified(ErrorUsage, err)
+	}
 	dir, err := ergoDir(a.repository)
 	if err != nil {
 		return PruneOutcome{}, classifyRepositoryError(err)
 	}
 	var plan PrunePlan
 	if request.Confirm {
-		plan, err = RunPruneApply(dir, a.repository)
+		plan, err = RunPruneApply(dir, a.repository, filter.Include...)
 	} else {
-		plan, err = RunPrunePlan(dir)
+		plan, err = RunPrunePlan(dir, filter.Include...)
 	}
 	if err != nil {
 		return PruneOutcome{}, classifyRepositoryError(err)
@@ -0,0 +1,196 @@
+// Purpose: Define the critical-path use case for one task or epic.
+// Exports: PathRequest, PathOutcome, PathLink, and Application.Path.
+// Role: Find the longest chain of unfinished work that gates the target and
+// the ready frontier inside that scope.
+// Invariants: chains run through leaf tasks only. A dependency on an epic
+// expands to the epic's unfinished children, and a task inherits its epic's
+// dependencies, matching Graph.Blockers.
+package ergo
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type PathRequest struct {
+	ID string
+}
+
+type PathOutcome struct {
+	Graph *Graph
+	Task  *Task
+	// Critical is the longest unfinished chain, dependencies first.
+	Critical []*Task
+	// Scope holds every unfinished task that gates the target, in claim order.
+	Scope []*Task
+	Links []PathLink
+	// Ready is the ready frontier within Scope: work that can start in
+	// parallel right now.
+	Ready []*Task
+}
+
+// PathLink is one dependency between unfinished tasks in scope; ToID waits
+// on FromID.
+type PathLink struct {
+	FromID, ToID string
+	Critical     bool
+}
+
+func (a *Application) Path(request PathRequest) (PathOutcome, error) {
+	id := strings.TrimSpace(request.ID)
+	if id == "" {
+		return PathOutcome{}, classified(ErrorUsage, errors.New("usage: ergo path <id>"))
+	}
+	repository, err := a.openView("")
+	if err != nil {
+		return PathOutcome{}, err
+	}
+	graph, err := repository.ViewGraph()
+	if err != nil {
+		return PathOutcome{}, classifyViewError(err)
+	}
+	if _, ok := graph.Tombstones[id]; ok {
+		return PathOutcome{}, classified(ErrorNotFound, prunedErr(id))
+	}
+	task := graph.Tasks[id]
+	if task == nil {
+		return PathOutcome{}, classified(ErrorNotFound, fmt.Errorf("unknown task id %s", id))
+	}
+	graph.prepareDerivedQueries()
+
+	var ends []string
+	if graph.IsEpic(id) {
+		ends = unfinishedLeaves(graph, id)
+	} else if !isFinishedState(task.State) {
+		ends = []string{id}
+	}
+	predecessors := map[string][]string{}
+	var visit func(string)
+	visit = func(taskID string) {
+		if _, seen := predecessors[taskID]; seen {
+			return
+		}
+		predecessors[taskID] = pathPredecessors(graph, taskID)
+		for _, predecessor := range predecessors[taskID] {
+			visit(predecessor)
+		}
+	}
+	for _, end := range ends {
+		visit(end)
+	}
+
+	outcome := PathOutcome{Graph: graph, Task: task}
+	for taskID := range predecessors {
+		outcome.Scope = append(outcome.Scope, graph.Tasks[taskID])
+	}
+	sort.Slice(outcome.Scope, func(i, j int) bool { return claimsBefore(outcome.Scope[i], outcome.Scope[j]) })
+
+	// depth is the number of tasks in the longest chain ending at a task;
+	// next points one step back along that chain.
+	// A cycle that runs through an epic's inherited dependencies is not
+	// refused when its edges are written, so measuring marks the tasks it is
+	// inside and stops at the first one it meets again.
+	depth := map[string]int{}
+	next := map[string]string{}
+	measuring := map[string]bool{}
+	cycle := ""
+	var measure func(string) int
+	measure = func(taskID string) int {
+		if value, done := depth[taskID]; done {
+			return value
+		}
+		if measuring[taskID] {
+			if cycle == "" {
+				cycle = taskID
+			}
+			return 0
+		}
+		measuring[taskID] = true
+		defer delete(measuring, taskID)
+		best := ""
+		for _, predecessor := range predecessors[taskID] {
+			length := measure(predecessor)
+			if best == "" || longerChain(graph, length, predecessor, depth[best], best) {
+				best = predecessor
+			}
+		}
+		depth[taskID] = 1
+		if best != "" {
+			depth[taskID] += depth[best]
+			next[taskID] = best
+		}
+		return depth[taskID]
+	}
+	end := ""
+	for _, candidate := range ends {
+		length := measure(candidate)
+		if end == "" || longerChain(graph, length, candidate, depth[end], end) {
+			end = candidate
+		}
+	}
+	if cycle != "" {
+		return PathOutcome{}, classified(ErrorConflict, fmt.Errorf("task %s waits on itself through an epic's dependencies; remove an edge with ergo unsequence", cycle))
+	}
+	onPath := map[string]string{}
+	for at := end; at != ""; at = next[at] {
+		outcome.Critical = append([]*Task{graph.Tasks[at]}, outcome.Critical...)
+		onPath[at] = next[at]
+	}
+
+	for _, dependent := range outcome.Scope {
+		for _, predecessor := range predecessors[dependent.ID] {
+			from, critical := onPath[dependent.ID]
+			outcome.Links = append(outcome.Links, PathLink{
+				FromID: predecessor, ToID: dependent.ID, Critical: critical && from == predecessor,
+			})
+		}
+		if graph.IsReady(dependent.ID) {
+			outcome.Ready = append(outcome.Ready, dependent)
+		}
+	}
+	return outcome, nil
+}
+
+// pathPredecessors returns the unfinished leaf tasks that id waits on.
+func pathPredecessors(graph *Graph, id string) []string {
+	task := graph.Tasks[id]
+	var predecessors []string
+	add := func(dependencies map[string]struct{}) {
+		for dependency := range dependencies {
+			if graph.IsEpic(dependency) {
+				for _, child := range unfinishedLeaves(graph, dependency) {
+					predecessors = appendUnique(predecessors, child)
+				}
+			} else if !graph.IsComplete(dependency) {
+				predecessors = appendUnique(predecessors, dependency)
+			}
+		}
+	}
+	add(graph.Deps[id])
+	if task != nil && task.EpicID != "" {
+		add(graph.Deps[task.EpicID])
+	}
+	sort.Strings(predecessors)
+	return predecessors
+}
+
+func unfinishedLeaves(graph *Graph, epicID string) []string {
+	var leaves []string
+	for _, child := range graph.Children(epicID) {
+		if !isFinishedState(child.State) {
+			leaves = append(leaves, child.ID)
+		}
+	}
+	return leaves
+}
+
+// longerChain orders chain ends: more tasks first, then the end an automatic
+// claim would reach first.
+func longerChain(graph *Graph, leftDepth int, left string, rightDepth int, right string) bool {
+	if leftDepth != rightDepth {
+		return leftDepth > rightDepth
+	}
+	return claimsBefore(graph.Tasks[left], graph.Tasks[right])
+}
@@ -0,0 +1,113 @@
+package ergo
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestPathFollowsInheritedEpicDependencies(t *testing.T) {
+	app := newTestApplication(t)
+	create := func(title, epicID string) string {
+		t.Helper()
+		task, err := app.CreateTask(CreateTaskRequest{Title: title, EpicID: epicID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return task.ID
+	}
+	sequence := func(ids ...string) {
+		t.Helper()
+		if _, err := app.Sequence(SequenceRequest{Command: "sequence", EventType: "link", IDs: ids}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	before := create("Before", "")
+	first := create("First", before)
+	second := create("Second", before)
+	epic := create("Epic", "")
+	build := create("Build", epic)
+	ship := create("Ship", epic)
+	side := create("Side", epic)
+	done := create("Done", epic)
+	sequence(first, second)
+	sequence(before, epic)
+	sequence(build, ship)
+	if _, err := app.Lifecycle(LifecycleRequest{Kind: "done", ID: done}); err != nil {
+		t.Fatal(err)
+	}
+
+	outcome, err := app.Path(PathRequest{ID: epic})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var chain []string
+	for _, task := range outcome.Critical {
+		chain = append(chain, task.ID)
+	}
+	if !equalStrings(chain, []string{first, second, build, ship}) {
+		t.Fatalf("critical path = %v", chain)
+	}
+	if len(outcome.Scope) != 5 || len(outcome.Ready) != 1 || outcome.Ready[0].ID != first {
+		t.Fatalf("scope = %d tasks, ready = %v", len(outcome.Scope), outcome.Ready)
+	}
+	critical := 0
+	for _, link := range outcome.Links {
+		if link.Critical {
+			critical++
+		}
+		if link.FromID == first && link.ToID == side && link.Critical {
+			t.Fatalf("inherited side link marked critical: %#v", link)
+		}
+	}
+	if critical != 3 {
+		t.Fatalf("critical links = %d in %#v", critical, outcome.Links)
+	}
+
+	var output bytes.Buffer
+	if err := RenderPathJSON(&output, outcome); err != nil {
+		t.Fatal(err)
+	}
+	for _, fact := range []string{`"version":1`, `"length":4`, `"width":1`, `"ready":["` + first + `"]`} {
+		if !strings.Contains(output.String(), fact) {
+			t.Fatalf("path JSON lacks %s: %s", fact, output.String())
+		}
+	}
+
+	finished, err := app.Path(PathRequest{ID: done})
+	if err != nil || len(finished.Critical) != 0 || len(finished.Scope) != 0 {
+		t.Fatalf("finished task path = %#v, %v", finished, err)
+	}
+	_, err = app.Path(PathRequest{ID: "ZZZZZZ"})
+	requireApplicationError(t, err, ErrorNotFound)
+}
+
+func TestPathRefusesCyclesThroughInheritedDependencies(t *testing.T) {
+	app := newTestApplication(t)
+	epic, err := app.CreateTask(CreateTaskRequest{Title: "Epic"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	child, err := app.CreateTask(CreateTaskRequest{Title: "Child", EpicID: epic.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	gate, err := app.CreateTask(CreateTaskRequest{Title: "Gate"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The child inherits the epic's dependency on the gate, which in turn
+	// waits on the child.
+	for _, ids := range [][]string{{gate.ID, epic.ID}, {child.ID, gate.ID}} {
+		if _, err := app.Sequence(SequenceRequest{Command: "sequence", EventType: "link", IDs: ids}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, id := range []string{epic.ID, child.ID, gate.ID} {
+		_, err := app.Path(PathRequest{ID: id})
+		requireApplicationError(t, err, ErrorConflict)
+		if !strings.Contains(err.Error(), "waits on itself") {
+			t.Fatalf("path %s err = %v", id, err)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package ergo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAutomaticClaimTakesMostUrgentPriorityFirst(t *testing.T) {
+	app := newTestApplication(t)
+	var ids []string
+	for _, request := range []CreateTaskRequest{
+		{Title: "Default"},
+		{Title: "Urgent", Priority: "p0"},
+		{Title: "Soon", Priority: "1"},
+		{Title: "Someday", Priority: "P3"},
+	} {
+		created, err := app.CreateTask(request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, created.ID)
+	}
+	_, err := app.CreateTask(CreateTaskRequest{Title: "Bad", Priority: "P9"})
+	requireApplicationError(t, err, ErrorUsage)
+
+	for _, want := range []string{ids[1], ids[2], ids[0], ids[3]} {
+		claimed, err := app.Claim(ClaimRequest{AgentID: "agent"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if claimed.NoReady || claimed.Task.ID != want {
+			t.Fatalf("claimed %s (%s), want %s", claimed.Task.ID, claimed.Task.Priority, want)
+		}
+	}
+}
+
+func TestApplicationSetPriority(t *testing.T) {
+	app := newTestApplication(t)
+	epic, err := app.CreateTask(CreateTaskRequest{Title: "Epic"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	child, err := app.CreateTask(CreateTaskRequest{Title: "Child", EpicID: epic.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unchanged, err := app.SetPriority(SetPriorityRequest{ID: child.ID, Priority: "P2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if unchanged.Changed {
+		t.Fatal("setting the default priority on an unprioritized task changed it")
+	}
+	changed, err := app.SetPriority(SetPriorityRequest{ID: child.ID, Priority: "p1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changed.Changed || changed.Priority != "P1" {
+		t.Fatalf("priority outcome = %#v", changed)
+	}
+	_, err = app.SetPriority(SetPriorityRequest{ID: epic.ID, Priority: "P0"})
+	requireApplicationError(t, err, ErrorConflict)
+	_, err = app.SetPriority(SetPriorityRequest{ID: child.ID, Priority: "urgent"})
+	requireApplicationError(t, err, ErrorUsage)
+
+	if _, err := app.Compact(); err != nil {
+		t.Fatal(err)
+	}
+	shown, err := app.Show(ShowRequest{ID: child.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shown.Task.Priority != "P1" {
+		t.Fatalf("compacted priority = %q", shown.Task.Priority)
+	}
+}
+
+func TestEpicFilePriorityLines(t *testing.T) {
+	app := newTestApplication(t)
+	path := filepath.Join(t.TempDir(), "tasks.md")
+	input := "# Configure\nPriority: P3\n\nConfigure it.\n\n---\n\n# Verify\nPriority: p0\n"
+	if err := os.WriteFile(path, []byte(input), 0644); err != nil {
+		t.Fatal(err)
+	}
+	out, err := app.CreateEpic(CreateEpicRequest{Title: "Prioritized", FilePath: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []struct{ priority, body string }{{"P3", "\nConfigure it."}, {"P0", ""}} {
+		shown, err := app.Show(ShowRequest{ID: out.Children[i].ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if shown.Task.Priority != want.priority || shown.Task.Body != want.body {
+			t.Fatalf("child %d = priority %q body %q", i, shown.Task.Priority, shown.Task.Body)
+		}
+	}
+
+	if err := os.WriteFile(path, []byte("# Twice\nPriority: P1\nPriority: P2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err = app.CreateEpic(CreateEpicRequest{Title: "Duplicate", FilePath: path})
+	requireApplicationError(t, err, ErrorUsage)
+}
@@ -0,0 +1,186 @@
+// Purpose: Define the full-text search use case over tasks and journal text.
+// Exports: SearchRequest, SearchOutcome, SearchHit, and Application.Search.
+// Role: Match one coherent locked view so results never mix backlog versions.
+// Invariants: pruned IDs never match, even while their journal text remains.
+// Invariants: title hits rank above body hits, and body hits above journal hits.
+package ergo
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Search fields in rank order.
+const (
+	searchFieldTitle   = "title"
+	searchFieldBody    = "body"
+	searchFieldJournal = "journal"
+)
+
+const maxSearchSnippetLen = 80
+
+type SearchRequest struct {
+	Query string
+	// Regex treats Query as a regular expression; matching is always
+	// case-insensitive.
+	Regex  bool
+	States []string
+	EpicID string
+}
+
+type SearchOutcome struct {
+	Query string
+	Graph *Graph
+	Hits  []SearchHit
+}
+
+// SearchHit is one matching task. Fields lists every matching field in rank
+// order; Snippet shows the first match outside the title, from SnippetField.
+type SearchHit struct {
+	Task         *Task
+	Fields       []string
+	Snippet      string
+	SnippetField string
+}
+
+func (a *Application) Search(request SearchRequest) (SearchOutcome, error) {
+	query := strings.TrimSpace(request.Query)
+	if query == "" {
+		return SearchOutcome{}, classified(ErrorUsage, errors.New("usage: ergo search <query> [--regex] [--state <state>] [--epic <id>]"))
+	}
+	pattern := regexp.QuoteMeta(query)
+	if request.Regex {
+		pattern = query
+	}
+	if _, err := regexp.Compile(pattern); err != nil {
+		return SearchOutcome{}, classified(ErrorUsage, fmt.Errorf("invalid --regex query: %w", err))
+	}
+	matcher := regexp.MustCompile("(?i)" + pattern)
+	if matcher.MatchString("") {
+		return SearchOutcome{}, classified(ErrorUsage, errors.New("search query must not match empty text"))
+	}
+	for _, state := range request.States {
+		if !isReadableState(state) && state != "active" && state != "empty" {
+			return SearchOutcome{}, classified(ErrorUsage, fmt.Errorf("invalid state: %s", state))
+		}
+	}
+	var repository Repository
+	if err := repository.Open(a.repository); err != nil {
+		return SearchOutcome{}, classifyRepositoryError(err)
+	}
+	graph, journal, err := repository.ViewWithJournal()
+	if err != nil {
+		return SearchOutcome{}, classifyRepositoryError(err)
+	}
+	graph.prepareDerivedQueries()
+	if request.EpicID != "" && !graph.IsEpic(request.EpicID) {
+		return SearchOutcome{}, classified(ErrorNotFound, fmt.Errorf("no such epic: %s", request.EpicID))
+	}
+
+	hits := map[string]*SearchHit{}
+	record := func(task *Task, field, text string) {
+		location := matcher.FindStringIndex(text)
+		if location == nil || !searchSelects(graph, task, request) {
+			return
+		}
+		hit := hits[task.ID]
+		if hit == nil {
+			hit = &SearchHit{Task: task}
+			hits[task.ID] = hit
+		}
+		if !containsString(hit.Fields, field) {
+			hit.Fields = append(hit.Fields, field)
+		}
+		if field != searchFieldTitle && hit.SnippetField == "" {
+			hit.Snippet, hit.SnippetField = searchSnippet(text, location), field
+		}
+	}
+	for _, task := range graph.Tasks {
+		record(task, searchFieldTitle, task.Title)
+		record(task, searchFieldBody, task.Body)
+	}
+	for _, entry := range journal {
+		if _, pruned := graph.Tombstones[entry.TaskID]; pruned {
+			continue
+		}
+		if task := graph.Tasks[entry.TaskID]; task != nil {
+			record(task, searchFieldJournal, entry.Text)
+		}
+	}
+
+	outcome := SearchOutcome{Query: query, Graph: graph, Hits: make([]SearchHit, 0, len(hits))}
+	for _, hit := range hits {
+		outcome.Hits = append(outcome.Hits, *hit)
+	}
+	sort.Slice(outcome.Hits, func(i, j int) bool {
+		left, right := outcome.Hits[i], outcome.Hits[j]
+		if rank, other := searchRank(left), searchRank(right); rank != other {
+			return rank < other
+		}
+		if !left.Task.UpdatedAt.Equal(right.Task.UpdatedAt) {
+			return left.Task.UpdatedAt.After(right.Task.UpdatedAt)
+		}
+		return left.Task.ID < right.Task.ID
+	})
+	return outcome, nil
+}
+
+func searchSelects(graph *Graph, task *Task, request SearchRequest) bool {
+	if request.EpicID != "" && task.ID != request.EpicID && task.EpicID != request.EpicID {
+		return false
+	}
+	if len(request.States) == 0 {
+		return true
+	}
+	return containsString(request.States, searchState(graph, task))
+}
+
+// searchState is the state a reader sees: epics report their derived state.
+func searchState(graph *Graph, task *Task) string {
+	if graph.IsEpic(task.ID) {
+		return graph.EpicState(task.ID)
+	}
+	return task.State
+}
+
+func searchRank(hit SearchHit) int {
+	for rank, field := range []string{searchFieldTitle, searchFieldBody, searchFieldJournal} {
+		if containsString(hit.Fields, field) {
+			return rank
+		}
+	}
+	return 3
+}
+
+// searchSnippet returns the line around a match, trimmed to a readable width.
+func searchSnippet(text string, location []int) string {
+	start := strings.LastIndexByte(text[:location[0]], '\n') + 1
+	end := len(text)
+	if newline := strings.IndexByte(text[location[0]:], '\n'); newline >= 0 {
+		end = location[0] + newline
+	}
+	line := text[start:end]
+	offset := location[0] - start
+	if utf8.RuneCountInString(line) <= maxSearchSnippetLen {
+		return strings.TrimSpace(line)
+	}
+	prefix, suffix := "", "…"
+	if offset > maxSearchSnippetLen/4 {
+		cut := offset - maxSearchSnippetLen/4
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		line, prefix = line[cut:], "…"
+	}
+	runes := []rune(line)
+	if len(runes) <= maxSearchSnippetLen {
+		suffix = ""
+	} else {
+		runes = runes[:maxSearchSnippetLen]
+	}
+	return prefix + strings.TrimSpace(string(runes)) + suffix
+}
@@ -0,0 +1,79 @@
+package ergo
+
+import (
+	"testing"
+)
+
+func TestApplicationSearchRanksTitleBodyThenJournal(t *testing.T) {
+	app := newTestApplication(t)
+	journaled, err := app.CreateTask(CreateTaskRequest{Title: "Deploy"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.Lifecycle(LifecycleRequest{Kind: "done", ID: journaled.ID, Messages: []string{"Ran the Migration script."}}); err != nil {
+		t.Fatal(err)
+	}
+	body, err := app.CreateTask(CreateTaskRequest{Title: "Schema", Body: "Intro\nWrite the MIGRATION first.\n"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	title, err := app.CreateTask(CreateTaskRequest{Title: "Plan migration", Body: "also a migration"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	found, err := app.Search(SearchRequest{Query: "migration"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found.Hits) != 3 {
+		t.Fatalf("hits = %#v", found.Hits)
+	}
+	for i, want := range []string{title.ID, body.ID, journaled.ID} {
+		if found.Hits[i].Task.ID != want {
+			t.Fatalf("hit %d = %s, want %s", i, found.Hits[i].Task.ID, want)
+		}
+	}
+	if got := found.Hits[0].Fields; len(got) != 2 || got[0] != "title" || got[1] != "body" {
+		t.Fatalf("title hit fields = %v", got)
+	}
+	if found.Hits[1].Snippet != "Write the MIGRATION first." || found.Hits[2].SnippetField != "journal" {
+		t.Fatalf("snippets = %#v, %#v", found.Hits[1], found.Hits[2])
+	}
+
+	done, err := app.Search(SearchRequest{Query: `migr\w+ script`, Regex: true, States: []string{stateDone}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(done.Hits) != 1 || done.Hits[0].Task.ID != journaled.ID {
+		t.Fatalf("filtered hits = %#v", done.Hits)
+	}
+
+	if _, err := app.Prune(PruneRequest{Confirm: true}); err != nil {
+		t.Fatal(err)
+	}
+	after, err := app.Search(SearchRequest{Query: "migration"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, hit := range after.Hits {
+		if hit.Task.ID == journaled.ID {
+			t.Fatal("pruned task still matched")
+		}
+	}
+}
+
+func TestApplicationSearchRejectsUnusableQueries(t *testing.T) {
+	app := newTestApplication(t)
+	for _, request := range []SearchRequest{
+		{Query: "  "},
+		{Query: "(", Regex: true},
+		{Query: "x*", Regex: true},
+		{Query: "x", States: []string{"finished"}},
+	} {
+		_, err := app.Search(request)
+		requireApplicationError(t, err, ErrorUsage)
+	}
+	_, err := app.Search(SearchRequest{Query: "x", EpicID: "NOPE00"})
+	requireApplicationError(t, err, ErrorNotFound)
+}
@@ -8,6 +8,8 @@ import (
 type SequenceRequest struct {
 	Command, EventType string
 	IDs                []string
+	// IfRevisions maps IDs in the chain to the revision each must still be at.
+	IfRevisions map[string]string
 }
 type SequenceOutcome struct {
 	EventType string
@@ -22,11 +24,14 @@ func (a *Application) Sequence(request SequenceRequest) (SequenceOutcome, error)
 	if len(request.IDs) < 2 {
 		return SequenceOutcome{}, classified(ErrorUsage, errors.New(usage))
 	}
+	if err := checkRevisionTargets(request.IDs, request.IfRevisions); err != nil {
+		return SequenceOutcome{}, classified(ErrorUsage, err)
+	}
 	dir, err := ergoDir(a.repository)
 	if err != nil {
 		return SequenceOutcome{}, classifyRepositoryError(err)
 	}
-	changed, err := writeLinkEvents(dir, a.repository, request.EventType, buildSequenceEdges(request.IDs))
+	changed, err := writeLinkEvents(dir, a.repository, request.EventType, buildSequenceEdges(request.IDs), request.IfRevisions)
 	if err != nil {
 		return SequenceOutcome{}, classifyRepositoryError(err)
 	}
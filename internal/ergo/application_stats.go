@@ -0,0 +1,206 @@
+// Purpose: Define the backlog statistics and throughput use case.
+// Exports: StatsRequest, StatsOutcome, StatsDay, StatsAgent, and Application.Stats.
+// Role: Combine current state counts with journal timestamps over a window.
+// Invariants: throughput comes only from journal entries, so it survives
+// compaction but not pruning; claim-to-done pairs a done entry with the latest
+// earlier claim of the same task, even one before the window.
+package ergo
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const defaultStatsWindow = 7 * 24 * time.Hour
+
+type StatsRequest struct {
+	// Since is an RFC 3339 timestamp or a duration ago; empty means seven days.
+	Since  string
+	EpicID string
+	// Agent keeps only that agent's journal entries and the tasks it claimed.
+	Agent string
+}
+
+type StatsOutcome struct {
+	Since, Until time.Time
+	EpicID       string
+	Agent        string
+	// Counts buckets the current state of every task in scope.
+	Counts                 taskStats
+	Done, Failed, Canceled int
+	MedianClaimToDone      time.Duration
+	MeasuredClaimToDone    int
+	Days                   []StatsDay
+	Agents                 []StatsAgent
+}
+
+// StatsDay counts closures recorded on one UTC date.
+type StatsDay struct {
+	Date                   string
+	Done, Failed, Canceled int
+}
+
+type StatsAgent struct {
+	Agent                string
+	Claims, Done, Failed int
+	MedianClaimToDone    time.Duration
+	MeasuredClaimToDone  int
+}
+
+// FailureRate and CancelRate are shares of all closures in the window.
+func (o StatsOutcome) FailureRate() float64 { return closureShare(o.Failed, o) }
+func (o StatsOutcome) CancelRate() float64  { return closureShare(o.Canceled, o) }
+
+func closureShare(count int, outcome StatsOutcome) float64 {
+	total := outcome.Done + outcome.Failed + outcome.Canceled
+	if total == 0 {
+		return 0
+	}
+	return float64(count) / float64(total)
+}
+
+func (a *Application) Stats(request StatsRequest) (StatsOutcome, error) {
+	now := time.Now().UTC()
+	outcome := StatsOutcome{Since: now.Add(-defaultStatsWindow), Until: now, EpicID: request.EpicID, Agent: strings.TrimSpace(request.Agent)}
+	if since := strings.TrimSpace(request.Since); since != "" {
+		if at, err := time.Parse(time.RFC3339Nano, since); err == nil {
+			outcome.Since = at.UTC()
+		} else if ago, ok := parseAgo(since); ok {
+			outcome.Since = now.Add(-ago)
+		} else {
+			return StatsOutcome{}, classified(ErrorUsage, fmt.Errorf("invalid --since %q: use an RFC 3339 timestamp or a positive duration ago such as 7d", since))
+		}
+	}
+	repository, err := a.openView("")
+	if err != nil {
+		return StatsOutcome{}, err
+	}
+	graph, journal, err := repository.ViewWithJournal()
+	if err != nil {
+		return StatsOutcome{}, classifyViewError(err)
+	}
+	graph.prepareDerivedQueries()
+
+	var tasks []*Task
+	if request.EpicID != "" {
+		if !graph.IsEpic(request.EpicID) {
+			return StatsOutcome{}, classified(ErrorNotFound, fmt.Errorf("no such epic: %s", request.EpicID))
+		}
+		tasks = graph.Children(request.EpicID)
+	} else {
+		tasks = collectNonContainerTasks(graph)
+	}
+	scope := make(map[string]bool, len(tasks))
+	for _, task := range tasks {
+		scope[task.ID] = true
+	}
+	if outcome.Agent != "" {
+		claimed := map[string]bool{}
+		for _, entry := range journal {
+			if entry.Kind == "claim" && entry.Agent == outcome.Agent {
+				claimed[entry.TaskID] = true
+			}
+		}
+		filtered := tasks[:0]
+		for _, task := range tasks {
+			if claimed[task.ID] {
+				filtered = append(filtered, task)
+			}
+		}
+		tasks = filtered
+	}
+	outcome.Counts = computeStatsForTasks(tasks, graph)
+
+	days := map[string]*StatsDay{}
+	agents := map[string]*StatsAgent{}
+	var claimToDone []time.Duration
+	agentClaimToDone := map[string][]time.Duration{}
+	lastClaim := map[string]time.Time{}
+	for _, entry := range journal {
+		if !scope[entry.TaskID] {
+			continue
+		}
+		at, err := parseTime(entry.At)
+		if err != nil {
+			continue
+		}
+		if entry.Kind == "claim" {
+			lastClaim[entry.TaskID] = at
+		}
+		if at.Before(outcome.Since) || (outcome.Agent != "" && entry.Agent != outcome.Agent) {
+			continue
+		}
+		var agent *StatsAgent
+		if entry.Agent != "" {
+			if agents[entry.Agent] == nil {
+				agents[entry.Agent] = &StatsAgent{Agent: entry.Agent}
+			}
+			agent = agents[entry.Agent]
+		}
+		date := at.UTC().Format(time.DateOnly)
+		day := days[date]
+		if day == nil && (entry.Kind == "done" || entry.Kind == "fail" || entry.Kind == "cancel") {
+			day = &StatsDay{Date: date}
+			days[date] = day
+		}
+		switch entry.Kind {
+		case "claim":
+			if agent != nil {
+				agent.Claims++
+			}
+		case "done":
+			outcome.Done++
+			day.Done++
+			if agent != nil {
+				agent.Done++
+			}
+			if claimedAt, ok := lastClaim[entry.TaskID]; ok && !at.Before(claimedAt) {
+				claimToDone = append(claimToDone, at.Sub(claimedAt))
+				if agent != nil {
+					agentClaimToDone[agent.Agent] = append(agentClaimToDone[agent.Agent], at.Sub(claimedAt))
+				}
+			}
+		case "fail":
+			outcome.Failed++
+			day.Failed++
+			if agent != nil {
+				agent.Failed++
+			}
+		case "cancel":
+			outcome.Canceled++
+			day.Canceled++
+		}
+	}
+	outcome.MedianClaimToDone = medianDuration(claimToDone)
+	outcome.MeasuredClaimToDone = len(claimToDone)
+	for _, date := range sortedKeys(days) {
+		outcome.Days = append(outcome.Days, *days[date])
+	}
+	for _, agent := range agents {
+		agent.MedianClaimToDone = medianDuration(agentClaimToDone[agent.Agent])
+		agent.MeasuredClaimToDone = len(agentClaimToDone[agent.Agent])
+		outcome.Agents = append(outcome.Agents, *agent)
+	}
+	sort.Slice(outcome.Agents, func(i, j int) bool {
+		if outcome.Agents[i].Done != outcome.Agents[j].Done {
+			return outcome.Agents[i].Done > outcome.Agents[j].Done
+		}
+		return outcome.Agents[i].Agent < outcome.Agents[j].Agent
+	})
+	return outcome, nil
+}
+
+func medianDuration(values []time.Duration) time.Duration {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration(nil), values...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return sorted[middle]
+	}
+	return (sorted[middle-1] + sorted[middle]) / 2
+}
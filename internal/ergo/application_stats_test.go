@@ -0,0 +1,80 @@
+package ergo
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStatsCombinesStateCountsWithJournalThroughput(t *testing.T) {
+	app := newTestApplication(t)
+	var ids []string
+	for _, title := range []string{"First", "Second", "Third", "Fourth"} {
+		task, err := app.CreateTask(CreateTaskRequest{Title: title})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, task.ID)
+	}
+	for _, step := range []struct{ id, agent, kind string }{
+		{ids[0], "alpha", "done"}, {ids[1], "alpha", "fail"}, {ids[2], "beta", "done"},
+	} {
+		if _, err := app.Claim(ClaimRequest{ID: step.id, AgentID: step.agent}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := app.Lifecycle(LifecycleRequest{Kind: step.kind, ID: step.id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := app.Lifecycle(LifecycleRequest{Kind: "cancel", ID: ids[3]}); err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := app.Stats(StatsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Counts.done != 2 || stats.Counts.failed != 1 || stats.Counts.canceled != 1 {
+		t.Fatalf("state counts = %+v", stats.Counts)
+	}
+	if stats.Done != 2 || stats.Failed != 1 || stats.Canceled != 1 || stats.FailureRate() != 0.25 {
+		t.Fatalf("closures = %d done, %d failed, %d canceled", stats.Done, stats.Failed, stats.Canceled)
+	}
+	if stats.MeasuredClaimToDone != 2 || len(stats.Days) != 1 || stats.Days[0].Done != 2 {
+		t.Fatalf("throughput = %d measured, days %+v", stats.MeasuredClaimToDone, stats.Days)
+	}
+	if len(stats.Agents) != 2 || stats.Agents[0].Agent != "alpha" || stats.Agents[0].Claims != 2 || stats.Agents[0].Failed != 1 {
+		t.Fatalf("agents = %+v", stats.Agents)
+	}
+
+	alpha, err := app.Stats(StatsRequest{Agent: "alpha"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if alpha.Counts.total != 2 || alpha.Done != 1 || alpha.Canceled != 0 || len(alpha.Agents) != 1 {
+		t.Fatalf("alpha stats = %+v", alpha)
+	}
+	later, err := app.Stats(StatsRequest{Since: time.Now().Add(time.Hour).Format(time.RFC3339)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if later.Done != 0 || later.Counts.done != 2 {
+		t.Fatalf("window after all activity = %+v", later)
+	}
+
+	var output bytes.Buffer
+	if err := RenderStatsJSON(&output, stats); err != nil {
+		t.Fatal(err)
+	}
+	for _, fact := range []string{`"version":1`, `"failure_rate":0.25`, `"cancel_rate":0.25`, `"agent":"beta","claims":1,"done":1`} {
+		if !strings.Contains(output.String(), fact) {
+			t.Fatalf("stats JSON lacks %s: %s", fact, output.String())
+		}
+	}
+
+	_, err = app.Stats(StatsRequest{Since: "soon"})
+	requireApplicationError(t, err, ErrorUsage)
+	_, err = app.Stats(StatsRequest{EpicID: ids[0]})
+	requireApplicationError(t, err, ErrorNotFound)
+}
@@ -1,5 +1,5 @@
 // Purpose: Define application requests and outcomes for focused task changes.
-// Exports: title, body, and move request/outcome types and Application methods.
+// Exports: title, body, priority, label, and move request/outcome types and Application methods.
 // Role: Validate public inputs and map them onto the shared locked mutation path.
 // Invariants: titles are nonblank; body bytes remain literal.
 // Invariants: body append is resolved against repository state under the lock.
@@ -10,7 +10,7 @@ import (
 	"strings"
 )
 
-type UpdateTitleRequest struct{ ID, Title string }
+type UpdateTitleRequest struct{ ID, Title, IfRevision string }
 type UpdateTitleOutcome struct {
 	ID, Title string
 	Changed   bool
@@ -26,7 +26,7 @@ func (a *Application) UpdateTitle(request UpdateTitleRequest) (UpdateTitleOutcom
 		return UpdateTitleOutcome{}, classifyRepositoryError(err)
 	}
 	outcome, err := applyTaskMutation(dir, a.repository, request.ID, taskMutation{
-		Kind: "title", Title: title, TitleSet: true,
+		Kind: "title", Title: title, TitleSet: true, ExpectRevision: request.IfRevision,
 	}, "")
 	if err != nil {
 		return UpdateTitleOutcome{}, classifyRepositoryError(err)
@@ -35,9 +35,10 @@ func (a *Application) UpdateTitle(request UpdateTitleRequest) (UpdateTitleOutcom
 }
 
 type UpdateBodyRequest struct {
-	ID     string
-	Body   []byte
-	Append bool
+	ID         string
+	Body       []byte
+	Append     bool
+	IfRevision string
 }
 type UpdateBodyOutcome struct {
 	ID      string
@@ -52,6 +53,7 @@ func (a *Application) UpdateBody(request UpdateBodyRequest) (UpdateBodyOutcome,
 	}
 	outcome, err := applyTaskMutation(dir, a.repository, request.ID, taskMutation{
 		Kind: "body", Body: string(request.Body), BodySet: true, BodyAppend: request.Append,
+		ExpectRevision: request.IfRevision,
 	}, "")
 	if err != nil {
 		return UpdateBodyOutcome{}, classifyRepositoryError(err)
@@ -62,9 +64,74 @@ func (a *Application) UpdateBody(request UpdateBodyRequest) (UpdateBodyOutcome,
 	}, nil
 }
 
+type SetPriorityRequest struct{ ID, Priority, IfRevision string }
+type SetPriorityOutcome struct {
+	ID, Priority string
+	Changed      bool
+}
+
+func (a *Application) SetPriority(request SetPriorityRequest) (SetPriorityOutcome, error) {
+	priority, err := normalizePriority(request.Priority)
+	if err != nil {
+		return SetPriorityOutcome{}, classified(ErrorUsage, err)
+	}
+	dir, err := ergoDir(a.repository)
+	if err != nil {
+		return SetPriorityOutcome{}, classifyRepositoryError(err)
+	}
+	outcome, err := applyTaskMutation(dir, a.repository, request.ID, taskMutation{
+		Kind: "priority", Priority: priority, PrioritySet: true, ExpectRevision: request.IfRevision,
+	}, "")
+	if err != nil {
+		return SetPriorityOutcome{}, classifyRepositoryError(err)
+	}
+	return SetPriorityOutcome{ID: request.ID, Priority: priority, Changed: len(outcome.ChangedFields) > 0}, nil
+}
+
+// LabelRequest adds Labels to a task, or removes them when Remove is set.
+type LabelRequest struct {
+	ID         string
+	Labels     []string
+	Remove     bool
+	IfRevision string
+}
+type LabelOutcome struct {
+	ID      string
+	Labels  []string
+	Remove  bool
+	Changed bool
+}
+
+func (a *Application) Label(request LabelRequest) (LabelOutcome, error) {
+	if len(request.Labels) == 0 {
+		return LabelOutcome{}, classified(ErrorUsage, errors.New("usage: ergo label add|remove <id> <label>..."))
+	}
+	labels, err := normalizeLabels(request.Labels)
+	if err != nil {
+		return LabelOutcome{}, classified(ErrorUsage, err)
+	}
+	dir, err := ergoDir(a.repository)
+	if err != nil {
+		return LabelOutcome{}, classifyRepositoryError(err)
+	}
+	mutation := taskMutation{Kind: "label", AddLabels: labels, ExpectRevision: request.IfRevision}
+	if request.Remove {
+		mutation = taskMutation{Kind: "label", RemoveLabels: labels, ExpectRevision: request.IfRevision}
+	}
+	outcome, err := applyTaskMutation(dir, a.repository, request.ID, mutation, "")
+	if err != nil {
+		return LabelOutcome{}, classifyRepositoryError(err)
+	}
+	return LabelOutcome{
+		ID: request.ID, Labels: outcome.Graph.Tasks[request.ID].Labels, Remove: request.Remove,
+		Changed: len(outcome.ChangedFields) > 0,
+	}, nil
+}
+
 type MoveRequest struct {
 	ID, DestinationID string
 	ToRoot            bool
+	IfRevision        string
 }
 type MoveOutcome struct {
 	ID, DestinationID string
@@ -84,6 +151,7 @@ func (a *Application) Move(request MoveRequest) (MoveOutcome, error) {
 	}
 	outcome, err := applyTaskMutation(dir, a.repository, request.ID, taskMutation{
 		Kind: "move", EpicID: request.DestinationID, EpicSet: true, ValidateMove: true,
+		ExpectRevision: request.IfRevision,
 	}, "")
 	if err != nil {
 		return MoveOutcome{}, classifyRepositoryError(err)
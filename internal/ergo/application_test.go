@@ -30,7 +30,7 @@ func TestApplicationCreateAndShow(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	shown, err := app.Show(ShowRequest(created))
+	shown, err := app.Show(ShowRequest{ID: created.ID})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -49,7 +49,7 @@ func TestApplicationDraftCreationAndPromotion(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	shown, err := app.Show(ShowRequest(child))
+	shown, err := app.Show(ShowRequest{ID: child.ID})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -88,6 +88,60 @@ func TestApplicationBulkCreateDraftChildren(t *testing.T) {
 	}
 }
 
+func TestApplicationBulkCreateAfterLines(t *testing.T) {
+	app := newTestApplication(t)
+	existing, err := app.CreateTask(CreateTaskRequest{Title: "Existing"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "tasks.md")
+	input := "# Schema, v2\nAfter: " + existing.ID + "\n---\n# Endpoints\nAfter: Schema, v2\nBody\n---\n# Tests\nAfter: Endpoints, " + existing.ID + "\nPriority: P1\n"
+	if err := os.WriteFile(path, []byte(input), 0644); err != nil {
+		t.Fatal(err)
+	}
+	out, err := app.CreateEpic(CreateEpicRequest{Title: "Sequenced", FilePath: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema, endpoints, tests := out.Children[0].ID, out.Children[1].ID, out.Children[2].ID
+	want := []sequenceEdge{{schema, existing.ID}, {endpoints, schema}, {tests, endpoints}, {tests, existing.ID}}
+	if len(out.Edges) != len(want) {
+		t.Fatalf("edges = %v, want %v", out.Edges, want)
+	}
+	for i := range want {
+		if out.Edges[i] != want[i] {
+			t.Fatalf("edges = %v, want %v", out.Edges, want)
+		}
+	}
+	shown, err := app.Show(ShowRequest{ID: endpoints})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shown.Task.Body != "Body" {
+		t.Fatalf("body after After line = %q", shown.Task.Body)
+	}
+
+	for _, bad := range []string{
+		"# Alone\nAfter: Missing\n",
+		"# Alone\nAfter: Alone\n",
+		"# First\nAfter: Second\n---\n# Second\nAfter: First\n",
+		"# Empty\nAfter: ,\n",
+	} {
+		if err := os.WriteFile(path, []byte(bad), 0644); err != nil {
+			t.Fatal(err)
+		}
+		_, err := app.CreateEpic(CreateEpicRequest{Title: "Rejected", FilePath: path})
+		requireApplicationError(t, err, ErrorUsage)
+	}
+	listed, err := app.List(ListRequest{ShowAll: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count := len(listed.Graph.Tasks); count != 5 {
+		t.Fatalf("rejected files wrote tasks: %d tasks", count)
+	}
+}
+
 func TestApplicationLifecycleReturnsOutcomeWithoutRendering(t *testing.T) {
 	app := newTestApplication(t)
 	created, err := app.CreateTask(CreateTaskRequest{Title: "Finish this"})
@@ -121,8 +175,8 @@ func TestApplicationClaimIdentityIsRequestScoped(t *testing.T) {
 	if _, err := app.Claim(ClaimRequest{ID: second.ID, AgentID: "two@host"}); err != nil {
 		t.Fatal(err)
 	}
-	firstShown, _ := app.Show(ShowRequest(first))
-	secondShown, _ := app.Show(ShowRequest(second))
+	firstShown, _ := app.Show(ShowRequest{ID: first.ID})
+	secondShown, _ := app.Show(ShowRequest{ID: second.ID})
 	if firstShown.Task.ClaimedBy != "one@host" || secondShown.Task.ClaimedBy != "two@host" {
 		t.Fatalf("claims = %q, %q", firstShown.Task.ClaimedBy, secondShown.Task.ClaimedBy)
 	}
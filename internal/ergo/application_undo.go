@@ -0,0 +1,330 @@
+// Purpose: Define the undo use case that reverts one backlog transaction.
+// Exports: UndoRequest, UndoOutcome, and Application.Undo.
+// Role: Append the inverse of a recorded transaction through UpdateWithJournal.
+// Invariants: undo appends and never rewrites history, so an undo of a change
+// can itself be undone; a transaction is reverted only when no later one
+// touched its tasks.
+// Notes: transactions before the last compaction and journal-only commands
+// such as result are not undoable. A created task is undone by a tombstone,
+// which drops its journal as prune does, so undoing a creation is final.
+package ergo
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+type UndoRequest struct {
+	// ID selects the latest transaction that touched this task; empty selects
+	// the latest transaction.
+	ID      string
+	AgentID string
+}
+
+type UndoOutcome struct {
+	At    string
+	Kinds []string
+	// Restored maps each reverted task to the fields undo restored.
+	Restored map[string][]string
+	Removed  []string
+}
+
+// undoTransaction is one physical backlog record and the task IDs it touched.
+type undoTransaction struct {
+	events  []Event
+	touched map[string]struct{}
+}
+
+func (a *Application) Undo(request UndoRequest) (UndoOutcome, error) {
+	id := strings.TrimSpace(request.ID)
+	agentID := strings.TrimSpace(request.AgentID)
+	var repository Repository
+	if err := repository.Open(a.repository); err != nil {
+		return UndoOutcome{}, classifyRepositoryError(err)
+	}
+	var outcome UndoOutcome
+	_, err := repository.updateDroppingJournal(func(graph *Graph) ([]Event, []JournalEntry, []string, error) {
+		read, err := repository.io.inspectEvents(repository.eventsPath)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		transactions, err := groupUndoTransactions(read.events)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		target, err := selectUndoTransaction(transactions, graph, id)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		before := read.snapshot
+		if before == nil {
+			before = newGraph()
+		}
+		var prefix []Event
+		for _, transaction := range transactions[:target] {
+			prefix = append(prefix, transaction.events...)
+		}
+		if before, err = replayEventsOnto(before, prefix); err != nil {
+			return nil, nil, nil, err
+		}
+		undone := transactions[target]
+		after, err := applyTransaction(before, undone.events)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		now := time.Now().UTC()
+		events, restored, removed, err := inverseTransaction(before, after, now)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if len(events) == 0 {
+			return nil, nil, nil, classified(ErrorConflict, errors.New("nothing to undo: the transaction left no reversible change"))
+		}
+		outcome = UndoOutcome{At: undone.events[0].TS, Restored: restored, Removed: removed}
+		for _, event := range undone.events {
+			if !containsString(outcome.Kinds, event.Type) {
+				outcome.Kinds = append(outcome.Kinds, event.Type)
+			}
+		}
+		// Removed tasks keep no journal, so the note of their removal goes on
+		// every surviving task the transaction touched, such as their epic.
+		var journal []JournalEntry
+		for _, taskID := range sortedKeys(undone.touched) {
+			if after.Tasks[taskID] == nil || slices.Contains(removed, taskID) {
+				continue
+			}
+			var reverted []string
+			if fields := restored[taskID]; len(fields) > 0 {
+				reverted = append(reverted, "restored "+strings.Join(fields, ", "))
+			}
+			if len(removed) > 0 {
+				reverted = append(reverted, "removed "+strings.Join(removed, ", "))
+			}
+			if len(reverted) > 0 {
+				journal = append(journal, newJournalEntry(taskID, "undo", agentID, "undo "+strings.Join(reverted, "; "), now))
+			}
+		}
+		return events, journal, removed, nil
+	})
+	return outcome, classifyRepositoryError(err)
+}
+
+// groupUndoTransactions splits replayed events back into their physical
+// records. Released standalone events count as one-event transactions.
+func groupUndoTransactions(events []Event) ([]undoTransaction, error) {
+	var transactions []undoTransaction
+	line := -1
+	for index, event := range events {
+		if event.Source.Line != line || len(transactions) == 0 {
+			transactions = append(transactions, undoTransaction{touched: map[string]struct{}{}})
+			line = event.Source.Line
+		}
+		transaction := &transactions[len(transactions)-1]
+		transaction.events = append(transaction.events, event)
+		decoded, err := decodeEvent(event, index)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range eventTaskIDs(decoded.payload) {
+			if id != "" {
+				transaction.touched[id] = struct{}{}
+			}
+		}
+	}
+	return transactions, nil
+}
+
+func eventTaskIDs(payload any) []string {
+	switch data := payload.(type) {
+	case NewTaskEvent:
+		return []string{data.ID, data.EpicID}
+	case StateEvent:
+		return []string{data.ID}
+	case ClaimEvent:
+		return []string{data.ID}
+	case UnclaimEvent:
+		return []string{data.ID}
+	case LeaseEvent:
+		return []string{data.ID}
+	case LinkEvent:
+		return []string{data.FromID, data.ToID}
+	case TitleUpdateEvent:
+		return []string{data.ID}
+	case BodyUpdateEvent:
+		return []string{data.ID}
+	case EpicAssignEvent:
+		return []string{data.ID, data.EpicID}
+	case TombstoneEvent:
+		return []string{data.ID}
+	case ResultEvent:
+		return []string{data.TaskID}
+	case MessageEvent:
+		return []string{data.TaskID}
+	case PriorityEvent:
+		return []string{data.ID}
+	case LabelEvent:
+		return []string{data.ID}
+	default:
+		return nil
+	}
+}
+
+// selectUndoTransaction returns the index of the transaction to revert and
+// refuses when a later transaction touched any of its tasks.
+func selectUndoTransaction(transactions []undoTransaction, graph *Graph, id string) (int, error) {
+	if len(transactions) == 0 {
+		return 0, classified(ErrorConflict, errors.New("nothing to undo: no transactions since the last compaction"))
+	}
+	target := len(transactions) - 1
+	if id != "" {
+		if _, pruned := graph.Tombstones[id]; !pruned && graph.Tasks[id] == nil {
+			return 0, classified(ErrorNotFound, fmt.Errorf("unknown task id %s", id))
+		}
+		for target >= 0 {
+			if _, touched := transactions[target].touched[id]; touched {
+				break
+			}
+			target--
+		}
+		if target < 0 {
+			return 0, classified(ErrorConflict, fmt.Errorf("nothing to undo: no transaction since the last compaction changed %s", id))
+		}
+	}
+	for _, later := range transactions[target+1:] {
+		for _, touched := range sortedKeys(transactions[target].touched) {
+			if _, conflict := later.touched[touched]; conflict {
+				return 0, classified(ErrorConflict, fmt.Errorf("a later transaction also changed %s; undo it first", touched))
+			}
+		}
+	}
+	return target, nil
+}
+
+// inverseTransaction builds the events that return after's tasks to before.
+// Tasks the transaction created are tombstoned; tasks it pruned cannot return.
+func inverseTransaction(before, after *Graph, now time.Time) ([]Event, map[string][]string, []string, error) {
+	ts := formatTime(now)
+	var events []Event
+	add := func(kind string, payload any) error {
+		event, err := newEvent(kind, now, payload)
+		if err == nil {
+			events = append(events, event)
+		}
+		return err
+	}
+	restored := map[string][]string{}
+	var removed []string
+	for _, id := range sortedKeys(before.Tasks) {
+		if after.Tasks[id] == nil {
+			return nil, nil, nil, classified(ErrorConflict, fmt.Errorf("cannot undo: the transaction pruned %s, and pruned tasks cannot be restored", id))
+		}
+	}
+	for _, id := range sortedKeys(after.Tasks) {
+		was, is := before.Tasks[id], after.Tasks[id]
+		if was == nil {
+			removed = append(removed, id)
+			continue
+		}
+		var fields []string
+		var err error
+		if was.EpicID != is.EpicID {
+			fields = append(fields, "epic")
+			err = errors.Join(err, add(eventEpic, EpicAssignEvent{ID: id, EpicID: was.EpicID, TS: ts}))
+		}
+		if was.Title != is.Title {
+			fields = append(fields, "title")
+			err = errors.Join(err, add(eventTitle, TitleUpdateEvent{ID: id, Title: was.Title, TS: ts}))
+		}
+		if was.Body != is.Body {
+			fields = append(fields, "body")
+			err = errors.Join(err, add(eventBody, BodyUpdateEvent{ID: id, Body: was.Body, TS: ts}))
+		}
+		if effectivePriority(was) != effectivePriority(is) {
+			fields = append(fields, "priority")
+			err = errors.Join(err, add(eventPriority, PriorityEvent{ID: id, Priority: effectivePriority(was), TS: ts}))
+		}
+		if !slices.Equal(was.Labels, is.Labels) {
+			fields = append(fields, "labels")
+			for _, label := range was.Labels {
+				if !is.hasLabel(label) {
+					err = errors.Join(err, add(eventLabel, LabelEvent{ID: id, Label: label, TS: ts}))
+				}
+			}
+			for _, label := range is.Labels {
+				if !was.hasLabel(label) {
+					err = errors.Join(err, add(eventUnlabel, LabelEvent{ID: id, Label: label, TS: ts}))
+				}
+			}
+		}
+		if was.State != is.State {
+			fields = append(fields, "state")
+			err = errors.Join(err, add(eventState, StateEvent{ID: id, NewState: was.State, TS: ts}))
+		}
+		claimChanged := was.ClaimedBy != is.ClaimedBy || !was.ClaimedAt.Equal(is.ClaimedAt)
+		leaseChanged := was.Lease != is.Lease || !was.LeaseExpiresAt.Equal(is.LeaseExpiresAt)
+		if claimChanged {
+			fields = append(fields, "claim")
+		} else if leaseChanged {
+			fields = append(fields, "lease")
+		}
+		switch {
+		case !claimChanged && !leaseChanged:
+		case was.ClaimedBy != "":
+			// A claim clears any lease, so a prior lease is granted again.
+			err = errors.Join(err, add(eventClaim, ClaimEvent{ID: id, AgentID: was.ClaimedBy, TS: formatTime(was.ClaimedAt)}))
+			if !was.LeaseExpiresAt.IsZero() {
+				err = errors.Join(err, add(eventLease, LeaseEvent{
+					ID: id, AgentID: was.ClaimedBy,
+					ExpiresAt: formatTime(was.LeaseExpiresAt), TS: formatTime(was.LeaseExpiresAt.Add(-was.Lease)),
+				}))
+			}
+		default:
+			err = errors.Join(err, add(eventUnclaim, UnclaimEvent{ID: id, TS: ts}))
+		}
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if len(fields) > 0 {
+			restored[id] = fields
+		}
+	}
+
+	for _, from := range sortedKeys(after.Deps) {
+		for _, to := range sortedKeys(after.Deps[from]) {
+			if _, existed := before.Deps[from][to]; existed || before.Tasks[from] == nil || before.Tasks[to] == nil {
+				continue
+			}
+			restored[from] = appendUnique(restored[from], "dependencies")
+			if err := add(eventUnlink, LinkEvent{FromID: from, ToID: to, Type: dependsLinkType}); err != nil {
+				return nil, nil, nil, err
+			}
+		}
+	}
+	for _, from := range sortedKeys(before.Deps) {
+		for _, to := range sortedKeys(before.Deps[from]) {
+			if _, kept := after.Deps[from][to]; kept {
+				continue
+			}
+			restored[from] = appendUnique(restored[from], "dependencies")
+			if err := add(eventLink, LinkEvent{FromID: from, ToID: to, Type: dependsLinkType}); err != nil {
+				return nil, nil, nil, err
+			}
+		}
+	}
+	for _, id := range removed {
+		if err := add(eventTombstone, TombstoneEvent{ID: id, TS: ts}); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	return events, restored, removed, nil
+}
+
+func appendUnique(values []string, value string) []string {
+	if containsString(values, value) {
+		return values
+	}
+	return append(values, value)
+}
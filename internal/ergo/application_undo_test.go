@@ -0,0 +1,168 @@
+package ergo
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUndoRevertsTransactionsAndRecordsJournal(t *testing.T) {
+	app := newTestApplication(t)
+	first, err := app.CreateTask(CreateTaskRequest{Title: "First"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := app.CreateTask(CreateTaskRequest{Title: "Second"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.Claim(ClaimRequest{ID: first.ID, AgentID: "worker"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.Lifecycle(LifecycleRequest{Kind: "done", ID: first.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	undone, err := app.Undo(UndoRequest{AgentID: "reviewer"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !containsString(undone.Kinds, eventState) || !equalStrings(undone.Restored[first.ID], []string{"state", "claim"}) {
+		t.Fatalf("done undo outcome = %#v", undone)
+	}
+	shown, err := app.Show(ShowRequest{ID: first.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shown.Task.State != stateDoing || shown.Task.ClaimedBy != "worker" {
+		t.Fatalf("restored task = %s claimed by %q", shown.Task.State, shown.Task.ClaimedBy)
+	}
+	last := shown.Journal[len(shown.Journal)-1]
+	if last.Kind != "undo" || last.Agent != "reviewer" || last.Text != "undo restored state, claim" {
+		t.Fatalf("undo journal entry = %#v", last)
+	}
+
+	// Undoing the undo restores the reverted change.
+	if _, err := app.Undo(UndoRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	redone, err := app.Show(ShowRequest{ID: first.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if redone.Task.State != stateDone || redone.Task.ClaimedBy != "" {
+		t.Fatalf("redone task = %s claimed by %q", redone.Task.State, redone.Task.ClaimedBy)
+	}
+
+	if _, err := app.Sequence(SequenceRequest{Command: "sequence", EventType: "link", IDs: []string{first.ID, second.ID}}); err != nil {
+		t.Fatal(err)
+	}
+	undone, err = app.Undo(UndoRequest{ID: second.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !equalStrings(undone.Restored[second.ID], []string{"dependencies"}) {
+		t.Fatalf("sequence undo outcome = %#v", undone)
+	}
+	unlinked, err := app.Show(ShowRequest{ID: second.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(unlinked.Graph.Deps[second.ID]) != 0 {
+		t.Fatalf("restored dependencies = %v", unlinked.Graph.Deps)
+	}
+}
+
+func TestUndoTombstonesCreatedTasks(t *testing.T) {
+	app := newTestApplication(t)
+	created, err := app.CreateTask(CreateTaskRequest{Title: "Mistake"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	undone, err := app.Undo(UndoRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !equalStrings(undone.Removed, []string{created.ID}) {
+		t.Fatalf("undo outcome = %#v", undone)
+	}
+	_, err = app.Show(ShowRequest{ID: created.ID})
+	requireApplicationError(t, err, ErrorNotFound)
+
+	// The removed task's journal goes with it; its epic records the undo.
+	epic, err := app.CreateTask(CreateTaskRequest{Title: "Epic"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	child, err := app.CreateTask(CreateTaskRequest{Title: "Stray", EpicID: epic.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.Undo(UndoRequest{ID: child.ID, AgentID: "reviewer"}); err != nil {
+		t.Fatal(err)
+	}
+	shown, err := app.Show(ShowRequest{ID: epic.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := shown.Journal[len(shown.Journal)-1]
+	if last.Kind != "undo" || last.Agent != "reviewer" || last.Text != "undo removed "+child.ID {
+		t.Fatalf("epic journal after undoing its child = %#v", shown.Journal)
+	}
+	report, err := app.Fsck(FsckRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Problems) != 0 {
+		t.Fatalf("fsck after undoing creations = %#v", report.Problems)
+	}
+	// Undoing a creation is final.
+	_, err = app.Undo(UndoRequest{})
+	requireApplicationError(t, err, ErrorConflict)
+}
+
+func TestUndoRefusesWhenLaterTransactionsDependOnIt(t *testing.T) {
+	app := newTestApplication(t)
+	_, err := app.Undo(UndoRequest{})
+	requireApplicationError(t, err, ErrorConflict)
+
+	task, err := app.CreateTask(CreateTaskRequest{Title: "Draft title"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.UpdateTitle(UpdateTitleRequest{ID: task.ID, Title: "Final title"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.CreateTask(CreateTaskRequest{Title: "Unrelated"}); err != nil {
+		t.Fatal(err)
+	}
+	_, err = app.Undo(UndoRequest{ID: "ZZZZZZ"})
+	requireApplicationError(t, err, ErrorNotFound)
+
+	if _, err := app.Undo(UndoRequest{ID: task.ID}); err != nil {
+		t.Fatal(err)
+	}
+	shown, err := app.Show(ShowRequest{ID: task.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shown.Task.Title != "Draft title" {
+		t.Fatalf("restored title = %q", shown.Task.Title)
+	}
+
+	other, err := app.CreateTask(CreateTaskRequest{Title: "Other"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.Sequence(SequenceRequest{Command: "sequence", EventType: "link", IDs: []string{task.ID, other.ID}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.UpdateTitle(UpdateTitleRequest{ID: other.ID, Title: "Other, retitled"}); err != nil {
+		t.Fatal(err)
+	}
+	// The sequence is task's latest transaction, but the retitle built on other.
+	_, err = app.Undo(UndoRequest{ID: task.ID})
+	requireApplicationError(t, err, ErrorConflict)
+	if !strings.Contains(err.Error(), "a later transaction also changed "+other.ID) {
+		t.Fatalf("conflict = %v", err)
+	}
+}
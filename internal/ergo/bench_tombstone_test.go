@@ -125,7 +125,7 @@ func BenchmarkPrunePlanSelection10000(b *testing.B) {
 	graph := buildPruneGraph(10000, 50)
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_ = buildPrunePlan(graph)
+		_ = buildPrunePlan(graph, labelFilter{})
 	}
 }
 
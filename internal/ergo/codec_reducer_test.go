@@ -90,6 +90,21 @@ func TestEverySupportedEventKindDecodesAndReduces(t *testing.T) {
 		{eventTombstone, []Event{create("T1")}, []Event{mustNewEvent(eventTombstone, now, TombstoneEvent{ID: "T1", TS: formatTime(now)})}},
 		{eventResult, []Event{create("T1")}, []Event{mustNewEvent(eventResult, now, ResultEvent{TaskID: "T1", Summary: "result", Path: "result.txt", TS: formatTime(now)})}},
 		{eventMessage, []Event{create("T1")}, []Event{mustNewEvent(eventMessage, now, MessageEvent{TaskID: "T1", Kind: "done", Text: "note", TS: formatTime(now)})}},
+		{eventLease, []Event{
+			create("T1"),
+			mustNewEvent(eventClaim, now, ClaimEvent{ID: "T1", AgentID: "agent", TS: formatTime(now)}),
+			mustNewEvent(eventState, now, StateEvent{ID: "T1", NewState: stateDoing, TS: formatTime(now)}),
+		}, []Event{mustNewEvent(eventLease, now, LeaseEvent{ID: "T1", AgentID: "agent", ExpiresAt: formatTime(now.Add(time.Hour)), TS: formatTime(now)})}},
+		{eventHeartbeat, []Event{
+			create("T1"),
+			mustNewEvent(eventClaim, now, ClaimEvent{ID: "T1", AgentID: "agent", TS: formatTime(now)}),
+			mustNewEvent(eventState, now, StateEvent{ID: "T1", NewState: stateDoing, TS: formatTime(now)}),
+			mustNewEvent(eventLease, now, LeaseEvent{ID: "T1", AgentID: "agent", ExpiresAt: formatTime(now.Add(time.Hour)), TS: formatTime(now)}),
+		}, []Event{mustNewEvent(eventHeartbeat, now, LeaseEvent{ID: "T1", AgentID: "agent", ExpiresAt: formatTime(now.Add(2 * time.Hour)), TS: formatTime(now)})}},
+		{eventPriority, []Event{create("T1")}, []Event{mustNewEvent(eventPriority, now, PriorityEvent{ID: "T1", Priority: "P1", TS: formatTime(now)})}},
+		{eventLabel, []Event{create("T1")}, []Event{mustNewEvent(eventLabel, now, LabelEvent{ID: "T1", Label: "backend", TS: formatTime(now)})}},
+		{eventUnlabel, []Event{create("T1"), mustNewEvent(eventLabel, now, LabelEvent{ID: "T1", Label: "backend", TS: formatTime(now)})},
+			[]Event{mustNewEvent(eventUnlabel, now, LabelEvent{ID: "T1", Label: "backend", TS: formatTime(now)})}},
 	}
 	if len(tests) != len(supportedEventKinds) {
 		t.Fatalf("reducer fixtures=%d supported kinds=%d", len(tests), len(supportedEventKinds))
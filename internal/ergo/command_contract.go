@@ -1,6 +1,6 @@
 package ergo
 
 const (
-	NewTaskUsage = `usage: ergo new task "<title>" [--epic <id>] [--draft]; optional piped stdin becomes the body`
+	NewTaskUsage = `usage: ergo new task "<title>" [--epic <id>] [--draft] [--priority <P0-P3>] [--label <label>]...; optional piped stdin becomes the body`
 	NewEpicUsage = `usage: ergo new epic "<title>" --file <path> [--draft]; optional piped stdin becomes the epic body`
 )
@@ -0,0 +1,19 @@
+// Purpose: Render batch receipts.
+// Role: Presentation only; validation and writing belong to Application.Batch.
+package ergo
+
+import (
+	"fmt"
+	"io"
+)
+
+func RenderBatch(w io.Writer, outcome BatchOutcome) {
+	for _, created := range outcome.Created {
+		if created.Handle != "" {
+			fmt.Fprintf(w, "$%s = %s - %s\n", created.Handle, created.ID, created.Title)
+			continue
+		}
+		fmt.Fprintf(w, "%s - %s\n", created.ID, created.Title)
+	}
+	fmt.Fprintf(w, "%s applied, %d created\n", pluralize(outcome.Operations, "operation", "operations"), len(outcome.Created))
+}
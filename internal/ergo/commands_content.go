@@ -8,6 +8,7 @@ package ergo
 import (
 	"fmt"
 	"io"
+	"strings"
 )
 
 func RunTitle(id, title string, opts GlobalOptions, render RenderOptions) error {
@@ -36,6 +37,26 @@ func RenderTitle(w io.Writer, outcome UpdateTitleOutcome) {
 	fmt.Fprintf(w, "%s - %s\n", outcome.ID, outcome.Title)
 }
 
+func RenderPriority(w io.Writer, outcome SetPriorityOutcome) {
+	if !outcome.Changed {
+		fmt.Fprintf(w, "%s priority unchanged: %s\n", outcome.ID, outcome.Priority)
+		return
+	}
+	fmt.Fprintf(w, "%s priority: %s\n", outcome.ID, outcome.Priority)
+}
+
+func RenderLabels(w io.Writer, outcome LabelOutcome) {
+	labels := "(none)"
+	if len(outcome.Labels) > 0 {
+		labels = strings.Join(outcome.Labels, ", ")
+	}
+	if !outcome.Changed {
+		fmt.Fprintf(w, "%s labels unchanged: %s\n", outcome.ID, labels)
+		return
+	}
+	fmt.Fprintf(w, "%s labels: %s\n", outcome.ID, labels)
+}
+
 func RenderBody(w io.Writer, outcome UpdateBodyOutcome) {
 	if !outcome.Changed {
 		fmt.Fprintf(w, "%s body unchanged\n", outcome.ID)
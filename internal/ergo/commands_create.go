@@ -37,6 +37,19 @@ func RenderInitialize(w io.Writer, outcome InitializeResult) {
 	default:
 		fmt.Fprintf(w, "Ergo already initialized at %s\n", outcome.Path)
 	}
+	if outcome.GitAttributes == "" {
+		return
+	}
+	fmt.Fprintf(w, "Merge driver attributes in %s\n", outcome.GitAttributes)
+	if outcome.GitConfigured {
+		fmt.Fprintf(w, "Configured git merge driver %q\n", gitMergeDriverName)
+		return
+	}
+	fmt.Fprintf(w, "Enable the driver in each clone:\n  git config merge.%s.driver %q\n", gitMergeDriverName, gitMergeDriverCommand)
+}
+
+func RenderMergeDriver(w io.Writer, outcome MergeDriverOutcome) {
+	fmt.Fprintf(w, "Merged %s: %d ours + %d theirs records\n", outcome.File, outcome.Ours, outcome.Theirs)
 }
 
 func RunNewTask(title, epicID, body string, opts GlobalOptions, render RenderOptions) error {
@@ -71,5 +84,8 @@ func RenderCreateEpic(w io.Writer, out bulkCreateOutput) {
 	for _, child := range out.Children {
 		fmt.Fprintf(w, "  %s - %s\n", child.ID, child.Title)
 	}
+	for _, edge := range out.Edges {
+		fmt.Fprintf(w, "  %s depends on %s\n", edge.FromID, edge.ToID)
+	}
 	fmt.Fprintf(w, "%d tasks, %d dependencies\n", len(out.Children), len(out.Edges))
 }
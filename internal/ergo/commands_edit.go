@@ -0,0 +1,69 @@
+// Purpose: Present the editor file and the receipts of ergo edit.
+// Exports: FormatEditText, ParseEditText, RenderEdit, and RenderEditConflict.
+// Role: Presentation only; the stale-base check and the merge belong to
+// Application.EditTask.
+// Invariants: the file round-trips: parsing an unedited file returns the text
+// it was formatted from, byte for byte.
+package ergo
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// FormatEditText is the file the editor opens. With the title, its first line
+// is the title and a blank line separates it from the body, as in a commit
+// message.
+func FormatEditText(text TaskText, withTitle bool) string {
+	if !withTitle {
+		return text.Body
+	}
+	return text.Title + "\n\n" + text.Body
+}
+
+// ParseEditText reads a file written by FormatEditText.
+func ParseEditText(file string, withTitle bool) TaskText {
+	if !withTitle {
+		return TaskText{Body: file}
+	}
+	title, body, _ := strings.Cut(file, "\n")
+	return TaskText{Title: title, Body: strings.TrimPrefix(body, "\n")}
+}
+
+func RenderEdit(w io.Writer, outcome EditTaskOutcome) {
+	if len(outcome.ChangedFields) == 0 {
+		fmt.Fprintf(w, "%s - %s (unchanged)\n", outcome.ID, outcome.Title)
+		return
+	}
+	fmt.Fprintf(w, "%s - %s (%s)\n", outcome.ID, outcome.Title, strings.Join(outcome.ChangedFields, ", "))
+}
+
+// RenderEditConflict shows both sides of a conflict as diffs from the base:
+// what was stored while the editor was open, then the edit itself.
+func RenderEditConflict(w io.Writer, conflict *EditConflict, useColor bool) {
+	fmt.Fprintf(w, "%s changed while you were editing.\n", conflict.ID)
+	for _, side := range []struct {
+		heading string
+		text    TaskText
+	}{{"Stored since you began:", conflict.Current}, {"Your edit:", conflict.Edited}} {
+		writeGeneratedLine(w, side.heading, colorBold, useColor)
+		if side.text.Title != conflict.Base.Title {
+			fmt.Fprintf(w, "  title: %s → %s\n", conflict.Base.Title, side.text.Title)
+		}
+		for _, line := range diffLines(conflict.Base.Body, side.text.Body) {
+			style := colorGreen
+			if line[0] == '-' {
+				style = colorRed
+			}
+			fmt.Fprint(w, "  ")
+			writeGenerated(w, line[:1], style, useColor)
+			fmt.Fprintln(w, line[1:])
+		}
+	}
+	if conflict.Clean {
+		fmt.Fprintln(w, "The changes do not overlap and merge cleanly.")
+	} else {
+		fmt.Fprintln(w, "The changes overlap; nothing was written.")
+	}
+}
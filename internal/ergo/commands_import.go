@@ -0,0 +1,45 @@
+// Purpose: Render import receipts and dry-run reports.
+// Role: Presentation only; reading, mapping, and writing belong to
+// Application.ImportBeads.
+package ergo
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// importNoteIssueLimit caps the issue IDs listed per note in text output.
+const importNoteIssueLimit = 5
+
+func RenderImportBeads(w io.Writer, outcome ImportBeadsOutcome) {
+	verb := "Imported"
+	if outcome.DryRun {
+		verb = "Would import"
+	}
+	fmt.Fprintf(w, "%s %s from %s: %s, %s, %s\n", verb,
+		pluralize(outcome.Epics+outcome.Tasks, "issue", "issues"), outcome.Source,
+		pluralize(outcome.Epics, "epic", "epics"), pluralize(outcome.Tasks, "task", "tasks"),
+		pluralize(outcome.Dependencies, "dependency", "dependencies"))
+	if !outcome.DryRun {
+		for _, task := range outcome.Created {
+			fmt.Fprintf(w, "%s = %s - %s\n", task.SourceID, task.ID, task.Title)
+		}
+	}
+	if outcome.Skipped > 0 {
+		fmt.Fprintf(w, "Skipped %s\n", pluralize(outcome.Skipped, "deleted issue", "deleted issues"))
+	}
+	if len(outcome.Notes) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "Not carried over as is:")
+	for _, note := range outcome.Notes {
+		issues := note.Issues
+		more := ""
+		if len(issues) > importNoteIssueLimit {
+			more = fmt.Sprintf(", and %d more", len(issues)-importNoteIssueLimit)
+			issues = issues[:importNoteIssueLimit]
+		}
+		fmt.Fprintf(w, "  %s: %s (%s%s)\n", note.Field, note.Detail, strings.Join(issues, ", "), more)
+	}
+}
@@ -80,3 +80,31 @@ func lifecycleTargetState(kind string) (string, error) {
 		return "", fmt.Errorf("unknown lifecycle command %q", kind)
 	}
 }
+
+// lifecycleMutation builds the mutation for a lifecycle command, including
+// the states it may start from.
+func lifecycleMutation(kind, targetState, message string, messageSet bool) taskMutation {
+	mutation := taskMutation{
+		Kind: kind, State: targetState, StateSet: true,
+		MessageKind: kind, MessageText: message, MessageSet: messageSet,
+	}
+	switch kind {
+	case "open":
+		mutation.AllowedStates = []string{stateTodo, stateDraft, stateDoing, stateBlocked}
+	case "done", "fail", "block":
+		mutation.AllowedStates = []string{stateTodo, stateDoing, stateBlocked, stateDone, stateFailed, stateCanceled, stateError}
+	case "cancel":
+		mutation.AllowedStates = []string{stateTodo, stateDraft, stateDoing, stateBlocked, stateDone, stateFailed, stateCanceled, stateError}
+	}
+	return mutation
+}
+
+func RenderUndo(w io.Writer, outcome UndoOutcome) {
+	fmt.Fprintf(w, "Undid %s from %s\n", strings.Join(outcome.Kinds, ", "), outcome.At)
+	for _, id := range sortedKeys(outcome.Restored) {
+		fmt.Fprintf(w, "%s restored: %s\n", id, strings.Join(outcome.Restored[id], ", "))
+	}
+	for _, id := range outcome.Removed {
+		fmt.Fprintf(w, "%s removed\n", id)
+	}
+}
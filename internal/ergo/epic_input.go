@@ -2,7 +2,10 @@
 // Exports: EpicTaskInput and ParseEpicFile.
 // Role: Turn ordered Markdown chunks into validated child-task inputs.
 // Invariants: Each chunk starts with `# Title`; duplicate titles are rejected.
-// Notes: File order intentionally does not infer dependencies.
+// Invariants: Metadata lines directly follow the title and precede the body.
+// Notes: File order intentionally does not infer dependencies; `After:` lines
+// name them. References resolve against the whole file, so they are expanded
+// only once every title is known.
 package ergo
 
 import (
@@ -13,9 +16,10 @@ import (
 
 // EpicTaskInput describes one child task in an epic file.
 type EpicTaskInput struct {
-	Title string
-	Body  string
-	After []string
+	Title    string
+	Body     string
+	Priority string
+	After    []string
 }
 
 func ParseEpicFile(path string) ([]EpicTaskInput, error) {
@@ -43,9 +47,30 @@ func ParseEpicFile(path string) ([]EpicTaskInput, error) {
 		seenTitles[title] = struct{}{}
 		tasks = append(tasks, task)
 	}
+	for idx := range tasks {
+		tasks[idx].After = expandEpicAfter(tasks[idx].After, seenTitles)
+	}
 	return tasks, nil
 }
 
+// expandEpicAfter splits raw After values on commas unless a value names a
+// sibling title exactly, so titles containing commas stay referenceable.
+func expandEpicAfter(values []string, titles map[string]struct{}) []string {
+	var refs []string
+	for _, value := range values {
+		if _, sibling := titles[value]; sibling {
+			refs = append(refs, value)
+			continue
+		}
+		for _, ref := range strings.Split(value, ",") {
+			if ref = strings.TrimSpace(ref); ref != "" {
+				refs = append(refs, ref)
+			}
+		}
+	}
+	return refs
+}
+
 func splitEpicChunks(content string) []string {
 	lines := strings.Split(content, "\n")
 	chunks := make([]string, 0)
@@ -82,9 +107,36 @@ func parseEpicChunk(chunk string) (EpicTaskInput, error) {
 		return EpicTaskInput{}, fmt.Errorf("chunk title cannot be empty")
 	}
 	task := EpicTaskInput{Title: title}
-	if len(lines) > 1 {
-		body := strings.Join(lines[1:], "\n")
-		task.Body = body
+	rest := lines[1:]
+metadata:
+	for len(rest) > 0 {
+		key, value, ok := strings.Cut(rest[0], ":")
+		if !ok {
+			break
+		}
+		switch strings.TrimSpace(key) {
+		case "Priority":
+			if task.Priority != "" {
+				return EpicTaskInput{}, fmt.Errorf("duplicate Priority line")
+			}
+			priority, err := normalizePriority(value)
+			if err != nil {
+				return EpicTaskInput{}, err
+			}
+			task.Priority = priority
+		case "After":
+			value = strings.TrimSpace(value)
+			if strings.Trim(value, ", ") == "" {
+				return EpicTaskInput{}, fmt.Errorf("After line names no tasks")
+			}
+			task.After = append(task.After, value)
+		default:
+			break metadata
+		}
+		rest = rest[1:]
+	}
+	if len(rest) > 0 {
+		task.Body = strings.Join(rest, "\n")
 	}
 	return task, nil
 }
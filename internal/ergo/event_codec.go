@@ -22,13 +22,15 @@ type EventSource struct {
 }
 
 type NewTaskEvent struct {
-	ID        string `json:"id"`
-	UUID      string `json:"uuid"`
-	EpicID    string `json:"epic_id"`
-	State     string `json:"state"`
-	Title     string `json:"title"`
-	Body      string `json:"body"`
-	CreatedAt string `json:"created_at"`
+	ID        string   `json:"id"`
+	UUID      string   `json:"uuid"`
+	EpicID    string   `json:"epic_id"`
+	State     string   `json:"state"`
+	Title     string   `json:"title"`
+	Body      string   `json:"body"`
+	CreatedAt string   `json:"created_at"`
+	Priority  string   `json:"priority,omitempty"`
+	Labels    []string `json:"labels,omitempty"`
 }
 
 type StateEvent struct {
@@ -49,6 +51,28 @@ type ClaimEvent struct {
 	TS      string `json:"ts"`
 }
 
+// LeaseEvent grants (lease) or extends (heartbeat) the current claimant's
+// lease. The granted duration is the distance from TS to ExpiresAt.
+type LeaseEvent struct {
+	ID        string `json:"id"`
+	AgentID   string `json:"agent_id"`
+	ExpiresAt string `json:"expires_at"`
+	TS        string `json:"ts"`
+}
+
+type PriorityEvent struct {
+	ID       string `json:"id"`
+	Priority string `json:"priority"`
+	TS       string `json:"ts"`
+}
+
+// LabelEvent adds (label) or removes (unlabel) one label on a task.
+type LabelEvent struct {
+	ID    string `json:"id"`
+	Label string `json:"label"`
+	TS    string `json:"ts"`
+}
+
 type TitleUpdateEvent struct {
 	ID    string `json:"id"`
 	Title string `json:"title"`
@@ -108,11 +132,17 @@ const (
 	eventTombstone = "tombstone"
 	eventResult    = "result"
 	eventMessage   = "message"
+	eventLease     = "lease"
+	eventHeartbeat = "heartbeat"
+	eventPriority  = "priority"
+	eventLabel     = "label"
+	eventUnlabel   = "unlabel"
 )
 
 var supportedEventKinds = []string{
 	eventNewTask, eventState, eventClaim, eventUnclaim, eventLink, eventUnlink,
 	eventTitle, eventBody, eventEpic, eventTombstone, eventResult, eventMessage,
+	eventLease, eventHeartbeat, eventPriority, eventLabel, eventUnlabel,
 }
 
 var supportedLegacyEventKinds = []string{"new_epic"}
@@ -140,6 +170,11 @@ var eventDecoders = map[string]eventDecoder{
 	eventTombstone: decodeEventPayload[TombstoneEvent],
 	eventResult:    decodeEventPayload[ResultEvent],
 	eventMessage:   decodeEventPayload[MessageEvent],
+	eventLease:     decodeEventPayload[LeaseEvent],
+	eventHeartbeat: decodeEventPayload[LeaseEvent],
+	eventPriority:  decodeEventPayload[PriorityEvent],
+	eventLabel:     decodeEventPayload[LabelEvent],
+	eventUnlabel:   decodeEventPayload[LabelEvent],
 }
 
 var legacyEventDecoders = map[string]eventDecoder{
@@ -0,0 +1,387 @@
+// Purpose: Check the whole backlog and journal for integrity problems.
+// Exports: FsckProblem, FsckReport, and Repository.Fsck.
+// Role: Diagnostic counterpart to inspectEventLog, which stops at the first fault.
+// Invariants: every complete record is examined; one bad record never hides another.
+// Invariants: repair only truncates interrupted tails and drops orphaned journal
+// entries, under the repository lock; other records keep their bytes.
+package ergo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Fsck problem codes.
+const (
+	fsckMalformedRecord = "malformed_record"
+	fsckInvalidRecord   = "invalid_record"
+	fsckInterruptedTail = "interrupted_tail"
+	fsckSnapshot        = "snapshot"
+	fsckReplay          = "replay"
+	fsckOrphanJournal   = "orphan_journal"
+	fsckEvidenceMissing = "evidence_missing"
+	fsckEvidenceChanged = "evidence_changed"
+)
+
+// Errors make ordinary reads fail; warnings describe state Ergo tolerates.
+const (
+	fsckError   = "error"
+	fsckWarning = "warning"
+)
+
+// FsckProblem is one finding. File is a log name or an evidence path; Line is
+// the 1-based physical line when the finding has one.
+type FsckProblem struct {
+	File       string `json:"file"`
+	Line       int    `json:"line,omitempty"`
+	TaskID     string `json:"task_id,omitempty"`
+	Code       string `json:"code"`
+	Severity   string `json:"severity"`
+	Message    string `json:"message"`
+	Repairable bool   `json:"repairable"`
+	Repaired   bool   `json:"repaired"`
+}
+
+type FsckReport struct {
+	BacklogPath    string
+	JournalPath    string
+	Records        int
+	JournalEntries int
+	Problems       []FsckProblem
+}
+
+// Errors counts unrepaired error-severity problems.
+func (report FsckReport) Errors() int {
+	return report.count(fsckError)
+}
+
+func (report FsckReport) Warnings() int {
+	return report.count(fsckWarning)
+}
+
+// Failure reports unrepaired errors as corruption so the command exits
+// non-zero after printing the full report.
+func (report FsckReport) Failure() error {
+	failures := report.Errors()
+	if failures == 0 {
+		return nil
+	}
+	return classified(ErrorCorruption, fmt.Errorf("fsck found %s in %s", pluralize(failures, "error", "errors"), filepath.Dir(report.BacklogPath)))
+}
+
+func (report FsckReport) count(severity string) int {
+	count := 0
+	for _, problem := range report.Problems {
+		if problem.Severity == severity && !problem.Repaired {
+			count++
+		}
+	}
+	return count
+}
+
+// fsckLine is one non-blank physical record.
+type fsckLine struct {
+	number int
+	raw    []byte
+}
+
+// fsckLines splits data into non-blank records. An unterminated final record
+// that is not valid JSON is returned separately as an interrupted write.
+func fsckLines(data []byte) (lines []fsckLine, tail *fsckLine, validBytes int64) {
+	offset := int64(0)
+	physical := bytes.Split(data, []byte{'\n'})
+	for index, line := range physical {
+		last := index == len(physical)-1
+		if last && len(line) == 0 {
+			break
+		}
+		end := offset + int64(len(line))
+		if !last {
+			end++
+		}
+		trimmed := bytes.TrimSpace(line)
+		record := fsckLine{number: index + 1, raw: trimmed}
+		offset = end
+		if len(trimmed) == 0 {
+			validBytes = end
+			continue
+		}
+		if last && !json.Valid(trimmed) {
+			tail = &record
+			break
+		}
+		lines = append(lines, record)
+		validBytes = end
+	}
+	return lines, tail, validBytes
+}
+
+func (r *Repository) Fsck(repair bool) (FsckReport, error) {
+	if r == nil || r.eventsPath == "" {
+		return FsckReport{}, fmt.Errorf("repository is not open")
+	}
+	var report FsckReport
+	err := withLock(r.lockPath, r.opts, func() error {
+		report = FsckReport{BacklogPath: r.eventsPath, JournalPath: r.journalPath}
+		backlogData, err := readOptionalFile(r.eventsPath)
+		if err != nil {
+			return err
+		}
+		journalData, err := readOptionalFile(r.journalPath)
+		if err != nil {
+			return err
+		}
+		backlogName, journalName := filepath.Base(r.eventsPath), filepath.Base(r.journalPath)
+
+		backlogLines, backlogTail, backlogValid := fsckLines(backlogData)
+		report.Records = len(backlogLines)
+		graph := fsckBacklog(backlogName, backlogLines, &report)
+		if backlogTail != nil {
+			report.Problems = append(report.Problems, FsckProblem{
+				File: backlogName, Line: backlogTail.number, Code: fsckInterruptedTail, Severity: fsckWarning,
+				Message: "interrupted write at the end of the backlog", Repairable: true,
+			})
+		}
+
+		journalLines, journalTail, _ := fsckLines(journalData)
+		report.JournalEntries = len(journalLines)
+		entries, orphans := fsckJournal(journalName, journalLines, graph, &report)
+		if journalTail != nil {
+			report.Problems = append(report.Problems, FsckProblem{
+				File: journalName, Line: journalTail.number, Code: fsckInterruptedTail, Severity: fsckWarning,
+				Message: "interrupted write at the end of the journal", Repairable: true,
+			})
+		}
+		if graph != nil {
+			entries = mergeLegacyJournal(entries, graph)
+		}
+		fsckEvidence(entries, filepath.Dir(r.dir), &report)
+
+		if !repair {
+			return nil
+		}
+		if backlogTail != nil {
+			if err := os.Truncate(r.eventsPath, backlogValid); err != nil {
+				return fmt.Errorf("repair backlog tail: %w", err)
+			}
+			markFsckRepaired(&report, backlogName, fsckInterruptedTail)
+		}
+		if journalTail != nil || len(orphans) > 0 {
+			var kept bytes.Buffer
+			for _, line := range journalLines {
+				if _, orphan := orphans[line.number]; !orphan {
+					kept.Write(line.raw)
+					kept.WriteByte('\n')
+				}
+			}
+			if err := replaceLogAtomically(r.journalPath, kept.Bytes()); err != nil {
+				return fmt.Errorf("repair journal: %w", err)
+			}
+			markFsckRepaired(&report, journalName, fsckInterruptedTail)
+			markFsckRepaired(&report, journalName, fsckOrphanJournal)
+		}
+		return nil
+	})
+	return report, err
+}
+
+// fsckBacklog examines every backlog record and returns the replayed graph,
+// or nil when the records cannot be replayed.
+func fsckBacklog(name string, lines []fsckLine, report *FsckReport) *Graph {
+	problem := func(line int, code, message string) {
+		report.Problems = append(report.Problems, FsckProblem{
+			File: name, Line: line, Code: code, Severity: fsckError, Message: message,
+		})
+	}
+	var snapshot *Graph
+	var decoder *snapshotBlockDecoder
+	snapshotFailed := false
+	var events []Event
+	for index, line := range lines {
+		if decoder != nil && decoder.seen < decoder.total() {
+			if err := decoder.consume(line.number, line.raw); err != nil {
+				problem(line.number, fsckSnapshot, fsckMessage(name, line.number, err))
+				snapshotFailed = true
+				decoder.seen++
+			}
+			if decoder.seen == decoder.total() {
+				snapshot = fsckFinishSnapshot(name, decoder, problem)
+			}
+			continue
+		}
+		var header struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(line.raw, &header); err != nil {
+			problem(line.number, fsckMalformedRecord, fsckMessage(name, line.number, formatEventsParseError(name, line.number, line.raw, err)))
+			continue
+		}
+		if header.Type == snapshotRecordType {
+			if index != 0 {
+				problem(line.number, fsckSnapshot, "snapshot manifest must be the first and only snapshot")
+				snapshotFailed = true
+				continue
+			}
+			var err error
+			if decoder, err = newSnapshotDecoder(name, line.number, line.raw); err != nil {
+				problem(line.number, fsckSnapshot, fsckMessage(name, line.number, err))
+				snapshotFailed = true
+				decoder = nil
+				continue
+			}
+			if decoder.total() == 0 {
+				snapshot = fsckFinishSnapshot(name, decoder, problem)
+			}
+			continue
+		}
+		if snapshotKind(header.Type) {
+			problem(line.number, fsckSnapshot, fmt.Sprintf("snapshot data record outside a snapshot block: %q", header.Type))
+			continue
+		}
+		decoded, err := decodeEventLogRecord(name, line.number, line.raw)
+		if err != nil {
+			problem(line.number, fsckInvalidRecord, fsckMessage(name, line.number, err))
+			continue
+		}
+		events = append(events, decoded...)
+	}
+	if decoder != nil && decoder.seen < decoder.total() {
+		problem(decoder.line, fsckSnapshot, fmt.Sprintf("incomplete snapshot: got %d of %d data records", decoder.seen, decoder.total()))
+		snapshotFailed = true
+	}
+	if snapshotFailed || (decoder != nil && snapshot == nil) || len(report.Problems) > 0 {
+		return nil
+	}
+	base := snapshot
+	if base == nil {
+		base = newGraph()
+	}
+	graph, err := replayEventsOnto(base, events)
+	if err != nil {
+		line, message := fsckLocation(name, err.Error())
+		problem(line, fsckReplay, message)
+		return nil
+	}
+	return graph
+}
+
+func fsckFinishSnapshot(name string, decoder *snapshotBlockDecoder, problem func(int, string, string)) *Graph {
+	graph, err := decoder.finish()
+	if err != nil {
+		problem(decoder.line, fsckSnapshot, fsckMessage(name, decoder.line, err))
+		return nil
+	}
+	return graph
+}
+
+// fsckJournal examines every journal entry. It returns the valid entries and
+// the lines of entries whose task is unknown or pruned; orphans are found only
+// against a replayed graph.
+func fsckJournal(name string, lines []fsckLine, graph *Graph, report *FsckReport) ([]JournalEntry, map[int]struct{}) {
+	orphans := map[int]struct{}{}
+	var entries []JournalEntry
+	for _, line := range lines {
+		var entry JournalEntry
+		if err := json.Unmarshal(line.raw, &entry); err != nil {
+			report.Problems = append(report.Problems, FsckProblem{
+				File: name, Line: line.number, Code: fsckMalformedRecord, Severity: fsckError,
+				Message: fmt.Sprintf("invalid JSON in journal: %v", err),
+			})
+			continue
+		}
+		if err := validateJournalEntry(entry); err != nil {
+			report.Problems = append(report.Problems, FsckProblem{
+				File: name, Line: line.number, TaskID: entry.TaskID, Code: fsckInvalidRecord, Severity: fsckError, Message: err.Error(),
+			})
+			continue
+		}
+		entries = append(entries, entry)
+		if graph == nil || graph.Tasks[entry.TaskID] != nil {
+			continue
+		}
+		message := fmt.Sprintf("journal entry for unknown task %s", entry.TaskID)
+		if _, pruned := graph.Tombstones[entry.TaskID]; pruned {
+			message = fmt.Sprintf("journal entry for pruned task %s", entry.TaskID)
+		}
+		report.Problems = append(report.Problems, FsckProblem{
+			File: name, Line: line.number, TaskID: entry.TaskID, Code: fsckOrphanJournal, Severity: fsckWarning,
+			Message: message, Repairable: true,
+		})
+		orphans[line.number] = struct{}{}
+	}
+	return entries, orphans
+}
+
+// fsckEvidence compares recorded result file hashes with the current files.
+func fsckEvidence(entries []JournalEntry, projectDir string, report *FsckReport) {
+	for _, entry := range entries {
+		if entry.File == nil || entry.File.SHA256 == "" {
+			continue
+		}
+		evidence, err := captureResultEvidence(projectDir, entry.File.Path)
+		switch {
+		case err != nil:
+			report.Problems = append(report.Problems, FsckProblem{
+				File: entry.File.Path, TaskID: entry.TaskID, Code: fsckEvidenceMissing, Severity: fsckWarning, Message: err.Error(),
+			})
+		case evidence.Sha256AtAttach != entry.File.SHA256:
+			report.Problems = append(report.Problems, FsckProblem{
+				File: entry.File.Path, TaskID: entry.TaskID, Code: fsckEvidenceChanged, Severity: fsckWarning,
+				Message: fmt.Sprintf("result file changed since it was attached: sha256 %s, recorded %s", evidence.Sha256AtAttach, entry.File.SHA256),
+			})
+		}
+	}
+}
+
+func markFsckRepaired(report *FsckReport, file, code string) {
+	for index := range report.Problems {
+		problem := &report.Problems[index]
+		if problem.File == file && problem.Code == code && problem.Repairable {
+			problem.Repaired = true
+		}
+	}
+}
+
+// fsckMessage drops the "path:line: " prefix that codec errors carry; the
+// problem records its location separately.
+func fsckMessage(name string, line int, err error) string {
+	return strings.TrimPrefix(err.Error(), fmt.Sprintf("%s:%d: ", name, line))
+}
+
+// fsckLocation recovers the line from a replay context such as
+// "backlog.jsonl:12 transaction event 1: ...".
+func fsckLocation(name, message string) (int, string) {
+	rest, ok := strings.CutPrefix(message, name+":")
+	if !ok {
+		return 0, message
+	}
+	digits := len(rest) - len(strings.TrimLeft(rest, "0123456789"))
+	line, err := strconv.Atoi(rest[:digits])
+	if err != nil {
+		return 0, message
+	}
+	if _, detail, found := strings.Cut(rest, ": "); found {
+		return line, detail
+	}
+	return line, message
+}
+
+func pluralize(count int, singular, plural string) string {
+	if count == 1 {
+		return fmt.Sprintf("%d %s", count, singular)
+	}
+	return fmt.Sprintf("%d %s", count, plural)
+}
+
+func readOptionalFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return data, err
+}
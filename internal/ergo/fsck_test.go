@@ -0,0 +1,128 @@
+package ergo
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func fsckCodes(report FsckReport) []string {
+	var codes []string
+	for _, problem := range report.Problems {
+		codes = append(codes, problem.Code)
+	}
+	return codes
+}
+
+func TestFsckReportsEveryProblemAndRepairsTails(t *testing.T) {
+	app := newTestApplication(t)
+	dir := filepath.Join(app.repository.StartDir, dataDirName)
+	kept, err := app.CreateTask(CreateTaskRequest{Title: "Kept"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pruned, err := app.CreateTask(CreateTaskRequest{Title: "Pruned"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(app.repository.StartDir, "evidence.md"), []byte("before"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.Result(ResultRequest{ID: kept.ID, Text: "Verified", FilePath: "evidence.md", FileSet: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(app.repository.StartDir, "evidence.md"), []byte("after"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	journalPath := filepath.Join(dir, journalFileName)
+	journal, err := os.ReadFile(journalPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.Lifecycle(LifecycleRequest{Kind: "cancel", ID: pruned.ID}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.Prune(PruneRequest{Confirm: true}); err != nil {
+		t.Fatal(err)
+	}
+	// Restore the pruned task's journal entries, then interrupt both logs.
+	if err := os.WriteFile(journalPath, append(journal, `{"version":1,"task_id"`...), 0644); err != nil {
+		t.Fatal(err)
+	}
+	backlogPath := filepath.Join(dir, "backlog.jsonl")
+	backlog, err := os.ReadFile(backlogPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(backlogPath, append(backlog, `{"type":"trans`...), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	checked, err := app.Fsck(FsckRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{fsckInterruptedTail, fsckOrphanJournal, fsckInterruptedTail, fsckEvidenceChanged}
+	if got := fsckCodes(checked); !equalStrings(got, want) {
+		t.Fatalf("fsck codes = %v, want %v", got, want)
+	}
+	if checked.Errors() != 0 || checked.Failure() != nil {
+		t.Fatalf("warnings counted as errors: %#v", checked.Problems)
+	}
+
+	repaired, err := app.Fsck(FsckRequest{Repair: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repaired.Warnings() != 1 {
+		t.Fatalf("repair left %d warnings: %#v", repaired.Warnings(), repaired.Problems)
+	}
+	after, err := app.Fsck(FsckRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fsckCodes(after); !equalStrings(got, []string{fsckEvidenceChanged}) {
+		t.Fatalf("codes after repair = %v", got)
+	}
+	data, err := os.ReadFile(backlogPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, backlog) {
+		t.Fatal("repair changed complete backlog records")
+	}
+}
+
+func TestFsckReportsCorruptionPastTheFirstFault(t *testing.T) {
+	app := newTestApplication(t)
+	if _, err := app.CreateTask(CreateTaskRequest{Title: "Snapshotted"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.Compact(); err != nil {
+		t.Fatal(err)
+	}
+	backlogPath := filepath.Join(app.repository.StartDir, dataDirName, "backlog.jsonl")
+	data, err := os.ReadFile(backlogPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data = bytes.Replace(data, []byte("Snapshotted"), []byte("Tampered"), 1)
+	data = append(data, "not json\n{\"type\":\"transaction\",\"version\":9,\"events\":[]}\n"...)
+	if err := os.WriteFile(backlogPath, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := app.Fsck(FsckRequest{Repair: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{fsckSnapshot, fsckMalformedRecord, fsckInvalidRecord}
+	if got := fsckCodes(report); !equalStrings(got, want) {
+		t.Fatalf("fsck codes = %v, want %v", got, want)
+	}
+	if report.Problems[1].Line != 3 || report.Problems[2].Line != 4 {
+		t.Fatalf("problem lines = %#v", report.Problems)
+	}
+	requireApplicationError(t, report.Failure(), ErrorCorruption)
+}
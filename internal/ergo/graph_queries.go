@@ -177,13 +177,16 @@ func (graph *Graph) IsReady(id string) bool {
 	return graph.isReadyUncached(id)
 }
 
+// isReadyUncached treats doing work whose lease has lapsed as unclaimed, so
+// abandoned claims return to the ready frontier without a write.
 func (graph *Graph) isReadyUncached(id string) bool {
 	task := graph.Tasks[id]
-	return task != nil &&
-		!graph.IsEpic(id) &&
-		task.State == stateTodo &&
-		task.ClaimedBy == "" &&
-		len(graph.Blockers(id)) == 0
+	if task == nil || graph.IsEpic(id) {
+		return false
+	}
+	available := task.State == stateTodo && task.ClaimedBy == ""
+	reclaimable := task.State == stateDoing && task.leaseExpired(graph.leaseNow())
+	return (available || reclaimable) && len(graph.Blockers(id)) == 0
 }
 
 // prepareDerivedQueries calculates stable list-time graph projections once.
@@ -221,15 +224,22 @@ func (graph *Graph) IsBlocked(id string) bool {
 
 func readyTasks(graph *Graph) []*Task {
 	tasks := filterNonContainers(listTasks(graph, "", true), graph)
-	sort.Slice(tasks, func(i, j int) bool {
-		if tasks[i].CreatedAt.Equal(tasks[j].CreatedAt) {
-			return tasks[i].ID < tasks[j].ID
-		}
-		return tasks[i].CreatedAt.Before(tasks[j].CreatedAt)
-	})
+	sort.Slice(tasks, func(i, j int) bool { return claimsBefore(tasks[i], tasks[j]) })
 	return tasks
 }
 
+// claimsBefore is automatic claim order: the more urgent priority first, then
+// the older task, then the smaller ID.
+func claimsBefore(left, right *Task) bool {
+	if leftPriority, rightPriority := effectivePriority(left), effectivePriority(right); leftPriority != rightPriority {
+		return leftPriority < rightPriority
+	}
+	if left.CreatedAt.Equal(right.CreatedAt) {
+		return left.ID < right.ID
+	}
+	return left.CreatedAt.Before(right.CreatedAt)
+}
+
 func filterNonContainers(tasks []*Task, graph *Graph) []*Task {
 	filtered := tasks[:0]
 	for _, task := range tasks {
@@ -0,0 +1,220 @@
+// Purpose: Render graph outcomes as Graphviz DOT or a Mermaid flowchart.
+// Role: Presentation only; visibility belongs to the shared list tree.
+// Invariants: edges point from a dependency to its dependent, so diagrams read
+// in work order. Epics with visible children become clusters; an edge that
+// touches such an epic is clipped to its cluster (DOT) or subgraph (Mermaid).
+package ergo
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type graphStyle struct {
+	fill, stroke, text string
+	dashed             bool
+}
+
+// graphStyles is keyed by node class: a task display state, or "epic_" plus a
+// derived epic state.
+var graphStyles = map[string]graphStyle{
+	"ready":         {fill: "#d9f2d9", stroke: "#2e7d32"},
+	"waiting":       {fill: "#f5f5f5", stroke: "#9e9e9e"},
+	stateDraft:      {fill: "#ffffff", stroke: "#9e9e9e", dashed: true},
+	stateDoing:      {fill: "#d6e6fb", stroke: "#1565c0"},
+	stateBlocked:    {fill: "#fff1c9", stroke: "#b7791f"},
+	stateDone:       {fill: "#e6e6e6", stroke: "#757575", text: "#555555"},
+	stateFailed:     {fill: "#fbd6d6", stroke: "#c62828"},
+	stateCanceled:   {fill: "#eeeeee", stroke: "#bdbdbd", text: "#777777", dashed: true},
+	stateError:      {fill: "#fbd6d6", stroke: "#c62828", dashed: true},
+	"epic_active":   {fill: "#fafcff", stroke: "#1565c0"},
+	"epic_done":     {fill: "#f7f7f7", stroke: "#757575", text: "#555555"},
+	"epic_failed":   {fill: "#fff5f5", stroke: "#c62828"},
+	"epic_canceled": {fill: "#f7f7f7", stroke: "#bdbdbd", text: "#777777", dashed: true},
+	"epic_empty":    {fill: "#ffffff", stroke: "#9e9e9e", dashed: true},
+}
+
+// RenderGraph writes the outcome in its requested format.
+func RenderGraph(w io.Writer, outcome GraphOutcome) {
+	if outcome.Format == graphFormatMermaid {
+		renderGraphMermaid(w, outcome)
+		return
+	}
+	renderGraphDOT(w, outcome)
+}
+
+func renderGraphDOT(w io.Writer, outcome GraphOutcome) {
+	graph := outcome.Graph
+	clusters := graphClusters(outcome.Roots)
+	fmt.Fprintln(w, "digraph ergo {")
+	fmt.Fprintln(w, "  rankdir=LR;")
+	fmt.Fprintln(w, "  compound=true;")
+	fmt.Fprintln(w, `  node [shape=box, style="rounded,filled", fontname="Helvetica"];`)
+	for _, root := range outcome.Roots {
+		if _, cluster := clusters[root.task.ID]; !cluster {
+			writeDOTNode(w, "  ", graph, root)
+			continue
+		}
+		style := graphStyles[graphNodeClass(graph, root)]
+		fmt.Fprintf(w, "  subgraph %s {\n", strconv.Quote("cluster_"+root.task.ID))
+		fmt.Fprintf(w, "    label=%s;\n", strconv.Quote(graphNodeLabel(graph, root)))
+		fmt.Fprintf(w, "    style=%s; color=%s; bgcolor=%s;\n", strconv.Quote(dotStyle("rounded", style)), strconv.Quote(style.stroke), strconv.Quote(style.fill))
+		for _, child := range root.children {
+			writeDOTNode(w, "    ", graph, child)
+		}
+		fmt.Fprintln(w, "  }")
+	}
+	for _, edge := range graphEdges(graph, outcome.Roots) {
+		var attributes []string
+		from, to := edge.ToID, edge.FromID
+		if anchor, cluster := clusters[from]; cluster {
+			attributes = append(attributes, "ltail="+strconv.Quote("cluster_"+from))
+			from = anchor
+		}
+		if anchor, cluster := clusters[to]; cluster {
+			attributes = append(attributes, "lhead="+strconv.Quote("cluster_"+to))
+			to = anchor
+		}
+		fmt.Fprintf(w, "  %s -> %s", strconv.Quote(from), strconv.Quote(to))
+		if len(attributes) > 0 {
+			fmt.Fprintf(w, " [%s]", strings.Join(attributes, ", "))
+		}
+		fmt.Fprintln(w, ";")
+	}
+	fmt.Fprintln(w, "}")
+}
+
+func writeDOTNode(w io.Writer, indent string, graph *Graph, node *treeNode) {
+	style := graphStyles[graphNodeClass(graph, node)]
+	fmt.Fprintf(w, "%s%s [label=%s, style=%s, fillcolor=%s, color=%s", indent, strconv.Quote(node.task.ID),
+		strconv.Quote(graphNodeLabel(graph, node)), strconv.Quote(dotStyle("rounded,filled", style)),
+		strconv.Quote(style.fill), strconv.Quote(style.stroke))
+	if style.text != "" {
+		fmt.Fprintf(w, ", fontcolor=%s", strconv.Quote(style.text))
+	}
+	fmt.Fprintln(w, "];")
+}
+
+func dotStyle(base string, style graphStyle) string {
+	if style.dashed {
+		return base + ",dashed"
+	}
+	return base
+}
+
+func renderGraphMermaid(w io.Writer, outcome GraphOutcome) {
+	graph := outcome.Graph
+	clusters := graphClusters(outcome.Roots)
+	used := map[string]struct{}{}
+	var assignments []string
+	writeNode := func(indent string, node *treeNode) {
+		class := graphNodeClass(graph, node)
+		used[class] = struct{}{}
+		fmt.Fprintf(w, "%s%s[%s]:::%s\n", indent, node.task.ID, mermaidLabel(graphNodeLabel(graph, node)), class)
+	}
+	fmt.Fprintln(w, "```mermaid")
+	fmt.Fprintln(w, "flowchart LR")
+	for _, root := range outcome.Roots {
+		if _, cluster := clusters[root.task.ID]; !cluster {
+			writeNode("  ", root)
+			continue
+		}
+		class := graphNodeClass(graph, root)
+		used[class] = struct{}{}
+		assignments = append(assignments, fmt.Sprintf("  class %s %s", root.task.ID, class))
+		fmt.Fprintf(w, "  subgraph %s[%s]\n", root.task.ID, mermaidLabel(graphNodeLabel(graph, root)))
+		for _, child := range root.children {
+			writeNode("    ", child)
+		}
+		fmt.Fprintln(w, "  end")
+	}
+	for _, edge := range graphEdges(graph, outcome.Roots) {
+		fmt.Fprintf(w, "  %s --> %s\n", edge.ToID, edge.FromID)
+	}
+	for _, assignment := range assignments {
+		fmt.Fprintln(w, assignment)
+	}
+	for _, class := range sortedKeys(used) {
+		style := graphStyles[class]
+		definition := fmt.Sprintf("fill:%s,stroke:%s", style.fill, style.stroke)
+		if style.text != "" {
+			definition += ",color:" + style.text
+		}
+		if style.dashed {
+			definition += ",stroke-dasharray:4 3"
+		}
+		fmt.Fprintf(w, "  classDef %s %s\n", class, definition)
+	}
+	fmt.Fprintln(w, "```")
+}
+
+// mermaidLabel quotes a label, using Mermaid's entity form for quotes.
+func mermaidLabel(label string) string {
+	return `"` + strings.ReplaceAll(label, `"`, "#quot;") + `"`
+}
+
+// graphClusters maps each epic drawn as a cluster to the first of its visible
+// children, which anchors edges clipped to the cluster.
+func graphClusters(roots []*treeNode) map[string]string {
+	clusters := map[string]string{}
+	for _, root := range roots {
+		if root.isEpic && len(root.children) > 0 {
+			clusters[root.task.ID] = root.children[0].task.ID
+		}
+	}
+	return clusters
+}
+
+// graphEdges returns every dependency between visible nodes in render order.
+func graphEdges(graph *Graph, roots []*treeNode) []sequenceEdge {
+	var order []string
+	visible := map[string]struct{}{}
+	var walk func([]*treeNode)
+	walk = func(nodes []*treeNode) {
+		for _, node := range nodes {
+			order = append(order, node.task.ID)
+			visible[node.task.ID] = struct{}{}
+			walk(node.children)
+		}
+	}
+	walk(roots)
+	var edges []sequenceEdge
+	for _, id := range order {
+		for _, dependency := range sortedKeys(graph.Deps[id]) {
+			if _, shown := visible[dependency]; shown {
+				edges = append(edges, sequenceEdge{FromID: id, ToID: dependency})
+			}
+		}
+	}
+	return edges
+}
+
+func graphNodeClass(graph *Graph, node *treeNode) string {
+	if node.isEpic {
+		return "epic_" + graph.EpicState(node.task.ID)
+	}
+	if node.task.State == stateTodo {
+		if node.isReady {
+			return "ready"
+		}
+		return "waiting"
+	}
+	return node.task.State
+}
+
+func graphNodeLabel(graph *Graph, node *treeNode) string {
+	task := node.task
+	display := task
+	if node.isEpic && graph.EpicState(task.ID) == stateFailed {
+		copy := *task
+		copy.State = stateFailed
+		display = &copy
+	}
+	label := task.ID + " " + stateIcon(display, node.isReady, node.isEpic) + " " + task.Title
+	if task.ClaimedBy != "" {
+		label += " @" + task.ClaimedBy
+	}
+	return label
+}
@@ -0,0 +1,73 @@
+// Purpose: Render history outcomes as a readable audit or a versioned JSON document.
+// Role: Presentation only; event selection belongs to Application.History.
+// Invariants: diff lines carry stored text, so only their markers are colored.
+package ergo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+)
+
+const historyJSONVersion = 1
+
+type historyJSONDocument struct {
+	Version   int                `json:"version"`
+	ID        string             `json:"id"`
+	Title     string             `json:"title"`
+	Compacted bool               `json:"compacted"`
+	Entries   []historyJSONEntry `json:"entries"`
+}
+
+type historyJSONEntry struct {
+	At     string   `json:"at"`
+	Origin string   `json:"origin"`
+	Path   string   `json:"path"`
+	Line   int      `json:"line"`
+	Kind   string   `json:"kind"`
+	Agent  string   `json:"agent,omitempty"`
+	Text   string   `json:"text,omitempty"`
+	Diff   []string `json:"diff,omitempty"`
+}
+
+func RenderHistory(w io.Writer, outcome HistoryOutcome, useColor bool) {
+	writeGenerated(w, outcome.ID, colorCyan, useColor)
+	fmt.Fprintf(w, "  %s\n", outcome.Title)
+	if outcome.Compacted {
+		writeGeneratedLine(w, "History before the last compaction is not retained.", colorDim, useColor)
+	}
+	for _, entry := range outcome.Entries {
+		writeGenerated(w, fmt.Sprintf("%s  %s:%d  ", entry.At, filepath.Base(entry.Path), entry.Line), colorDim, useColor)
+		writeGenerated(w, entry.Kind, colorBold, useColor)
+		if entry.Text != "" {
+			fmt.Fprintf(w, "  %s", entry.Text)
+		}
+		if entry.Agent != "" {
+			writeGenerated(w, "  by "+entry.Agent, colorDim, useColor)
+		}
+		fmt.Fprintln(w)
+		for _, line := range entry.Diff {
+			style := colorGreen
+			if line[0] == '-' {
+				style = colorRed
+			}
+			fmt.Fprint(w, "    ")
+			writeGenerated(w, line[:1], style, useColor)
+			fmt.Fprintln(w, line[1:])
+		}
+	}
+}
+
+func RenderHistoryJSON(w io.Writer, outcome HistoryOutcome) error {
+	document := historyJSONDocument{
+		Version: historyJSONVersion, ID: outcome.ID, Title: outcome.Title, Compacted: outcome.Compacted,
+		Entries: make([]historyJSONEntry, 0, len(outcome.Entries)),
+	}
+	for _, entry := range outcome.Entries {
+		document.Entries = append(document.Entries, historyJSONEntry(entry))
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(document)
+}
@@ -0,0 +1,293 @@
+// Purpose: Serve the backlog as a local HTTP/JSON API with a change stream.
+// Exports: NewHTTPServer.
+// Role: Translate REST requests into Application requests and answer with the
+// same versioned documents the CLI writes under --json.
+// Invariants: a route makes one Application call and holds the lock only
+// inside it; failures answer with the --json error document under the status
+// of their ErrorKind; requests a browser page could forge never reach a route.
+// Notes: /v1/events is a server-sent event stream of watch events; it holds
+// no lock between wakes.
+package ergo
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// NewHTTPServer returns the API handler for app. The caller owns the
+// listener and decides which addresses may reach it; host is the listen host
+// clients may name besides localhost and loopback addresses, or "".
+func NewHTTPServer(app *Application, version, host string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/tasks", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		request := ListRequest{
+			EpicID: query.Get("epic"), Labels: query["label"], NotLabels: query["not_label"], At: query.Get("at"),
+		}
+		var err error
+		if request.ReadyOnly, err = queryBool(query.Get("ready")); err == nil {
+			request.ShowAll, err = queryBool(query.Get("all"))
+		}
+		if err != nil {
+			writeHTTPError(w, classified(ErrorUsage, err))
+			return
+		}
+		outcome, err := app.List(request)
+		if err != nil {
+			writeHTTPError(w, err)
+			return
+		}
+		writeHTTPDocument(w, http.StatusOK, func(w io.Writer) error { return RenderListJSON(w, outcome) })
+	})
+	mux.HandleFunc("GET /v1/tasks/{id}", func(w http.ResponseWriter, r *http.Request) {
+		outcome, err := app.Show(ShowRequest{ID: r.PathValue("id"), At: r.URL.Query().Get("at")})
+		writeHTTPOutcome(w, http.StatusOK, outcome, err)
+	})
+	mux.HandleFunc("POST /v1/tasks", func(w http.ResponseWriter, r *http.Request) {
+		request, err := decodeHTTPBody[CreateTaskRequest](w, r, "Title", "EpicID", "Body", "Draft", "Priority", "Labels")
+		if err == nil {
+			var outcome CreateTaskOutcome
+			outcome, err = app.CreateTask(request)
+			writeHTTPOutcome(w, http.StatusCreated, outcome, err)
+			return
+		}
+		writeHTTPError(w, err)
+	})
+	mux.HandleFunc("POST /v1/claim", func(w http.ResponseWriter, r *http.Request) {
+		request, err := decodeHTTPBody[ClaimRequest](w, r, "ID", "AgentID", "Lease", "Labels", "IfRevision")
+		if err == nil {
+			var outcome ClaimOutcome
+			outcome, err = app.Claim(request)
+			writeHTTPOutcome(w, http.StatusOK, outcome, err)
+			return
+		}
+		writeHTTPError(w, err)
+	})
+	for _, kind := range []string{"done", "fail", "block", "cancel", "open"} {
+		mux.HandleFunc("POST /v1/tasks/{id}/"+kind, func(w http.ResponseWriter, r *http.Request) {
+			request, err := decodeHTTPBody[LifecycleRequest](w, r, "Messages", "IfRevision")
+			if err == nil {
+				request.Kind, request.ID = kind, r.PathValue("id")
+				var outcome LifecycleOutcome
+				outcome, err = app.Lifecycle(request)
+				writeHTTPOutcome(w, http.StatusOK, outcome, err)
+				return
+			}
+			writeHTTPError(w, err)
+		})
+	}
+	mux.HandleFunc("POST /v1/tasks/{id}/results", func(w http.ResponseWriter, r *http.Request) {
+		request, err := decodeHTTPBody[ResultRequest](w, r, "Text", "FilePath", "IfRevision")
+		if err == nil {
+			request.ID, request.FileSet = r.PathValue("id"), request.FilePath != ""
+			var outcome ResultOutcome
+			outcome, err = app.Result(request)
+			writeHTTPOutcome(w, http.StatusOK, outcome, err)
+			return
+		}
+		writeHTTPError(w, err)
+	})
+	mux.HandleFunc("POST /v1/tasks/{id}/move", func(w http.ResponseWriter, r *http.Request) {
+		request, err := decodeHTTPBody[MoveRequest](w, r, "DestinationID", "ToRoot", "IfRevision")
+		if err == nil {
+			request.ID = r.PathValue("id")
+			var outcome MoveOutcome
+			outcome, err = app.Move(request)
+			writeHTTPOutcome(w, http.StatusOK, outcome, err)
+			return
+		}
+		writeHTTPError(w, err)
+	})
+	for command, eventType := range map[string]string{"sequence": eventLink, "unsequence": eventUnlink} {
+		mux.HandleFunc("POST /v1/"+command, func(w http.ResponseWriter, r *http.Request) {
+			request, err := decodeHTTPBody[SequenceRequest](w, r, "IDs", "IfRevisions")
+			if err == nil {
+				request.Command, request.EventType = command, eventType
+				var outcome SequenceOutcome
+				outcome, err = app.Sequence(request)
+				writeHTTPOutcome(w, http.StatusOK, outcome, err)
+				return
+			}
+			writeHTTPError(w, err)
+		})
+	}
+	mux.HandleFunc("GET /v1/events", func(w http.ResponseWriter, r *http.Request) {
+		readyOnly, err := queryBool(r.URL.Query().Get("ready"))
+		if err != nil {
+			writeHTTPError(w, classified(ErrorUsage, err))
+			return
+		}
+		serveHTTPEvents(w, r, app, readyOnly)
+	})
+	mux.HandleFunc("GET /v1/version", func(w http.ResponseWriter, r *http.Request) {
+		writeHTTPOutcome(w, http.StatusOK, app.Version(VersionRequest{Version: version}), nil)
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeHTTPError(w, classified(ErrorNotFound, fmt.Errorf("no route for %s %s", r.Method, r.URL.Path)))
+	})
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := checkHTTPCaller(r, host); err != nil {
+			writeHTTPError(w, classified(ErrorUsage, err))
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// checkHTTPCaller refuses requests a web page could send on a browser's
+// behalf. A Host naming some other site means its DNS was rebound to this
+// listener; an Origin other than the Host is a cross-site request; and a
+// write must be JSON, which no page can post without a CORS preflight.
+func checkHTTPCaller(r *http.Request, host string) error {
+	name := r.Host
+	if split, _, err := net.SplitHostPort(r.Host); err == nil {
+		name = split
+	}
+	if !isLocalHTTPHost(name) && (host == "" || name != strings.Trim(host, "[]")) {
+		return fmt.Errorf("refusing request for host %q: address the server by localhost or its listen address", r.Host)
+	}
+	if origin := r.Header.Get("Origin"); origin != "" {
+		if parsed, err := url.Parse(origin); err != nil || parsed.Host != r.Host {
+			return fmt.Errorf("refusing cross-origin request from %q", origin)
+		}
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+			return errors.New("request body must be sent as Content-Type: application/json")
+		}
+	}
+	return nil
+}
+
+func isLocalHTTPHost(name string) bool {
+	name = strings.Trim(name, "[]")
+	if name == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(name)
+	return ip != nil && ip.IsLoopback()
+}
+
+// serveHTTPEvents streams watch events until the client goes away. Each event
+// is named by its kind and carries the ergo watch --json document.
+func serveHTTPEvents(w http.ResponseWriter, r *http.Request, app *Application, readyOnly bool) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeHTTPError(w, classified(ErrorInternal, errors.New("response writer cannot stream")))
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, ": watching\n\n")
+	flusher.Flush()
+	err := app.Watch(WatchRequest{ReadyOnly: readyOnly, Stop: r.Context().Done()}, func(event WatchEvent) error {
+		var data bytes.Buffer
+		if err := RenderWatchEventJSON(&data, event); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n", event.Kind, data.Bytes()); err != nil {
+			return err
+		}
+		flusher.Flush()
+		return nil
+	})
+	if err != nil && r.Context().Err() == nil {
+		var data bytes.Buffer
+		_ = RenderErrorJSON(&data, httpErrorKind(err), err)
+		fmt.Fprintf(w, "event: error\ndata: %s\n", data.Bytes())
+		flusher.Flush()
+	}
+}
+
+// decodeHTTPBody decodes a JSON object into R, accepting only the named
+// fields under their snake_case names. An empty body leaves R zero.
+func decodeHTTPBody[R any](w http.ResponseWriter, r *http.Request, fields ...string) (R, error) {
+	var request R
+	exposed := make(map[string]mcpField, len(fields))
+	for _, field := range fields {
+		exposed[field] = mcpField{}
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxLogRecordBytes))
+	if err != nil {
+		return request, classified(ErrorUsage, err)
+	}
+	if err := decodeMCPArguments(json.RawMessage(body), exposed, reflect.ValueOf(&request).Elem()); err != nil {
+		return request, classified(ErrorUsage, err)
+	}
+	return request, nil
+}
+
+func queryBool(value string) (bool, error) {
+	if value == "" {
+		return false, nil
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid boolean %q", value)
+	}
+	return parsed, nil
+}
+
+func writeHTTPOutcome(w http.ResponseWriter, status int, outcome any, err error) {
+	if err != nil {
+		writeHTTPError(w, err)
+		return
+	}
+	writeHTTPDocument(w, status, func(w io.Writer) error { return RenderOutcomeJSON(w, outcome) })
+}
+
+// writeHTTPDocument renders before writing the status, so a rendering
+// failure still answers with an error document.
+func writeHTTPDocument(w http.ResponseWriter, status int, render func(io.Writer) error) {
+	var document bytes.Buffer
+	if err := render(&document); err != nil {
+		writeHTTPError(w, classified(ErrorInternal, err))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(document.Bytes())
+}
+
+func writeHTTPError(w http.ResponseWriter, err error) {
+	kind := httpErrorKind(err)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(httpStatus(kind))
+	_ = RenderErrorJSON(w, kind, err)
+}
+
+func httpErrorKind(err error) ErrorKind {
+	kind, ok := ApplicationErrorKind(err)
+	if !ok {
+		return ErrorInternal
+	}
+	return kind
+}
+
+func httpStatus(kind ErrorKind) int {
+	switch kind {
+	case ErrorUsage:
+		return http.StatusBadRequest
+	case ErrorNotFound:
+		return http.StatusNotFound
+	case ErrorConflict:
+		return http.StatusConflict
+	case ErrorBusy:
+		return http.StatusServiceUnavailable
+	case ErrorTimeout:
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
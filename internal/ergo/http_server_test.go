@@ -0,0 +1,197 @@
+package ergo
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// httpCall sends one request and decodes the JSON answer into a map.
+func httpCall(t *testing.T, server *httptest.Server, method, path, body string, wantStatus int) map[string]any {
+	t.Helper()
+	request, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if method != "GET" {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	response, err := server.Client().Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	var document map[string]any
+	if err := json.NewDecoder(response.Body).Decode(&document); err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	if response.StatusCode != wantStatus {
+		t.Fatalf("%s %s = %d %v, want %d", method, path, response.StatusCode, document, wantStatus)
+	}
+	return document
+}
+
+func TestHTTPServerRunsUseCasesAsJSON(t *testing.T) {
+	server := httptest.NewServer(NewHTTPServer(newTestApplication(t), "test", ""))
+	defer server.Close()
+
+	first := httpCall(t, server, "POST", "/v1/tasks", `{"title":"Design","priority":"P1"}`, http.StatusCreated)["id"].(string)
+	second := httpCall(t, server, "POST", "/v1/tasks", `{"title":"Build","labels":["api"]}`, http.StatusCreated)["id"].(string)
+	epic := httpCall(t, server, "POST", "/v1/tasks", `{"title":"Launch"}`, http.StatusCreated)["id"].(string)
+	httpCall(t, server, "POST", "/v1/sequence", `{"ids":["`+first+`","`+second+`"]}`, http.StatusOK)
+	moved := httpCall(t, server, "POST", "/v1/tasks/"+second+"/move", `{"destination_id":"`+epic+`"}`, http.StatusOK)
+	if moved["changed"] != true {
+		t.Fatalf("move = %v", moved)
+	}
+
+	list := httpCall(t, server, "GET", "/v1/tasks?ready=true", "", http.StatusOK)
+	if list["version"] == nil || !strings.Contains(mustJSON(t, list), first) || strings.Contains(mustJSON(t, list), `"id":"`+second+`"`) {
+		t.Fatalf("ready list = %v", list)
+	}
+	claimed := httpCall(t, server, "POST", "/v1/claim", `{"agent_id":"dashboard","lease":"30m"}`, http.StatusOK)
+	if task := claimed["task"].(map[string]any); task["id"] != first || task["claimed_by"] != "dashboard" {
+		t.Fatalf("claim = %v", claimed)
+	}
+	httpCall(t, server, "POST", "/v1/tasks/"+first+"/results", `{"text":"Schema drafted"}`, http.StatusOK)
+	done := httpCall(t, server, "POST", "/v1/tasks/"+first+"/done", `{"messages":["Shipped"]}`, http.StatusOK)
+	if done["task"].(map[string]any)["state"] != stateDone || done["ready"].(map[string]any)["id"] != second {
+		t.Fatalf("done = %v", done)
+	}
+	shown := httpCall(t, server, "GET", "/v1/tasks/"+first, "", http.StatusOK)
+	if !strings.Contains(mustJSON(t, shown), "Schema drafted") {
+		t.Fatalf("show = %v", shown)
+	}
+}
+
+func TestHTTPServerMapsErrorKindsToStatus(t *testing.T) {
+	server := httptest.NewServer(NewHTTPServer(newTestApplication(t), "test", ""))
+	defer server.Close()
+	for _, test := range []struct {
+		method, path, body string
+		status             int
+		kind               ErrorKind
+	}{
+		{"GET", "/v1/tasks/ZZZZZZ", "", http.StatusNotFound, ErrorNotFound},
+		{"POST", "/v1/tasks", `{"title":"A","bogus":1}`, http.StatusBadRequest, ErrorUsage},
+		{"POST", "/v1/tasks", `{"title":" "}`, http.StatusBadRequest, ErrorUsage},
+		{"POST", "/v1/claim", `{}`, http.StatusBadRequest, ErrorUsage},
+		{"GET", "/v1/tasks?ready=maybe", "", http.StatusBadRequest, ErrorUsage},
+		{"DELETE", "/v1/tasks", "", http.StatusNotFound, ErrorNotFound},
+	} {
+		document := httpCall(t, server, test.method, test.path, test.body, test.status)
+		if failure, ok := document["error"].(map[string]any); !ok || failure["kind"] != string(test.kind) {
+			t.Errorf("%s %s = %v, want kind %s", test.method, test.path, document, test.kind)
+		}
+	}
+	id := httpCall(t, server, "POST", "/v1/tasks", `{"title":"Owned"}`, http.StatusCreated)["id"].(string)
+	httpCall(t, server, "POST", "/v1/claim", `{"id":"`+id+`","agent_id":"first"}`, http.StatusOK)
+	httpCall(t, server, "POST", "/v1/claim", `{"id":"`+id+`","agent_id":"second"}`, http.StatusConflict)
+}
+
+func TestHTTPServerRefusesRequestsABrowserPageCouldForge(t *testing.T) {
+	app := newTestApplication(t)
+	server := httptest.NewServer(NewHTTPServer(app, "test", "ergo.internal"))
+	defer server.Close()
+	for _, test := range []struct {
+		name, method, path, host, origin, contentType string
+		status                                        int
+	}{
+		{"form post", "POST", "/v1/tasks", "", "", "text/plain", http.StatusBadRequest},
+		{"untyped post", "POST", "/v1/tasks", "", "", "", http.StatusBadRequest},
+		{"rebound host", "GET", "/v1/tasks", "evil.example", "", "", http.StatusBadRequest},
+		{"rebound host with port", "POST", "/v1/tasks", "evil.example:7420", "", "application/json", http.StatusBadRequest},
+		{"cross origin", "POST", "/v1/tasks", "", "https://evil.example", "application/json", http.StatusBadRequest},
+		{"opaque origin", "GET", "/v1/tasks", "", "null", "", http.StatusBadRequest},
+		{"listen host", "GET", "/v1/tasks", "ergo.internal:7420", "", "", http.StatusOK},
+		{"localhost", "GET", "/v1/tasks", "localhost", "", "", http.StatusOK},
+		{"same origin", "POST", "/v1/tasks", "", server.URL, "application/json; charset=utf-8", http.StatusCreated},
+	} {
+		request, err := http.NewRequest(test.method, server.URL+test.path, strings.NewReader(`{"title":"Forged"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if test.host != "" {
+			request.Host = test.host
+		}
+		if test.origin != "" {
+			request.Header.Set("Origin", test.origin)
+		}
+		if test.contentType != "" {
+			request.Header.Set("Content-Type", test.contentType)
+		}
+		response, err := server.Client().Do(request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var document map[string]any
+		_ = json.NewDecoder(response.Body).Decode(&document)
+		response.Body.Close()
+		if response.StatusCode != test.status {
+			t.Errorf("%s = %d %v, want %d", test.name, response.StatusCode, document, test.status)
+		}
+	}
+	tasks := mustList(t, app).Tasks
+	if len(tasks) != 1 {
+		t.Fatalf("refused requests stored %d tasks, want only the same-origin one", len(tasks))
+	}
+}
+
+func TestHTTPServerStreamsChangesAsServerSentEvents(t *testing.T) {
+	app := newTestApplication(t)
+	server := httptest.NewServer(NewHTTPServer(app, "test", ""))
+	defer server.Close()
+
+	response, err := server.Client().Get(server.URL + "/v1/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK || response.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("events = %d %s", response.StatusCode, response.Header.Get("Content-Type"))
+	}
+	lines := make(chan string, 16)
+	go func() {
+		scanner := bufio.NewScanner(response.Body)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		close(lines)
+	}()
+	if line := <-lines; line != ": watching" {
+		t.Fatalf("first line = %q", line)
+	}
+	created, err := app.CreateTask(CreateTaskRequest{Title: "Streamed"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				t.Fatal("event stream ended")
+			}
+			if strings.HasPrefix(line, "data: ") && strings.Contains(line, `"kind":"created"`) {
+				if !strings.Contains(line, `"id":"`+created.ID+`"`) {
+					t.Fatalf("created event = %s", line)
+				}
+				return
+			}
+		case <-timeout:
+			t.Fatal("no created event")
+		}
+	}
+}
+
+func mustJSON(t *testing.T, value any) string {
+	t.Helper()
+	data, err := json.Marshal(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
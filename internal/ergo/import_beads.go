@@ -0,0 +1,413 @@
+// Purpose: Read a beads (bd) issues export and plan it as batch operations.
+// Exports: none; Application.ImportBeads runs the plan.
+// Role: Format adapter. bd issues become tasks, parent-child dependencies
+// become epic membership, blocking dependencies become sequence edges, and bd
+// statuses become lifecycle operations.
+// Invariants: reads only files; the plan is applied by applyBatch, so an import
+// accepts and refuses exactly what the equivalent batch would.
+// Notes: whatever ergo cannot represent is recorded as an ImportNote rather
+// than guessed at. Original IDs and timestamps survive as body trailers.
+package ergo
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// beadsIssueFiles are the export names bd has used, newest first.
+var beadsIssueFiles = []string{"issues.jsonl", "beads.jsonl"}
+
+type beadsIssue struct {
+	ID                 string            `json:"id"`
+	Title              string            `json:"title"`
+	Description        string            `json:"description"`
+	Design             string            `json:"design"`
+	AcceptanceCriteria string            `json:"acceptance_criteria"`
+	Notes              string            `json:"notes"`
+	Status             string            `json:"status"`
+	Priority           *int              `json:"priority"`
+	IssueType          string            `json:"issue_type"`
+	Assignee           string            `json:"assignee"`
+	Labels             []string          `json:"labels"`
+	CreatedAt          string            `json:"created_at"`
+	UpdatedAt          string            `json:"updated_at"`
+	ClosedAt           string            `json:"closed_at"`
+	CloseReason        string            `json:"close_reason"`
+	ExternalRef        string            `json:"external_ref"`
+	Dependencies       []beadsDependency `json:"dependencies"`
+	Comments           []beadsComment    `json:"comments"`
+
+	line     int
+	extra    []string
+	handle   string
+	epic     bool
+	parentID string
+}
+
+type beadsDependency struct {
+	IssueID     string `json:"issue_id"`
+	DependsOnID string `json:"depends_on_id"`
+	Type        string `json:"type"`
+}
+
+type beadsComment struct {
+	Author    string `json:"author"`
+	Text      string `json:"text"`
+	CreatedAt string `json:"created_at"`
+}
+
+// beadsKnownFields are the issue keys the plan reads; any other key with a
+// value is reported as unmapped.
+var beadsKnownFields = map[string]bool{
+	"id": true, "title": true, "description": true, "design": true, "acceptance_criteria": true,
+	"notes": true, "status": true, "priority": true, "issue_type": true, "assignee": true,
+	"labels": true, "created_at": true, "updated_at": true, "closed_at": true, "close_reason": true,
+	"external_ref": true, "dependencies": true, "comments": true,
+}
+
+// findBeadsIssues resolves path to an issues export: the file itself, or the
+// export inside a .beads directory or a project that has one.
+func findBeadsIssues(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", classified(ErrorNotFound, fmt.Errorf("beads path %s: %w", path, err))
+	}
+	if !info.IsDir() {
+		return path, nil
+	}
+	for _, dir := range []string{path, filepath.Join(path, ".beads")} {
+		for _, name := range beadsIssueFiles {
+			candidate := filepath.Join(dir, name)
+			if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+				return candidate, nil
+			}
+		}
+	}
+	return "", classified(ErrorNotFound, fmt.Errorf("no beads issues.jsonl in %s or %s", path, filepath.Join(path, ".beads")))
+}
+
+func readBeadsIssues(path string) ([]*beadsIssue, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, classified(ErrorNotFound, err)
+	}
+	if err != nil {
+		return nil, classifyRepositoryError(err)
+	}
+	var issues []*beadsIssue
+	seen := map[string]int{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxBatchLine)
+	for line := 1; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		var issue beadsIssue
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(text, &issue); err != nil {
+			return nil, classified(ErrorUsage, fmt.Errorf("line %d: invalid beads issue: %w", line, err))
+		}
+		if err := json.Unmarshal(text, &fields); err != nil {
+			return nil, classified(ErrorUsage, fmt.Errorf("line %d: invalid beads issue: %w", line, err))
+		}
+		issue.ID = strings.TrimSpace(issue.ID)
+		if issue.ID == "" {
+			return nil, classified(ErrorUsage, fmt.Errorf("line %d: beads issue has no id", line))
+		}
+		if first, ok := seen[issue.ID]; ok {
+			return nil, classified(ErrorUsage, fmt.Errorf("line %d: issue %s already appears on line %d", line, issue.ID, first))
+		}
+		seen[issue.ID] = line
+		issue.line = line
+		for _, key := range sortedKeys(fields) {
+			if !beadsKnownFields[key] && !emptyJSONValue(fields[key]) {
+				issue.extra = append(issue.extra, key)
+			}
+		}
+		issues = append(issues, &issue)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, classified(ErrorUsage, err)
+	}
+	if len(issues) == 0 {
+		return nil, classified(ErrorUsage, fmt.Errorf("%s has no issues", path))
+	}
+	return issues, nil
+}
+
+func emptyJSONValue(value json.RawMessage) bool {
+	switch string(bytes.TrimSpace(value)) {
+	case "null", `""`, "0", "false", "[]", "{}":
+		return true
+	}
+	return false
+}
+
+// beadsPlan is an import expressed as batch operations, with what could not
+// be carried over.
+type beadsPlan struct {
+	operations   []batchOperation
+	sources      map[string]*beadsIssue
+	byID         map[string]*beadsIssue
+	epics, tasks int
+	dependencies int
+	skipped      int
+	notes        importNotes
+}
+
+// importNotes collects ImportNotes by field and detail, in first-seen order.
+type importNotes struct {
+	notes []ImportNote
+}
+
+func (n *importNotes) add(issueID, field, detail string) {
+	for i := range n.notes {
+		if n.notes[i].Field == field && n.notes[i].Detail == detail {
+			if !slices.Contains(n.notes[i].Issues, issueID) {
+				n.notes[i].Issues = append(n.notes[i].Issues, issueID)
+			}
+			return
+		}
+	}
+	n.notes = append(n.notes, ImportNote{Field: field, Detail: detail, Issues: []string{issueID}})
+}
+
+func planBeadsImport(issues []*beadsIssue) (*beadsPlan, error) {
+	byID := map[string]*beadsIssue{}
+	plan := &beadsPlan{sources: map[string]*beadsIssue{}, byID: byID}
+	var live []*beadsIssue
+	for _, issue := range issues {
+		if issue.Status == "tombstone" {
+			plan.skipped++
+			continue
+		}
+		issue.handle = fmt.Sprintf("bd%d", len(live)+1)
+		byID[issue.ID] = issue
+		live = append(live, issue)
+		plan.sources[issue.handle] = issue
+	}
+	if len(live) == 0 {
+		return nil, classified(ErrorUsage, errors.New("every beads issue is deleted; nothing to import"))
+	}
+
+	for _, issue := range live {
+		for _, dependency := range issue.Dependencies {
+			if dependency.Type != "parent-child" || issue.parentID != "" {
+				continue
+			}
+			if byID[dependency.DependsOnID] == nil {
+				plan.notes.add(issue.ID, "dependencies", "parent-child on an issue that is not imported")
+				continue
+			}
+			issue.parentID = dependency.DependsOnID
+		}
+	}
+	// Ergo epics do not nest, so a child joins the top of its parent chain.
+	for _, issue := range live {
+		if issue.parentID == "" {
+			continue
+		}
+		top := issue.parentID
+		chain := map[string]bool{issue.ID: true}
+		for byID[top].parentID != "" && !chain[top] {
+			chain[top] = true
+			top = byID[top].parentID
+		}
+		if chain[top] {
+			plan.notes.add(issue.ID, "dependencies", "parent-child cycle; imported at the root")
+			issue.parentID = ""
+			continue
+		}
+		if top != issue.parentID {
+			plan.notes.add(issue.ID, "dependencies", "nested parent-child; joined the top-level epic")
+		}
+		issue.parentID = top
+		byID[top].epic = true
+	}
+
+	for _, issue := range live {
+		if issue.epic {
+			plan.add(issue, plan.createOperation(issue))
+		}
+	}
+	for _, issue := range live {
+		if !issue.epic {
+			plan.add(issue, plan.createOperation(issue))
+		}
+	}
+	// planned holds the imported blocks edges by handle, so an edge that would
+	// close a cycle is dropped here instead of refusing the whole import.
+	planned := &Graph{Deps: map[string]map[string]struct{}{}}
+	for _, issue := range live {
+		for _, dependency := range issue.Dependencies {
+			switch dependency.Type {
+			case "parent-child":
+			case "blocks", "":
+				blocker := byID[dependency.DependsOnID]
+				switch {
+				case blocker == nil:
+					plan.notes.add(issue.ID, "dependencies", "blocks an issue that is not imported")
+				case blocker.ID == issue.parentID || issue.ID == blocker.parentID:
+					plan.notes.add(issue.ID, "dependencies", "blocks between an epic and its child")
+				case hasCycle(planned, issue.handle, blocker.handle):
+					plan.notes.add(issue.ID, "dependencies", "blocks cycle; edge not imported")
+				default:
+					if planned.Deps[issue.handle] == nil {
+						planned.Deps[issue.handle] = map[string]struct{}{}
+					}
+					planned.Deps[issue.handle][blocker.handle] = struct{}{}
+					plan.add(issue, batchOperation{Op: "sequence", IDs: []string{"$" + blocker.handle, "$" + issue.handle}})
+					plan.dependencies++
+				}
+			default:
+				plan.notes.add(issue.ID, "dependencies", dependency.Type+" has no ergo equivalent")
+			}
+		}
+	}
+	for _, issue := range live {
+		if operation, ok := plan.lifecycleOperation(issue); ok {
+			plan.add(issue, operation)
+		}
+	}
+
+	declared := map[string]bool{}
+	for i := range plan.operations {
+		operation := &plan.operations[i]
+		if err := operation.validate(declared); err != nil {
+			return nil, classified(ErrorUsage, fmt.Errorf("line %d: %w", operation.line, err))
+		}
+	}
+	return plan, nil
+}
+
+func (plan *beadsPlan) add(issue *beadsIssue, operation batchOperation) {
+	operation.line = issue.line
+	plan.operations = append(plan.operations, operation)
+}
+
+func (plan *beadsPlan) createOperation(issue *beadsIssue) batchOperation {
+	for _, field := range issue.extra {
+		plan.notes.add(issue.ID, field, "no ergo equivalent")
+	}
+	title := strings.TrimSpace(issue.Title)
+	if title == "" {
+		title = issue.ID
+		plan.notes.add(issue.ID, "title", "empty; the issue ID is the title")
+	}
+	body := beadsBody(issue)
+	operation := batchOperation{Op: "create", Handle: issue.handle, Title: title, Body: &body}
+	if issue.epic {
+		plan.epics++
+		if issue.Status != "open" && issue.Status != "" {
+			plan.notes.add(issue.ID, "status", "epics take their state from their children")
+		}
+		if (issue.Priority != nil && *issue.Priority != 2) || len(issue.Labels) > 0 {
+			plan.notes.add(issue.ID, "priority", "epics have no priority or labels")
+		}
+		return operation
+	}
+	plan.tasks++
+	if issue.IssueType == "epic" {
+		plan.notes.add(issue.ID, "issue_type", "epic without children; imported as a task")
+	}
+	if issue.parentID != "" {
+		operation.Epic = "$" + plan.byID[issue.parentID].handle
+	}
+	if issue.Priority != nil {
+		switch level := *issue.Priority; {
+		case level >= 0 && level <= 3:
+			operation.Priority = fmt.Sprintf("P%d", level)
+		case level == 4:
+			operation.Priority = "P3"
+			plan.notes.add(issue.ID, "priority", "4 (backlog) imported as P3")
+		default:
+			plan.notes.add(issue.ID, "priority", fmt.Sprintf("%d is out of range; imported as %s", level, defaultPriority))
+		}
+	}
+	for _, value := range issue.Labels {
+		if label, err := normalizeLabel(value); err == nil {
+			operation.Labels = append(operation.Labels, label)
+		} else {
+			plan.notes.add(issue.ID, "labels", fmt.Sprintf("%q is not a valid ergo label", value))
+		}
+	}
+	operation.Draft = issue.Status == "deferred"
+	return operation
+}
+
+func (plan *beadsPlan) lifecycleOperation(issue *beadsIssue) (batchOperation, bool) {
+	if issue.epic {
+		return batchOperation{}, false
+	}
+	switch issue.Status {
+	case "open", "", "deferred":
+		return batchOperation{}, false
+	case "closed":
+		operation := batchOperation{Op: "done", ID: "$" + issue.handle}
+		if reason := strings.TrimSpace(issue.CloseReason); reason != "" {
+			operation.Message = &reason
+		}
+		return operation, true
+	case "blocked":
+		return batchOperation{Op: "block", ID: "$" + issue.handle}, true
+	case "in_progress":
+		plan.notes.add(issue.ID, "status", "in_progress imported as todo; claims are not imported")
+	default:
+		plan.notes.add(issue.ID, "status", issue.Status+" imported as todo")
+	}
+	return batchOperation{}, false
+}
+
+// beadsBody joins the issue's text fields as Markdown sections and ends with
+// trailers naming where it came from, so `ergo search` finds the original ID.
+func beadsBody(issue *beadsIssue) string {
+	var body strings.Builder
+	section := func(heading, text string) {
+		text = strings.TrimSpace(text)
+		if text == "" {
+			return
+		}
+		if body.Len() > 0 {
+			body.WriteString("\n")
+		}
+		if heading != "" {
+			body.WriteString("## " + heading + "\n\n")
+		}
+		body.WriteString(text + "\n")
+	}
+	section("", issue.Description)
+	section("Design", issue.Design)
+	section("Acceptance criteria", issue.AcceptanceCriteria)
+	section("Notes", issue.Notes)
+	if len(issue.Comments) > 0 {
+		var comments strings.Builder
+		for _, comment := range issue.Comments {
+			fmt.Fprintf(&comments, "- **%s** — %s\n", comment.Author, comment.CreatedAt)
+			for _, line := range strings.Split(strings.TrimSpace(comment.Text), "\n") {
+				comments.WriteString("  " + line + "\n")
+			}
+		}
+		section("Comments", comments.String())
+	}
+	var trailers strings.Builder
+	for _, trailer := range [][2]string{
+		{"Beads-ID", issue.ID}, {"Beads-Type", issue.IssueType}, {"Beads-Assignee", issue.Assignee},
+		{"Beads-External-Ref", issue.ExternalRef}, {"Beads-Created-At", issue.CreatedAt},
+		{"Beads-Updated-At", issue.UpdatedAt}, {"Beads-Closed-At", issue.ClosedAt},
+	} {
+		if value := strings.TrimSpace(trailer[1]); value != "" {
+			fmt.Fprintf(&trailers, "%s: %s\n", trailer[0], value)
+		}
+	}
+	section("", trailers.String())
+	return body.String()
+}
@@ -60,7 +60,7 @@ func validateJournalEntry(entry JournalEntry) error {
 		return errors.New("journal task_id is required")
 	}
 	switch entry.Kind {
-	case "created", "claim", "done", "fail", "block", "cancel", "open", "release", "result":
+	case "created", "claim", "expire", "done", "fail", "block", "cancel", "open", "release", "result", "undo":
 	default:
 		return fmt.Errorf("invalid journal kind %q", entry.Kind)
 	}
@@ -88,6 +88,8 @@ type journalRead struct {
 	validBytes     int64
 	truncatedTail  bool
 	needsSeparator bool
+	// lines holds the physical line of each entry.
+	lines []int
 }
 
 func readJournal(path string) (journalRead, error) {
@@ -131,6 +133,7 @@ func readJournal(path string) (journalRead, error) {
 			return journalRead{}, fmt.Errorf("%s:%d: %w", path, lineNo, err)
 		}
 		result.entries = append(result.entries, entry)
+		result.lines = append(result.lines, lineNo)
 		offset += len(line)
 		if index < len(lines)-1 {
 			offset++
@@ -253,7 +253,7 @@ func TestLifecycleAndResultsWriteOnlyMeaningfulJournalEntries(t *testing.T) {
 	if read.entries[2].Text != "Constraint disproved" || read.entries[2].Agent != "model@host" {
 		t.Fatalf("failed entry = %#v", read.entries[2])
 	}
-	shown, err := app.Show(ShowRequest(created))
+	shown, err := app.Show(ShowRequest{ID: created.ID})
 	if err != nil || shown.Task.State != stateFailed {
 		t.Fatalf("result changed lifecycle: %#v, %v", shown.Task, err)
 	}
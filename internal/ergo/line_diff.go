@@ -0,0 +1,108 @@
+// Purpose: Compute line diffs in memory linear in the input.
+// Exports: none; used by history, edit conflicts, and the edit merge.
+// Role: Myers' O((N+M)D) difference algorithm with the linear-space middle
+// snake, so a body of any size can be diffed without an N×M table.
+// Invariants: hunks are in base order, never touch, and applying them to the
+// base yields the changed text exactly.
+// Notes: each middle-snake search is bounded; past the bound the remaining
+// range becomes one replacing hunk. The result stays correct, only coarser,
+// which for the edit merge means a conflict rather than a wrong merge.
+package ergo
+
+import "slices"
+
+// diffWorkLimit bounds the comparisons one middle-snake search may make.
+const diffWorkLimit = 1 << 24
+
+// textHunk replaces base lines [start, end) with lines.
+type textHunk struct {
+	start, end int
+	lines      []string
+}
+
+// diffHunks returns the changes from base to changed along a shortest edit
+// script, in base order.
+func diffHunks(base, changed []string) []textHunk {
+	var hunks []textHunk
+	diffRange(base, changed, 0, len(base), 0, len(changed), &hunks)
+	return hunks
+}
+
+func diffRange(a, b []string, aLow, aHigh, bLow, bHigh int, hunks *[]textHunk) {
+	for aLow < aHigh && bLow < bHigh && a[aLow] == b[bLow] {
+		aLow++
+		bLow++
+	}
+	for aLow < aHigh && bLow < bHigh && a[aHigh-1] == b[bHigh-1] {
+		aHigh--
+		bHigh--
+	}
+	if aLow == aHigh && bLow == bHigh {
+		return
+	}
+	if aLow < aHigh && bLow < bHigh {
+		if x, y, u, v, ok := middleSnake(a[aLow:aHigh], b[bLow:bHigh]); ok {
+			diffRange(a, b, aLow, aLow+x, bLow, bLow+y, hunks)
+			diffRange(a, b, aLow+u, aHigh, bLow+v, bHigh, hunks)
+			return
+		}
+	}
+	// Hunks from the two sides of an empty snake touch; join them.
+	if last := len(*hunks) - 1; last >= 0 && (*hunks)[last].end == aLow {
+		previous := &(*hunks)[last]
+		previous.end = aHigh
+		previous.lines = append(slices.Clip(previous.lines), b[bLow:bHigh]...)
+		return
+	}
+	*hunks = append(*hunks, textHunk{start: aLow, end: aHigh, lines: b[bLow:bHigh]})
+}
+
+// middleSnake finds the snake, from (x, y) to (u, v), in the middle of a
+// shortest edit script of a into b. Both inputs are non-empty and differ in
+// their first and last lines, so the edit distance is at least two and each
+// side of the snake is a strictly smaller problem. It reports false when the
+// search exceeds diffWorkLimit.
+func middleSnake(a, b []string) (x, y, u, v int, ok bool) {
+	n, m := len(a), len(b)
+	delta := n - m
+	odd := delta%2 != 0
+	limit := min((n+m+1)/2, max(64, diffWorkLimit/(n+m)))
+	offset := limit + 1
+	forward := make([]int, 2*offset+1)
+	backward := make([]int, 2*offset+1)
+	for d := 0; d <= limit; d++ {
+		for k := -d; k <= d; k += 2 {
+			var start int
+			if k == -d || (k != d && forward[offset+k-1] < forward[offset+k+1]) {
+				start = forward[offset+k+1]
+			} else {
+				start = forward[offset+k-1] + 1
+			}
+			end := start
+			for end < n && end-k < m && a[end] == b[end-k] {
+				end++
+			}
+			forward[offset+k] = end
+			if reverse := delta - k; odd && reverse >= -(d-1) && reverse <= d-1 && end+backward[offset+reverse] >= n {
+				return start, start - k, end, end - k, true
+			}
+		}
+		for k := -d; k <= d; k += 2 {
+			var start int
+			if k == -d || (k != d && backward[offset+k-1] < backward[offset+k+1]) {
+				start = backward[offset+k+1]
+			} else {
+				start = backward[offset+k-1] + 1
+			}
+			end := start
+			for end < n && end-k < m && a[n-1-end] == b[m-1-(end-k)] {
+				end++
+			}
+			backward[offset+k] = end
+			if ahead := delta - k; !odd && ahead >= -d && ahead <= d && end+forward[offset+ahead] >= n {
+				return n - end, m - (end - k), n - start, m - (start - k), true
+			}
+		}
+	}
+	return 0, 0, 0, 0, false
+}
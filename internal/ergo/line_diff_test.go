@@ -0,0 +1,70 @@
+package ergo
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestDiffHunksAreMinimalAndReproduceTheChange(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	lines := func() []string {
+		text := make([]string, random.Intn(12))
+		for i := range text {
+			text[i] = string(rune('a' + random.Intn(4)))
+		}
+		return text
+	}
+	for range 2000 {
+		base, changed := lines(), lines()
+		hunks := diffHunks(base, changed)
+		var rebuilt []string
+		position, edits := 0, 0
+		for i, hunk := range hunks {
+			if hunk.start < position || (i > 0 && hunk.start == position) || (hunk.start == hunk.end && len(hunk.lines) == 0) {
+				t.Fatalf("diffHunks(%q, %q) = %v: hunks out of order, touching, or empty", base, changed, hunks)
+			}
+			rebuilt = append(append(rebuilt, base[position:hunk.start]...), hunk.lines...)
+			position = hunk.end
+			edits += hunk.end - hunk.start + len(hunk.lines)
+		}
+		rebuilt = append(rebuilt, base[position:]...)
+		if strings.Join(rebuilt, "\n") != strings.Join(changed, "\n") || len(rebuilt) != len(changed) {
+			t.Fatalf("diffHunks(%q, %q) = %v rebuilds %q", base, changed, hunks, rebuilt)
+		}
+		if want := len(base) + len(changed) - 2*longestCommon(base, changed); edits != want {
+			t.Fatalf("diffHunks(%q, %q) = %v: %d edits, want %d", base, changed, hunks, edits, want)
+		}
+	}
+}
+
+func TestDiffLinesHandlesLargeUnrelatedBodies(t *testing.T) {
+	var before, after strings.Builder
+	for i := range 200000 {
+		fmt.Fprintf(&before, "old line %d\n", i)
+		fmt.Fprintf(&after, "new line %d\n", i)
+	}
+	diff := diffLines(before.String(), after.String())
+	if len(diff) != 400000 || diff[0] != "-old line 0" || diff[len(diff)-1] != "+new line 199999" {
+		t.Fatalf("diff has %d lines, from %q to %q", len(diff), diff[0], diff[len(diff)-1])
+	}
+}
+
+// longestCommon is the quadratic reference the linear-space diff must match.
+func longestCommon(a, b []string) int {
+	common := make([][]int, len(a)+1)
+	for i := range common {
+		common[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else {
+				common[i][j] = max(common[i+1][j], common[i][j+1])
+			}
+		}
+	}
+	return common[0][0]
+}
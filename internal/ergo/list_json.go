@@ -15,12 +15,19 @@ type listJSONDocument struct {
 }
 
 type listJSONItem struct {
-	ID     string `json:"id"`
-	Title  string `json:"title"`
-	Kind   string `json:"kind"`
-	State  string `json:"state,omitempty"`
-	Ready  *bool  `json:"ready,omitempty"`
-	EpicID string `json:"epic_id,omitempty"`
+	ID       string `json:"id"`
+	Title    string `json:"title"`
+	Kind     string `json:"kind"`
+	State    string `json:"state,omitempty"`
+	Ready    *bool  `json:"ready,omitempty"`
+	EpicID   string `json:"epic_id,omitempty"`
+	Revision string `json:"revision"`
+
+	Priority       string   `json:"priority,omitempty"`
+	Labels         []string `json:"labels,omitempty"`
+	ClaimedBy      string   `json:"claimed_by,omitempty"`
+	LeaseExpiresAt string   `json:"lease_expires_at,omitempty"`
+	LeaseExpired   bool     `json:"lease_expired,omitempty"`
 }
 
 // RenderListJSON writes the filtered list outcome without terminal presentation
@@ -42,10 +49,11 @@ func appendNodesAsJSON(items *[]listJSONItem, nodes []*treeNode, graph *Graph) {
 			continue
 		}
 		item := listJSONItem{
-			ID:    node.task.ID,
-			Title: node.task.Title,
-			Kind:  "epic",
-			State: graph.EpicState(node.task.ID),
+			ID:       node.task.ID,
+			Title:    node.task.Title,
+			Kind:     "epic",
+			State:    graph.EpicState(node.task.ID),
+			Revision: graph.Revision(node.task.ID),
 		}
 		if !node.isEpic {
 			ready := node.isReady
@@ -53,6 +61,13 @@ func appendNodesAsJSON(items *[]listJSONItem, nodes []*treeNode, graph *Graph) {
 			item.State = node.task.State
 			item.Ready = &ready
 			item.EpicID = node.task.EpicID
+			item.Priority = effectivePriority(node.task)
+			item.Labels = node.task.Labels
+			item.ClaimedBy = node.task.ClaimedBy
+			if !node.task.LeaseExpiresAt.IsZero() {
+				item.LeaseExpiresAt = formatTime(node.task.LeaseExpiresAt)
+				item.LeaseExpired = graph.LeaseExpired(node.task.ID)
+			}
 		}
 		*items = append(*items, item)
 		appendNodesAsJSON(items, node.children, graph)
@@ -88,43 +88,39 @@ func buildListRoots(graph *Graph, showAll bool, readyOnly bool, epicID string) [
 }
 
 // sortReadyNodesByClaimOrder makes the first visible leaf the task that an
-// automatic claim would select. Each subtree is keyed by its oldest ready leaf.
+// automatic claim would select. Each subtree is keyed by its first ready leaf.
 func sortReadyNodesByClaimOrder(nodes []*treeNode) {
 	for _, node := range nodes {
 		sortReadyNodesByClaimOrder(node.children)
 	}
 	sort.SliceStable(nodes, func(i, j int) bool {
-		left := oldestTaskInTree(nodes[i])
-		right := oldestTaskInTree(nodes[j])
+		left := firstClaimInTree(nodes[i])
+		right := firstClaimInTree(nodes[j])
 		if left == nil || right == nil {
 			return right != nil
 		}
-		if left.CreatedAt.Equal(right.CreatedAt) {
-			return left.ID < right.ID
-		}
-		return left.CreatedAt.Before(right.CreatedAt)
+		return claimsBefore(left, right)
 	})
 }
 
-func oldestTaskInTree(node *treeNode) *Task {
+func firstClaimInTree(node *treeNode) *Task {
 	if node == nil || node.task == nil {
 		return nil
 	}
 	if !node.isEpic {
 		return node.task
 	}
-	var oldest *Task
+	var first *Task
 	for _, child := range node.children {
-		candidate := oldestTaskInTree(child)
+		candidate := firstClaimInTree(child)
 		if candidate == nil {
 			continue
 		}
-		if oldest == nil || candidate.CreatedAt.Before(oldest.CreatedAt) ||
-			(candidate.CreatedAt.Equal(oldest.CreatedAt) && candidate.ID < oldest.ID) {
-			oldest = candidate
+		if first == nil || claimsBefore(candidate, first) {
+			first = candidate
 		}
 	}
-	return oldest
+	return first
 }
 
 func buildEpicTree(graph *Graph, epicID string) *treeNode {
@@ -189,6 +185,40 @@ func filterNodesByReady(nodes []*treeNode, graph *Graph) []*treeNode {
 	return filtered
 }
 
+// filterNodesByLabels keeps matching leaves and epics with matching children.
+// Call this only when a label filter is requested.
+func filterNodesByLabels(nodes []*treeNode, filter labelFilter) []*treeNode {
+	filtered := make([]*treeNode, 0, len(nodes))
+	for _, node := range nodes {
+		if node == nil || node.task == nil {
+			continue
+		}
+		if node.isEpic {
+			node.children = filterNodesByLabels(node.children, filter)
+			if len(node.children) == 0 {
+				continue
+			}
+		} else if !filter.matches(node.task) {
+			continue
+		}
+		filtered = append(filtered, node)
+	}
+	return filtered
+}
+
+func filterTasksByLabels(tasks []*Task, filter labelFilter) []*Task {
+	if !filter.active() {
+		return tasks
+	}
+	var matched []*Task
+	for _, task := range tasks {
+		if filter.matches(task) {
+			matched = append(matched, task)
+		}
+	}
+	return matched
+}
+
 // derivedEpicState computes an epic's state from its child tasks.
 // Returns a derived presentation state for an epic's children.
 func derivedEpicState(children []*treeNode) string {
@@ -124,8 +124,19 @@ func renderNode(w io.Writer, node *treeNode, prefix string, isLast bool, isRoot
 
 	annotations := []string{}
 
+	if !node.isEpic && effectivePriority(task) != defaultPriority {
+		annotations = append(annotations, task.Priority)
+	}
+	if !node.isEpic {
+		for _, label := range task.Labels {
+			annotations = append(annotations, "#"+label)
+		}
+	}
 	if task.ClaimedBy != "" {
 		annotations = append(annotations, "@"+task.ClaimedBy)
+		if graph.LeaseExpired(task.ID) {
+			annotations = append(annotations, "lease expired")
+		}
 	}
 
 	// Blocking info - only show blockers that aren't already shown by parent
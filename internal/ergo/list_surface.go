@@ -10,6 +10,12 @@ type ListOptions struct {
 	ReadyOnly   bool
 	ShowAll     bool
 	OmitJournal bool
+	// Labels and NotLabels keep tasks carrying every Labels entry and no
+	// NotLabels entry; epics remain when any child does.
+	Labels    []string
+	NotLabels []string
+	// At replays the backlog only up to this RFC 3339 timestamp or duration ago.
+	At string
 }
 
 func RunList(listOpts ListOptions, opts GlobalOptions, render RenderOptions) error {
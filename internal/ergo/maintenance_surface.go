@@ -1,6 +1,7 @@
 package ergo
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"strings"
@@ -20,6 +21,62 @@ func RenderCompact(w io.Writer, outcome CompactOutcomeResult) {
 	fmt.Fprintf(w, "Journal: %d retained entries\n", outcome.JournalRecords)
 }
 
+const fsckJSONVersion = 1
+
+type fsckJSONDocument struct {
+	Version        int           `json:"version"`
+	Backlog        string        `json:"backlog"`
+	Journal        string        `json:"journal"`
+	Records        int           `json:"records"`
+	JournalEntries int           `json:"journal_entries"`
+	Errors         int           `json:"errors"`
+	Warnings       int           `json:"warnings"`
+	Problems       []FsckProblem `json:"problems"`
+}
+
+func RenderFsck(w io.Writer, outcome FsckOutcome, useColor bool) {
+	for _, problem := range outcome.Problems {
+		location := problem.File
+		if problem.Line > 0 {
+			location = fmt.Sprintf("%s:%d", problem.File, problem.Line)
+		}
+		fmt.Fprintf(w, "%s: ", location)
+		style := colorYellow
+		if problem.Severity == fsckError {
+			style = colorRed
+		}
+		writeGenerated(w, problem.Severity, style, useColor)
+		fmt.Fprintf(w, " %s: %s", problem.Code, problem.Message)
+		if problem.Repaired {
+			writeGenerated(w, " (repaired)", colorGreen, useColor)
+		}
+		fmt.Fprintln(w)
+	}
+	if len(outcome.Problems) > 0 {
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintf(w, "Checked %s and %s: ", pluralize(outcome.Records, "backlog record", "backlog records"), pluralize(outcome.JournalEntries, "journal entry", "journal entries"))
+	if outcome.Errors() == 0 && outcome.Warnings() == 0 {
+		fmt.Fprintln(w, "no problems")
+		return
+	}
+	fmt.Fprintf(w, "%s, %s\n", pluralize(outcome.Errors(), "error", "errors"), pluralize(outcome.Warnings(), "warning", "warnings"))
+}
+
+func RenderFsckJSON(w io.Writer, outcome FsckOutcome) error {
+	document := fsckJSONDocument{
+		Version: fsckJSONVersion, Backlog: outcome.BacklogPath, Journal: outcome.JournalPath,
+		Records: outcome.Records, JournalEntries: outcome.JournalEntries,
+		Errors: outcome.Errors(), Warnings: outcome.Warnings(), Problems: outcome.Problems,
+	}
+	if document.Problems == nil {
+		document.Problems = []FsckProblem{}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(document)
+}
+
 func RunPrune(confirm bool, opts GlobalOptions, render RenderOptions) error {
 	outcome, err := NewApplication(opts).Prune(PruneRequest{Confirm: confirm})
 	if err != nil {
@@ -0,0 +1,396 @@
+// Purpose: Serve the backlog as Model Context Protocol tools over stdio.
+// Exports: MCPServer, NewMCPServer, and MCPServer.Serve.
+// Role: Translate JSON-RPC tool calls into Application requests and outcomes.
+// Invariants: every tool call is one Application call, so each takes and
+// releases the repository lock exactly as the equivalent CLI command does.
+// Invariants: input schemas are derived from the request structs, and
+// application failures return their ErrorKind instead of a protocol error.
+// Notes: messages are newline-delimited JSON-RPC 2.0, the MCP stdio transport.
+package ergo
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const mcpProtocolVersion = "2025-06-18"
+
+// JSON-RPC 2.0 error codes.
+const (
+	rpcParseError     = -32700
+	rpcInvalidRequest = -32600
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+)
+
+// MCPServer answers MCP requests with one Application.
+type MCPServer struct {
+	app     *Application
+	version string
+	tools   []mcpTool
+}
+
+type mcpTool struct {
+	name        string
+	description string
+	schema      map[string]any
+	call        func(*Application, json.RawMessage) (any, error)
+}
+
+type rpcMessage struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string { return e.Message }
+
+// mcpField describes one request field exposed as a tool argument.
+type mcpField struct {
+	description string
+	required    bool
+	enum        []string
+}
+
+func NewMCPServer(app *Application, version string) *MCPServer {
+	return &MCPServer{app: app, version: version, tools: []mcpTool{
+		newMCPTool("create_task", "Create a task and return its ID.", map[string]mcpField{
+			"Title":    {description: "Task title", required: true},
+			"EpicID":   {description: "Epic to create the task in"},
+			"Body":     {description: "Task body"},
+			"Draft":    {description: "Create as draft so it cannot be claimed yet"},
+			"Priority": {description: "Priority from P0 (most urgent) to P3", enum: []string{"P0", "P1", "P2", "P3"}},
+			"Labels":   {description: "Labels to attach"},
+		}, func(app *Application, request CreateTaskRequest) (any, error) {
+			outcome, err := app.CreateTask(request)
+			return map[string]string{"id": outcome.ID}, err
+		}),
+		newMCPTool("list", "List tasks as the versioned list JSON document.", map[string]mcpField{
+			"EpicID":    {description: "Only this epic and its children"},
+			"ReadyOnly": {description: "Only ready tasks; conflicts with show_all"},
+			"ShowAll":   {description: "Include done and canceled tasks"},
+			"Labels":    {description: "Keep tasks carrying every label"},
+			"NotLabels": {description: "Drop tasks carrying any label"},
+			"At":        {description: "Replay the backlog up to an RFC 3339 time or a duration ago"},
+		}, func(app *Application, request ListRequest) (any, error) {
+			request.OmitJournal = true
+			outcome, err := app.List(request)
+			if err != nil {
+				return nil, err
+			}
+			var document bytes.Buffer
+			if err := RenderListJSON(&document, outcome); err != nil {
+				return nil, err
+			}
+			return json.RawMessage(bytes.TrimSpace(document.Bytes())), nil
+		}),
+		newMCPTool("show", "Show one task or epic with its journal.", map[string]mcpField{
+			"ID": {description: "Task or epic ID", required: true},
+			"At": {description: "Show the task as it stood at an RFC 3339 time or a duration ago"},
+		}, func(app *Application, request ShowRequest) (any, error) {
+			outcome, err := app.Show(request)
+			if err != nil {
+				return nil, err
+			}
+			document := newTaskJSONDocument(outcome.Graph, outcome.Task)
+			for _, child := range outcome.Children {
+				document.Children = append(document.Children, child.ID)
+			}
+			document.Journal = journalForTask(outcome.Journal, outcome.Task.ID)
+			return document, nil
+		}),
+		newMCPTool("claim", "Claim a task, or the most urgent ready task (highest priority, then oldest) when id is omitted.", map[string]mcpField{
+			"ID":         {description: "Task to claim; omit to claim ready work"},
+			"AgentID":    {description: "Claim identity, such as model@host", required: true},
+			"Lease":      {description: "Release the claim unless renewed within this duration, such as 30m"},
+			"Labels":     {description: "Claim only ready tasks carrying every label"},
+			"IfRevision": {description: "Refuse unless the task is still at this revision"},
+		}, func(app *Application, request ClaimRequest) (any, error) {
+			outcome, err := app.Claim(request)
+			if err != nil || outcome.Task == nil {
+				return map[string]any{"task": nil}, err
+			}
+			return map[string]any{"task": newTaskJSONDocument(outcome.Graph, outcome.Task)}, nil
+		}),
+		newMCPTool("lifecycle", "Move a task to done, fail, block, cancel, or open.", map[string]mcpField{
+			"Kind":       {description: "Lifecycle verb", required: true, enum: []string{"done", "fail", "block", "cancel", "open"}},
+			"ID":         {description: "Task ID", required: true},
+			"Messages":   {description: "Lifecycle messages for the journal"},
+			"IfRevision": {description: "Refuse unless the task is still at this revision"},
+		}, func(app *Application, request LifecycleRequest) (any, error) {
+			outcome, err := app.Lifecycle(request)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]any{"task": newTaskJSONDocument(outcome.Graph, outcome.Task), "changed": outcome.ChangedFields}, nil
+		}),
+		newMCPTool("result", "Record a result without changing task state.", map[string]mcpField{
+			"ID":         {description: "Task ID", required: true},
+			"Text":       {description: "Single-line result summary", required: true},
+			"FilePath":   {description: "Project file that evidences the result"},
+			"IfRevision": {description: "Refuse unless the task is still at this revision"},
+		}, func(app *Application, request ResultRequest) (any, error) {
+			request.FileSet = request.FilePath != ""
+			outcome, err := app.Result(request)
+			return map[string]string{"task_id": outcome.TaskID, "text": outcome.Text, "file_path": outcome.FilePath}, err
+		}),
+		newMCPTool("sequence", "Require tasks in order: each ID depends on the one before it.", map[string]mcpField{
+			"IDs":         {description: "Two or more task IDs in order", required: true},
+			"IfRevisions": {description: "Refuse unless each named task is still at its revision, keyed by task ID"},
+		}, func(app *Application, request SequenceRequest) (any, error) {
+			request.Command, request.EventType = "sequence", eventLink
+			outcome, err := app.Sequence(request)
+			edges := make([]map[string]string, 0, len(outcome.Edges))
+			for _, edge := range outcome.Edges {
+				edges = append(edges, map[string]string{"from_id": edge.FromID, "to_id": edge.ToID})
+			}
+			return map[string]any{"added": edges}, err
+		}),
+		newMCPTool("move", "Move a task into an epic or to the root.", map[string]mcpField{
+			"ID":            {description: "Task ID", required: true},
+			"DestinationID": {description: "Destination epic"},
+			"ToRoot":        {description: "Move the task to the root instead"},
+			"IfRevision":    {description: "Refuse unless the task is still at this revision"},
+		}, func(app *Application, request MoveRequest) (any, error) {
+			outcome, err := app.Move(request)
+			return map[string]any{"id": outcome.ID, "destination_id": outcome.DestinationID, "changed": outcome.Changed}, err
+		}),
+	}}
+}
+
+// newMCPTool derives the tool's input schema from R's exposed fields and
+// decodes arguments into R before calling the application.
+func newMCPTool[R any](name, description string, fields map[string]mcpField, call func(*Application, R) (any, error)) mcpTool {
+	requestType := reflect.TypeFor[R]()
+	properties := map[string]any{}
+	required := []string{}
+	for index := range requestType.NumField() {
+		field := requestType.Field(index)
+		exposed, ok := fields[field.Name]
+		if !ok {
+			continue
+		}
+		property := mcpPropertySchema(field.Type)
+		property["description"] = exposed.description
+		if len(exposed.enum) > 0 {
+			property["enum"] = exposed.enum
+		}
+		properties[mcpArgumentName(field.Name)] = property
+		if exposed.required {
+			required = append(required, mcpArgumentName(field.Name))
+		}
+	}
+	if len(properties) != len(fields) {
+		panic(fmt.Sprintf("mcp tool %s exposes fields missing from %s", name, requestType))
+	}
+	schema := map[string]any{"type": "object", "properties": properties, "required": required, "additionalProperties": false}
+	return mcpTool{name: name, description: description, schema: schema, call: func(app *Application, arguments json.RawMessage) (any, error) {
+		var request R
+		if err := decodeMCPArguments(arguments, fields, reflect.ValueOf(&request).Elem()); err != nil {
+			return nil, classified(ErrorUsage, err)
+		}
+		return call(app, request)
+	}}
+}
+
+var durationType = reflect.TypeFor[time.Duration]()
+
+func mcpPropertySchema(fieldType reflect.Type) map[string]any {
+	switch {
+	case fieldType == durationType:
+		return map[string]any{"type": "string", "format": "duration"}
+	case fieldType.Kind() == reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case fieldType.Kind() == reflect.Slice:
+		return map[string]any{"type": "array", "items": mcpPropertySchema(fieldType.Elem())}
+	case fieldType.Kind() == reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": mcpPropertySchema(fieldType.Elem())}
+	default:
+		return map[string]any{"type": "string"}
+	}
+}
+
+// mcpArgumentName converts a Go field name to snake case, keeping initialisms
+// together: AgentID becomes agent_id and IDs becomes ids.
+func mcpArgumentName(field string) string {
+	var name strings.Builder
+	previousLower := false
+	for _, r := range field {
+		if unicode.IsUpper(r) && previousLower {
+			name.WriteByte('_')
+		}
+		previousLower = unicode.IsLower(r)
+		name.WriteRune(unicode.ToLower(r))
+	}
+	return name.String()
+}
+
+func decodeMCPArguments(arguments json.RawMessage, fields map[string]mcpField, request reflect.Value) error {
+	values := map[string]json.RawMessage{}
+	if len(bytes.TrimSpace(arguments)) > 0 {
+		if err := json.Unmarshal(arguments, &values); err != nil {
+			return fmt.Errorf("arguments must be an object: %w", err)
+		}
+	}
+	byArgument := map[string]reflect.Value{}
+	for index := range request.NumField() {
+		field := request.Type().Field(index)
+		if _, ok := fields[field.Name]; ok {
+			byArgument[mcpArgumentName(field.Name)] = request.Field(index)
+		}
+	}
+	for name, raw := range values {
+		target, ok := byArgument[name]
+		if !ok {
+			return fmt.Errorf("unknown argument %q", name)
+		}
+		if target.Type() == durationType {
+			var text string
+			if err := json.Unmarshal(raw, &text); err != nil {
+				return fmt.Errorf("argument %q must be a duration string such as 30m", name)
+			}
+			duration, err := time.ParseDuration(text)
+			if err != nil {
+				return fmt.Errorf("argument %q: %w", name, err)
+			}
+			target.SetInt(int64(duration))
+			continue
+		}
+		if err := json.Unmarshal(raw, target.Addr().Interface()); err != nil {
+			return fmt.Errorf("argument %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// Serve answers requests read from r until it reaches EOF.
+func (s *MCPServer) Serve(r io.Reader, w io.Writer) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLogRecordBytes)
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if response := s.handle(line); response != nil {
+			if err := encoder.Encode(response); err != nil {
+				return err
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+// handle returns the response to one message, or nil for notifications.
+func (s *MCPServer) handle(line []byte) *rpcMessage {
+	var request rpcMessage
+	if err := json.Unmarshal(line, &request); err != nil {
+		return &rpcMessage{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &rpcError{Code: rpcParseError, Message: err.Error()}}
+	}
+	if request.ID == nil {
+		return nil
+	}
+	response := &rpcMessage{JSONRPC: "2.0", ID: request.ID}
+	if request.JSONRPC != "2.0" || request.Method == "" {
+		response.Error = &rpcError{Code: rpcInvalidRequest, Message: "expected a JSON-RPC 2.0 request"}
+		return response
+	}
+	result, err := s.dispatch(request.Method, request.Params)
+	if err != nil {
+		var protocolError *rpcError
+		if !errors.As(err, &protocolError) {
+			protocolError = &rpcError{Code: rpcInvalidParams, Message: err.Error()}
+		}
+		response.Error = protocolError
+		return response
+	}
+	response.Result = result
+	return response
+}
+
+func (s *MCPServer) dispatch(method string, params json.RawMessage) (any, error) {
+	switch method {
+	case "initialize":
+		var request struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		}
+		if len(params) > 0 {
+			if err := json.Unmarshal(params, &request); err != nil {
+				return nil, err
+			}
+		}
+		version := request.ProtocolVersion
+		if version == "" || version > mcpProtocolVersion {
+			version = mcpProtocolVersion
+		}
+		return map[string]any{
+			"protocolVersion": version,
+			"capabilities":    map[string]any{"tools": map[string]any{}},
+			"serverInfo":      map[string]string{"name": "ergo", "version": s.version},
+		}, nil
+	case "ping":
+		return map[string]any{}, nil
+	case "tools/list":
+		tools := make([]map[string]any, 0, len(s.tools))
+		for _, tool := range s.tools {
+			tools = append(tools, map[string]any{"name": tool.name, "description": tool.description, "inputSchema": tool.schema})
+		}
+		return map[string]any{"tools": tools}, nil
+	case "tools/call":
+		var request struct {
+			Name      string          `json:"name"`
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		if err := json.Unmarshal(params, &request); err != nil {
+			return nil, err
+		}
+		index := slices.IndexFunc(s.tools, func(tool mcpTool) bool { return tool.name == request.Name })
+		if index < 0 {
+			return nil, &rpcError{Code: rpcInvalidParams, Message: fmt.Sprintf("unknown tool %q", request.Name)}
+		}
+		return mcpToolResult(s.tools[index].call(s.app, request.Arguments))
+	default:
+		return nil, &rpcError{Code: rpcMethodNotFound, Message: fmt.Sprintf("method %q not found", method)}
+	}
+}
+
+// mcpToolResult reports application failures inside the result, with their
+// ErrorKind, so agents can tell a conflict from a missing task.
+func mcpToolResult(value any, err error) (any, error) {
+	isError := err != nil
+	if isError {
+		kind, ok := ApplicationErrorKind(err)
+		if !ok {
+			kind = ErrorInternal
+		}
+		value = map[string]any{"error": map[string]string{"kind": string(kind), "message": err.Error()}}
+	}
+	text, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{
+		"content":           []map[string]string{{"type": "text", "text": string(text)}},
+		"structuredContent": json.RawMessage(text),
+		"isError":           isError,
+	}, nil
+}
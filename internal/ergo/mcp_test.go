@@ -0,0 +1,188 @@
+package ergo
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"testing"
+)
+
+// mcpTestClient drives an MCPServer in process over pipes.
+type mcpTestClient struct {
+	t      *testing.T
+	writer *io.PipeWriter
+	reader *bufio.Scanner
+	nextID int
+	done   chan error
+}
+
+func newMCPTestClient(t *testing.T, app *Application) *mcpTestClient {
+	t.Helper()
+	requests, requestWriter := io.Pipe()
+	responseReader, responses := io.Pipe()
+	client := &mcpTestClient{t: t, writer: requestWriter, reader: bufio.NewScanner(responseReader), done: make(chan error, 1)}
+	go func() {
+		err := NewMCPServer(app, "test").Serve(requests, responses)
+		responses.Close()
+		client.done <- err
+	}()
+	t.Cleanup(func() {
+		requestWriter.Close()
+		if err := <-client.done; err != nil {
+			t.Errorf("Serve: %v", err)
+		}
+	})
+	return client
+}
+
+// call sends one request and decodes the response's result or error.
+func (c *mcpTestClient) call(method string, params any) (json.RawMessage, *rpcError) {
+	c.t.Helper()
+	c.nextID++
+	data, err := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": c.nextID, "method": method, "params": params})
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	if _, err := c.writer.Write(append(data, '\n')); err != nil {
+		c.t.Fatal(err)
+	}
+	if !c.reader.Scan() {
+		c.t.Fatalf("no response to %s: %v", method, c.reader.Err())
+	}
+	var response struct {
+		ID     int             `json:"id"`
+		Result json.RawMessage `json:"result"`
+		Error  *rpcError       `json:"error"`
+	}
+	if err := json.Unmarshal(c.reader.Bytes(), &response); err != nil {
+		c.t.Fatal(err)
+	}
+	if response.ID != c.nextID {
+		c.t.Fatalf("response id = %d, want %d", response.ID, c.nextID)
+	}
+	return response.Result, response.Error
+}
+
+// tool calls a tool and decodes its structured content into out.
+func (c *mcpTestClient) tool(name string, arguments map[string]any, out any) bool {
+	c.t.Helper()
+	result, protocolError := c.call("tools/call", map[string]any{"name": name, "arguments": arguments})
+	if protocolError != nil {
+		c.t.Fatalf("%s: protocol error %v", name, protocolError)
+	}
+	var decoded struct {
+		StructuredContent json.RawMessage `json:"structuredContent"`
+		IsError           bool            `json:"isError"`
+	}
+	if err := json.Unmarshal(result, &decoded); err != nil {
+		c.t.Fatal(err)
+	}
+	if err := json.Unmarshal(decoded.StructuredContent, out); err != nil {
+		c.t.Fatal(err)
+	}
+	return !decoded.IsError
+}
+
+func TestMCPToolsDriveTheBacklog(t *testing.T) {
+	app := newTestApplication(t)
+	client := newMCPTestClient(t, app)
+	if _, protocolError := client.call("initialize", map[string]any{"protocolVersion": mcpProtocolVersion}); protocolError != nil {
+		t.Fatal(protocolError)
+	}
+	result, _ := client.call("tools/list", nil)
+	var listed struct {
+		Tools []struct {
+			Name        string `json:"name"`
+			InputSchema struct {
+				Properties map[string]struct {
+					Type string `json:"type"`
+				} `json:"properties"`
+				Required []string `json:"required"`
+			} `json:"inputSchema"`
+		} `json:"tools"`
+	}
+	if err := json.Unmarshal(result, &listed); err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, tool := range listed.Tools {
+		names = append(names, tool.Name)
+	}
+	if !equalStrings(names, []string{"create_task", "list", "show", "claim", "lifecycle", "result", "sequence", "move"}) {
+		t.Fatalf("tools = %v", names)
+	}
+	claimSchema := listed.Tools[3].InputSchema
+	if claimSchema.Properties["agent_id"].Type != "string" || claimSchema.Properties["labels"].Type != "array" ||
+		!equalStrings(claimSchema.Required, []string{"agent_id"}) {
+		t.Fatalf("claim schema = %+v", claimSchema)
+	}
+
+	var first, second struct {
+		ID string `json:"id"`
+	}
+	if !client.tool("create_task", map[string]any{"title": "First", "priority": "P1"}, &first) ||
+		!client.tool("create_task", map[string]any{"title": "Second", "labels": []string{"backend"}}, &second) {
+		t.Fatal("create_task failed")
+	}
+	var sequenced map[string]any
+	if !client.tool("sequence", map[string]any{"ids": []string{first.ID, second.ID}}, &sequenced) {
+		t.Fatalf("sequence failed: %v", sequenced)
+	}
+	var claimed struct {
+		Task struct {
+			ID        string `json:"id"`
+			State     string `json:"state"`
+			ClaimedBy string `json:"claimed_by"`
+		} `json:"task"`
+	}
+	if !client.tool("claim", map[string]any{"agent_id": "mcp-agent", "lease": "30m"}, &claimed) ||
+		claimed.Task.ID != first.ID || claimed.Task.State != stateDoing {
+		t.Fatalf("claim = %+v", claimed)
+	}
+	var finished map[string]any
+	if !client.tool("lifecycle", map[string]any{"kind": "done", "id": first.ID, "messages": []string{"Shipped"}}, &finished) {
+		t.Fatalf("lifecycle failed: %v", finished)
+	}
+	var shown struct {
+		State     string         `json:"state"`
+		DependsOn []string       `json:"depends_on"`
+		Journal   []JournalEntry `json:"journal"`
+	}
+	if !client.tool("show", map[string]any{"id": second.ID}, &shown) ||
+		!equalStrings(shown.DependsOn, []string{first.ID}) || len(shown.Journal) != 1 {
+		t.Fatalf("show = %+v", shown)
+	}
+	var document struct {
+		Version int `json:"version"`
+		Items   []struct {
+			ID    string `json:"id"`
+			Ready bool   `json:"ready"`
+		} `json:"items"`
+	}
+	if !client.tool("list", map[string]any{"ready_only": true}, &document) ||
+		document.Version != listJSONVersion || len(document.Items) != 1 || document.Items[0].ID != second.ID {
+		t.Fatalf("list = %+v", document)
+	}
+}
+
+func TestMCPReportsApplicationAndProtocolErrors(t *testing.T) {
+	client := newMCPTestClient(t, newTestApplication(t))
+	var failure struct {
+		Error struct {
+			Kind    string `json:"kind"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if client.tool("show", map[string]any{"id": "ZZZZZZ"}, &failure) || failure.Error.Kind != string(ErrorNotFound) {
+		t.Fatalf("show unknown = %+v", failure)
+	}
+	if client.tool("claim", map[string]any{"agent": "typo"}, &failure) || failure.Error.Kind != string(ErrorUsage) {
+		t.Fatalf("unknown argument = %+v", failure)
+	}
+	if _, protocolError := client.call("tools/call", map[string]any{"name": "prune"}); protocolError == nil || protocolError.Code != rpcInvalidParams {
+		t.Fatalf("unknown tool error = %+v", protocolError)
+	}
+	if _, protocolError := client.call("resources/list", nil); protocolError == nil || protocolError.Code != rpcMethodNotFound {
+		t.Fatalf("unknown method error = %+v", protocolError)
+	}
+}
@@ -0,0 +1,344 @@
+// Purpose: Merge divergent backlog and journal files as a git merge driver.
+// Exports: MergeDriverRequest, MergeDriverOutcome, and Application.MergeDriver.
+// Role: Union records appended on each branch and prove the union replays.
+// Invariants: ours is rewritten only when the union has no semantic conflict.
+// Invariants: base records keep their bytes and order; appended records follow
+// in timestamp order, ours first on ties, with byte-identical records kept once.
+package ergo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	gitMergeDriverName    = "ergo"
+	gitMergeDriverCommand = "ergo merge-driver %O %A %B"
+	gitAttributesFileName = ".gitattributes"
+)
+
+// Merge sides in tie-break order.
+const (
+	mergeSideOurs   = "ours"
+	mergeSideTheirs = "theirs"
+)
+
+// MergeDriverRequest names git's %O, %A, and %B files. Ours receives the
+// merged result.
+type MergeDriverRequest struct {
+	Base, Ours, Theirs string
+}
+
+type MergeDriverOutcome struct {
+	Path string
+	// File is "backlog" or "journal", detected from the records themselves.
+	File         string
+	Ours, Theirs int
+	Conflicts    []string
+}
+
+type mergeRecord struct {
+	side   string
+	raw    []byte
+	at     string
+	events []Event
+}
+
+func (a *Application) MergeDriver(request MergeDriverRequest) (MergeDriverOutcome, error) {
+	if request.Base == "" || request.Ours == "" || request.Theirs == "" {
+		return MergeDriverOutcome{}, classified(ErrorUsage, errors.New("usage: ergo merge-driver <base> <ours> <theirs>"))
+	}
+	sides := map[string]string{"base": request.Base, mergeSideOurs: request.Ours, mergeSideTheirs: request.Theirs}
+	records := map[string][][]byte{}
+	for side, path := range sides {
+		lines, err := readMergeRecords(path)
+		if err != nil {
+			return MergeDriverOutcome{}, classifyRepositoryError(err)
+		}
+		records[side] = lines
+	}
+	outcome := MergeDriverOutcome{Path: request.Ours, File: mergeFileKind(records)}
+	for _, side := range []string{mergeSideOurs, mergeSideTheirs} {
+		if !hasRecordPrefix(records[side], records["base"]) {
+			outcome.Conflicts = append(outcome.Conflicts,
+				fmt.Sprintf("%s rewrote records shared with the merge base; run `ergo compact` only after merging", side))
+		}
+	}
+	if len(outcome.Conflicts) > 0 {
+		return outcome, mergeConflictError(outcome)
+	}
+
+	var merged []byte
+	var err error
+	if outcome.File == "journal" {
+		merged, err = mergeJournalRecords(records, &outcome)
+	} else {
+		merged, err = mergeBacklogRecords(request.Base, records, &outcome)
+	}
+	if err != nil {
+		return outcome, classified(ErrorInternal, err)
+	}
+	if len(outcome.Conflicts) > 0 {
+		return outcome, mergeConflictError(outcome)
+	}
+	if err := replaceLogAtomically(request.Ours, merged); err != nil {
+		return outcome, classifyRepositoryError(err)
+	}
+	return outcome, nil
+}
+
+func mergeConflictError(outcome MergeDriverOutcome) error {
+	return classified(ErrorConflict, fmt.Errorf("cannot merge %s; ours left unchanged:\n  %s",
+		outcome.File, strings.Join(outcome.Conflicts, "\n  ")))
+}
+
+func mergeBacklogRecords(basePath string, records map[string][][]byte, outcome *MergeDriverOutcome) ([]byte, error) {
+	read, err := inspectEventLog(basePath)
+	if err != nil {
+		return nil, err
+	}
+	graph := newGraph()
+	if read.snapshot != nil {
+		graph = read.snapshot
+	}
+	if graph, err = replayEventsOnto(graph, read.events); err != nil {
+		return nil, fmt.Errorf("merge base does not replay: %w", err)
+	}
+
+	var appended []mergeRecord
+	for _, side := range []string{mergeSideOurs, mergeSideTheirs} {
+		base := len(records["base"])
+		for index, raw := range records[side][base:] {
+			var header struct {
+				Type string `json:"type"`
+			}
+			if err := json.Unmarshal(raw, &header); err == nil && snapshotKind(header.Type) {
+				outcome.Conflicts = append(outcome.Conflicts, fmt.Sprintf("%s appended a snapshot record; run `ergo compact` only after merging", side))
+				continue
+			}
+			events, err := decodeEventLogRecord(side, base+index+1, raw)
+			if err != nil {
+				outcome.Conflicts = append(outcome.Conflicts, err.Error())
+				continue
+			}
+			record := mergeRecord{side: side, raw: raw, events: events}
+			if len(events) > 0 {
+				record.at = events[0].TS
+			}
+			appended = append(appended, record)
+		}
+	}
+	if len(outcome.Conflicts) > 0 {
+		return nil, nil
+	}
+	appended = unionMergeRecords(appended, outcome)
+	outcome.Conflicts = append(outcome.Conflicts, divergentBacklogEdits(appended)...)
+
+	var events []Event
+	for _, record := range appended {
+		events = append(events, record.events...)
+	}
+	if _, err := replayEventsOnto(graph, events); err != nil {
+		outcome.Conflicts = append(outcome.Conflicts, err.Error())
+	}
+	return joinMergeRecords(records["base"], appended), nil
+}
+
+func mergeJournalRecords(records map[string][][]byte, outcome *MergeDriverOutcome) ([]byte, error) {
+	var appended []mergeRecord
+	for _, side := range []string{mergeSideOurs, mergeSideTheirs} {
+		base := len(records["base"])
+		for index, raw := range records[side][base:] {
+			var entry JournalEntry
+			if err := json.Unmarshal(raw, &entry); err != nil {
+				outcome.Conflicts = append(outcome.Conflicts, fmt.Sprintf("%s:%d: invalid JSON in journal: %v", side, base+index+1, err))
+				continue
+			}
+			if err := validateJournalEntry(entry); err != nil {
+				outcome.Conflicts = append(outcome.Conflicts, fmt.Sprintf("%s:%d: %v", side, base+index+1, err))
+				continue
+			}
+			appended = append(appended, mergeRecord{side: side, raw: raw, at: entry.At})
+		}
+	}
+	if len(outcome.Conflicts) > 0 {
+		return nil, nil
+	}
+	return joinMergeRecords(records["base"], unionMergeRecords(appended, outcome)), nil
+}
+
+// unionMergeRecords drops byte-identical duplicates, counts each side's
+// surviving records, and orders the union by timestamp.
+func unionMergeRecords(appended []mergeRecord, outcome *MergeDriverOutcome) []mergeRecord {
+	seen := map[string]struct{}{}
+	union := make([]mergeRecord, 0, len(appended))
+	for _, record := range appended {
+		if _, duplicate := seen[string(record.raw)]; duplicate {
+			continue
+		}
+		seen[string(record.raw)] = struct{}{}
+		union = append(union, record)
+		if record.side == mergeSideOurs {
+			outcome.Ours++
+		} else {
+			outcome.Theirs++
+		}
+	}
+	sort.SliceStable(union, func(i, j int) bool {
+		left, leftErr := parseTime(union[i].at)
+		right, rightErr := parseTime(union[j].at)
+		if leftErr != nil || rightErr != nil {
+			return union[i].at < union[j].at
+		}
+		return left.Before(right)
+	})
+	return union
+}
+
+// divergentBacklogEdits reports edits replay would silently resolve by order:
+// both branches claiming one task for different agents, or retitling it
+// differently.
+func divergentBacklogEdits(appended []mergeRecord) []string {
+	claims := map[string]map[string]string{mergeSideOurs: {}, mergeSideTheirs: {}}
+	titles := map[string]map[string]string{mergeSideOurs: {}, mergeSideTheirs: {}}
+	for _, record := range appended {
+		for index, event := range record.events {
+			decoded, err := decodeEvent(event, index)
+			if err != nil {
+				continue
+			}
+			switch data := decoded.payload.(type) {
+			case ClaimEvent:
+				claims[record.side][data.ID] = data.AgentID
+			case TitleUpdateEvent:
+				titles[record.side][data.ID] = data.Title
+			}
+		}
+	}
+	var conflicts []string
+	for id, ours := range claims[mergeSideOurs] {
+		if theirs, ok := claims[mergeSideTheirs][id]; ok && theirs != ours {
+			conflicts = append(conflicts, fmt.Sprintf("%s claimed on both branches: %s (ours) and %s (theirs)", id, ours, theirs))
+		}
+	}
+	for id, ours := range titles[mergeSideOurs] {
+		if theirs, ok := titles[mergeSideTheirs][id]; ok && theirs != ours {
+			conflicts = append(conflicts, fmt.Sprintf("%s retitled on both branches: %q (ours) and %q (theirs)", id, ours, theirs))
+		}
+	}
+	sort.Strings(conflicts)
+	return conflicts
+}
+
+func joinMergeRecords(base [][]byte, appended []mergeRecord) []byte {
+	var output bytes.Buffer
+	for _, raw := range base {
+		output.Write(raw)
+		output.WriteByte('\n')
+	}
+	for _, record := range appended {
+		output.Write(record.raw)
+		output.WriteByte('\n')
+	}
+	return output.Bytes()
+}
+
+// readMergeRecords returns the non-blank physical records of one merge input.
+func readMergeRecords(path string) ([][]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var records [][]byte
+	for _, line := range bytes.Split(data, []byte{'\n'}) {
+		if trimmed := bytes.TrimSpace(line); len(trimmed) > 0 {
+			records = append(records, trimmed)
+		}
+	}
+	return records, nil
+}
+
+func hasRecordPrefix(records, prefix [][]byte) bool {
+	if len(prefix) > len(records) {
+		return false
+	}
+	for index := range prefix {
+		if !bytes.Equal(records[index], prefix[index]) {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeFileKind tells journal entries, which carry task_id and no type, from
+// backlog records. git passes temporary paths, so the name cannot decide.
+func mergeFileKind(records map[string][][]byte) string {
+	for _, side := range []string{"base", mergeSideOurs, mergeSideTheirs} {
+		if len(records[side]) == 0 {
+			continue
+		}
+		var header struct {
+			Type   string `json:"type"`
+			TaskID string `json:"task_id"`
+		}
+		if err := json.Unmarshal(records[side][0], &header); err == nil && header.Type == "" && header.TaskID != "" {
+			return "journal"
+		}
+		return "backlog"
+	}
+	return "backlog"
+}
+
+// configureGitMerge routes the backlog and journal through the merge driver.
+// .gitattributes travels with the repository; the driver definition lives in
+// local git config, so each clone configures it once.
+func configureGitMerge(projectDir string, outcome *InitializeOutcome) error {
+	eventsPath, err := selectEventsPath(filepath.Join(projectDir, dataDirName))
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(projectDir, gitAttributesFileName)
+	existing, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	lines := strings.Split(string(existing), "\n")
+	var missing []string
+	for _, name := range []string{filepath.Base(eventsPath), journalFileName} {
+		entry := dataDirName + "/" + name + " merge=" + gitMergeDriverName
+		if !containsString(lines, entry) {
+			missing = append(missing, entry)
+		}
+	}
+	if len(missing) > 0 {
+		data := existing
+		if len(data) > 0 && data[len(data)-1] != '\n' {
+			data = append(data, '\n')
+		}
+		data = append(data, strings.Join(missing, "\n")+"\n"...)
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return err
+		}
+	}
+	if outcome.GitAttributes, err = filepath.Abs(path); err != nil {
+		return err
+	}
+	outcome.GitConfigured = setGitConfig(projectDir, "merge."+gitMergeDriverName+".name", "Ergo backlog merge") &&
+		setGitConfig(projectDir, "merge."+gitMergeDriverName+".driver", gitMergeDriverCommand)
+	return nil
+}
+
+func setGitConfig(projectDir, key, value string) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	return exec.CommandContext(ctx, "git", "-C", projectDir, "config", "--local", key, value).Run() == nil
+}
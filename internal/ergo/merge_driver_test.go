@@ -0,0 +1,232 @@
+package ergo
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// branchApplication copies a repository's .ergo directory, standing in for a
+// git branch that diverges from it.
+func branchApplication(t *testing.T, base *Application) *Application {
+	t.Helper()
+	dir := t.TempDir()
+	if _, err := InitializeRepository(dir); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"backlog.jsonl", journalFileName} {
+		data, err := os.ReadFile(filepath.Join(base.repository.StartDir, dataDirName, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, dataDirName, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return NewApplication(RepositoryOptions{StartDir: dir})
+}
+
+func mergeRequestFor(base, ours, theirs *Application, name string) MergeDriverRequest {
+	path := func(app *Application) string { return filepath.Join(app.repository.StartDir, dataDirName, name) }
+	return MergeDriverRequest{Base: path(base), Ours: path(ours), Theirs: path(theirs)}
+}
+
+func TestMergeDriverUnionsBacklogAndJournal(t *testing.T) {
+	base := newTestApplication(t)
+	first, err := base.CreateTask(CreateTaskRequest{Title: "First"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := base.CreateTask(CreateTaskRequest{Title: "Second"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ours, theirs := branchApplication(t, base), branchApplication(t, base)
+	if _, err := ours.Claim(ClaimRequest{ID: first.ID, AgentID: "ours-agent"}); err != nil {
+		t.Fatal(err)
+	}
+	third, err := theirs.CreateTask(CreateTaskRequest{Title: "Third"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := theirs.Sequence(SequenceRequest{Command: "sequence", EventType: "link", IDs: []string{second.ID, third.ID}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := theirs.Result(ResultRequest{ID: second.ID, Text: "Measured"}); err != nil {
+		t.Fatal(err)
+	}
+
+	merged, err := ours.MergeDriver(mergeRequestFor(base, ours, theirs, "backlog.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged.File != "backlog" || merged.Ours != 1 || merged.Theirs != 2 {
+		t.Fatalf("backlog merge outcome = %#v", merged)
+	}
+	journal, err := ours.MergeDriver(mergeRequestFor(base, ours, theirs, journalFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if journal.File != "journal" || journal.Ours != 1 || journal.Theirs != 2 {
+		t.Fatalf("journal merge outcome = %#v", journal)
+	}
+
+	claimed, err := ours.Show(ShowRequest{ID: first.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claimed.Task.ClaimedBy != "ours-agent" {
+		t.Fatalf("merged claim = %q", claimed.Task.ClaimedBy)
+	}
+	shown, err := ours.Show(ShowRequest{ID: third.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := shown.Graph.Deps[third.ID][second.ID]; !ok {
+		t.Fatalf("merged dependencies = %v", shown.Graph.Deps)
+	}
+	measured, err := ours.Show(ShowRequest{ID: second.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest := latestExplicitResult(measured.Journal, second.ID); latest == nil || latest.Text != "Measured" {
+		t.Fatalf("merged journal = %#v", measured.Journal)
+	}
+}
+
+func TestMergeDriverReportsSemanticConflicts(t *testing.T) {
+	base := newTestApplication(t)
+	first, err := base.CreateTask(CreateTaskRequest{Title: "First"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := base.CreateTask(CreateTaskRequest{Title: "Second"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		name   string
+		want   string
+		ours   func(*Application) error
+		theirs func(*Application) error
+	}{
+		{
+			name: "double claim", want: "claimed on both branches",
+			ours: func(app *Application) error {
+				_, err := app.Claim(ClaimRequest{ID: first.ID, AgentID: "ours-agent"})
+				return err
+			},
+			theirs: func(app *Application) error {
+				_, err := app.Claim(ClaimRequest{ID: first.ID, AgentID: "theirs-agent"})
+				return err
+			},
+		},
+		{
+			name: "cycle", want: "dependency cycle",
+			ours: func(app *Application) error {
+				_, err := app.Sequence(SequenceRequest{Command: "sequence", EventType: "link", IDs: []string{first.ID, second.ID}})
+				return err
+			},
+			theirs: func(app *Application) error {
+				_, err := app.Sequence(SequenceRequest{Command: "sequence", EventType: "link", IDs: []string{second.ID, first.ID}})
+				return err
+			},
+		},
+		{
+			name: "title", want: "retitled on both branches",
+			ours: func(app *Application) error {
+				_, err := app.UpdateTitle(UpdateTitleRequest{ID: first.ID, Title: "Ours"})
+				return err
+			},
+			theirs: func(app *Application) error {
+				_, err := app.UpdateTitle(UpdateTitleRequest{ID: first.ID, Title: "Theirs"})
+				return err
+			},
+		},
+		{
+			name: "compacted branch", want: "rewrote records shared with the merge base",
+			ours: func(app *Application) error {
+				_, err := app.Compact()
+				return err
+			},
+			theirs: func(app *Application) error {
+				_, err := app.UpdateTitle(UpdateTitleRequest{ID: first.ID, Title: "Theirs"})
+				return err
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			ours, theirs := branchApplication(t, base), branchApplication(t, base)
+			if err := test.ours(ours); err != nil {
+				t.Fatal(err)
+			}
+			if err := test.theirs(theirs); err != nil {
+				t.Fatal(err)
+			}
+			request := mergeRequestFor(base, ours, theirs, "backlog.jsonl")
+			before, err := os.ReadFile(request.Ours)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = ours.MergeDriver(request)
+			requireApplicationError(t, err, ErrorConflict)
+			if !strings.Contains(err.Error(), test.want) {
+				t.Fatalf("conflict = %v, want %q", err, test.want)
+			}
+			after, err := os.ReadFile(request.Ours)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(before, after) {
+				t.Fatal("conflicting merge rewrote ours")
+			}
+		})
+	}
+}
+
+func TestMergeDriverAcceptsTheSameClaimOnBothBranches(t *testing.T) {
+	base := newTestApplication(t)
+	task, err := base.CreateTask(CreateTaskRequest{Title: "Shared"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ours, theirs := branchApplication(t, base), branchApplication(t, base)
+	for _, app := range []*Application{ours, theirs} {
+		if _, err := app.Claim(ClaimRequest{ID: task.ID, AgentID: "agent"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := ours.MergeDriver(mergeRequestFor(base, ours, theirs, "backlog.jsonl")); err != nil {
+		t.Fatalf("same-agent claims conflicted: %v", err)
+	}
+	shown, err := ours.Show(ShowRequest{ID: task.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shown.Task.ClaimedBy != "agent" || shown.Task.State != stateDoing {
+		t.Fatalf("merged task = %#v", shown.Task)
+	}
+}
+
+func TestInitializeGitMergeWritesAttributesOnce(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, gitAttributesFileName), []byte("*.png binary"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	app := NewApplication(RepositoryOptions{StartDir: dir})
+	for range 2 {
+		if _, err := app.Initialize(InitializeRequest{Dir: dir, GitMerge: true}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data, err := os.ReadFile(filepath.Join(dir, gitAttributesFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "*.png binary\n.ergo/backlog.jsonl merge=ergo\n.ergo/journal.jsonl merge=ergo\n"
+	if string(data) != want {
+		t.Fatalf(".gitattributes = %q, want %q", data, want)
+	}
+}
@@ -103,6 +103,25 @@ type Task struct {
 	UpdatedAt time.Time
 	Results   []Result  // Attached results/artifacts, newest first
 	Messages  []Message // Lifecycle messages, newest first
+
+	// Priority is one of P0 (most urgent) through P3. Empty means the default
+	// level so histories written before priorities replay unchanged.
+	Priority string
+
+	// Labels partition work between agents. The set is sorted and unique.
+	Labels []string
+
+	// A leased claim lapses at LeaseExpiresAt unless its holder heartbeats.
+	// Lease is the most recently granted duration; both are zero when the
+	// claim is unleased.
+	Lease          time.Duration
+	LeaseExpiresAt time.Time
+}
+
+// leaseExpired reports whether a leased claim has lapsed at now. Unleased
+// claims never expire.
+func (task *Task) leaseExpired(now time.Time) bool {
+	return task != nil && task.ClaimedBy != "" && !task.LeaseExpiresAt.IsZero() && !now.Before(task.LeaseExpiresAt)
 }
 
 type Graph struct {
@@ -118,6 +137,25 @@ type Graph struct {
 	readyByID        map[string]bool
 	epicStateByID    map[string]string
 	derivedCached    bool
+
+	// asOf pins lease evaluation; zero means the wall clock.
+	asOf time.Time
+}
+
+func (graph *Graph) leaseNow() time.Time {
+	if graph != nil && !graph.asOf.IsZero() {
+		return graph.asOf
+	}
+	return time.Now().UTC()
+}
+
+// LeaseExpired reports whether id is held by a claim whose lease has lapsed.
+// Expired claims remain recorded until another agent reclaims the task.
+func (graph *Graph) LeaseExpired(id string) bool {
+	if graph == nil {
+		return false
+	}
+	return graph.Tasks[id].leaseExpired(graph.leaseNow())
 }
 
 type TombstoneInfo struct {
@@ -168,6 +206,116 @@ func validateEpicPromotion(destination *Task) error {
 	return nil
 }
 
+const defaultPriority = "P2"
+
+var priorityLevels = []string{"P0", "P1", "P2", "P3"}
+
+// normalizePriority accepts P0-P3 in either case, or a bare digit 0-3.
+func normalizePriority(value string) (string, error) {
+	level := strings.ToUpper(strings.TrimSpace(value))
+	if len(level) == 1 {
+		level = "P" + level
+	}
+	for _, known := range priorityLevels {
+		if level == known {
+			return level, nil
+		}
+	}
+	return "", fmt.Errorf("invalid priority %q (want P0, P1, P2, or P3)", value)
+}
+
+// effectivePriority resolves an unset priority to the default level.
+func effectivePriority(task *Task) string {
+	if task == nil || task.Priority == "" {
+		return defaultPriority
+	}
+	return task.Priority
+}
+
+const maxLabelLen = 64
+
+// normalizeLabel lowercases a label and rejects characters that would be
+// ambiguous in flags or list output.
+func normalizeLabel(value string) (string, error) {
+	label := strings.ToLower(strings.TrimSpace(value))
+	if label == "" {
+		return "", errors.New("label cannot be empty")
+	}
+	if len(label) > maxLabelLen {
+		return "", fmt.Errorf("label %q is longer than %d characters", value, maxLabelLen)
+	}
+	for i, r := range label {
+		alphanumeric := (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9')
+		if alphanumeric || (i > 0 && strings.ContainsRune("-_./:", r)) {
+			continue
+		}
+		return "", fmt.Errorf("invalid label %q (use letters, digits, and - _ . / : after the first character)", value)
+	}
+	return label, nil
+}
+
+// normalizeLabels returns the sorted, unique normalized form of values.
+func normalizeLabels(values []string) ([]string, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	labels := make([]string, 0, len(values))
+	for _, value := range values {
+		label, err := normalizeLabel(value)
+		if err != nil {
+			return nil, err
+		}
+		labels = append(labels, label)
+	}
+	return sortedUniqueStrings(labels), nil
+}
+
+func (task *Task) hasLabel(label string) bool {
+	return task != nil && containsString(task.Labels, label)
+}
+
+// labelFilter selects leaves carrying every Include label and no Exclude
+// label. The zero value matches every task.
+type labelFilter struct {
+	Include []string
+	Exclude []string
+}
+
+func (filter labelFilter) active() bool {
+	return len(filter.Include) > 0 || len(filter.Exclude) > 0
+}
+
+func (filter labelFilter) matches(task *Task) bool {
+	for _, label := range filter.Include {
+		if !task.hasLabel(label) {
+			return false
+		}
+	}
+	for _, label := range filter.Exclude {
+		if task.hasLabel(label) {
+			return false
+		}
+	}
+	return true
+}
+
+func newLabelFilter(include, exclude []string) (labelFilter, error) {
+	var filter labelFilter
+	var err error
+	if filter.Include, err = normalizeLabels(include); err != nil {
+		return labelFilter{}, err
+	}
+	if filter.Exclude, err = normalizeLabels(exclude); err != nil {
+		return labelFilter{}, err
+	}
+	for _, label := range filter.Include {
+		if containsString(filter.Exclude, label) {
+			return labelFilter{}, fmt.Errorf("label %q is both required and excluded", label)
+		}
+	}
+	return filter, nil
+}
+
 const maxResultSummaryLen = 120
 
 // validateResultSummary ensures summary is non-empty, single-line, and ≤120 chars.
@@ -27,12 +27,25 @@ type taskMutation struct {
 	BodyAppend    bool
 	EpicID        string
 	EpicSet       bool
+	Priority      string
+	PrioritySet   bool
+	AddLabels     []string
+	RemoveLabels  []string
 	ValidateMove  bool
 	MessageKind   string
 	MessageText   string
 	MessageSet    bool
 	AllowedStates []string
 	ClaimConflict bool
+	Lease         time.Duration
+	// ExpectText refuses the mutation unless the stored body, and the title
+	// when TitleSet, still equal ExpectBody and ExpectTitle.
+	ExpectText  bool
+	ExpectTitle string
+	ExpectBody  string
+	// ExpectRevision, when set, refuses the mutation unless the task is still
+	// at that revision.
+	ExpectRevision string
 }
 
 type mutationOutcome struct {
@@ -49,58 +62,9 @@ func applyTaskMutation(dir string, opts RepositoryOptions, id string, mutation t
 	var outcome mutationOutcome
 
 	build := func(graph *Graph) ([]Event, []JournalEntry, error) {
-		if _, ok := graph.Tombstones[id]; ok {
-			return nil, nil, classified(ErrorNotFound, prunedErr(id))
-		}
-		task := graph.Tasks[id]
-		if task == nil {
-			return nil, nil, classified(ErrorNotFound, fmt.Errorf("unknown task id %s", id))
-		}
-		if len(mutation.AllowedStates) > 0 && !containsString(mutation.AllowedStates, task.State) {
-			return nil, nil, classified(ErrorConflict, lifecycleStateError(mutation.Kind, id, task.State))
-		}
-		if mutation.ClaimConflict && task.ClaimedBy != "" && task.ClaimedBy != mutation.Claim {
-			return nil, nil, classified(ErrorConflict, fmt.Errorf("task %s is already claimed by %s", id, task.ClaimedBy))
-		}
-		if mutation.EpicSet && mutation.ValidateMove {
-			if err := validateMovePlacement(graph, task, mutation.EpicID); err != nil {
-				return nil, nil, err
-			}
-		}
-		if graph.IsEpic(task.ID) {
-			if mutation.ClaimSet {
-				return nil, nil, classified(ErrorConflict, errors.New("epics cannot be claimed"))
-			}
-			if mutation.StateSet {
-				return nil, nil, classified(ErrorConflict, errors.New("epics do not have state"))
-			}
-			if mutation.MessageSet {
-				return nil, nil, classified(ErrorConflict, errors.New("epics cannot have lifecycle messages"))
-			}
-		}
-		if mutation.Kind == "open" && task.State == stateTodo {
-			mutation.MessageSet = false
-			mutation.MessageText = ""
-		}
-
-		now := time.Now().UTC()
-		events, fields, err := buildMutationEvents(id, task, mutation, agentID, now)
-		if err != nil {
-			return nil, nil, err
-		}
+		events, journal, fields, err := buildTaskMutation(graph, id, mutation, agentID, time.Now().UTC())
 		outcome.ChangedFields = fields
-		var journal []JournalEntry
-		if isAutomaticJournalKind(mutation.Kind) && (len(events) > 0 || mutation.MessageSet) {
-			responsible := agentID
-			if responsible == "" {
-				responsible = task.ClaimedBy
-			}
-			journal = []JournalEntry{newJournalEntry(id, mutation.Kind, responsible, mutation.MessageText, now)}
-		}
-		if mutation.MessageSet {
-			outcome.ChangedFields = append(outcome.ChangedFields, "message")
-		}
-		return events, journal, nil
+		return events, journal, err
 	}
 
 	var update UpdateOutcome
@@ -120,6 +84,76 @@ func applyTaskMutation(dir string, opts RepositoryOptions, id string, mutation t
 	return outcome, err
 }
 
+// buildTaskMutation validates mutation against graph and returns the events
+// and journal entries that apply it, with the names of the changed fields.
+func buildTaskMutation(graph *Graph, id string, mutation taskMutation, agentID string, now time.Time) ([]Event, []JournalEntry, []string, error) {
+	if _, ok := graph.Tombstones[id]; ok {
+		return nil, nil, nil, classified(ErrorNotFound, prunedErr(id))
+	}
+	task := graph.Tasks[id]
+	if task == nil {
+		return nil, nil, nil, classified(ErrorNotFound, fmt.Errorf("unknown task id %s", id))
+	}
+	if err := checkRevision(graph, id, mutation.ExpectRevision); err != nil {
+		return nil, nil, nil, err
+	}
+	if mutation.ExpectText && (task.Body != mutation.ExpectBody || (mutation.TitleSet && task.Title != mutation.ExpectTitle)) {
+		return nil, nil, nil, classified(ErrorConflict, &EditConflict{ID: id, Current: TaskText{Title: task.Title, Body: task.Body}})
+	}
+	if len(mutation.AllowedStates) > 0 && !containsString(mutation.AllowedStates, task.State) {
+		return nil, nil, nil, classified(ErrorConflict, lifecycleStateError(mutation.Kind, id, task.State))
+	}
+	if mutation.ClaimConflict && task.ClaimedBy != "" && task.ClaimedBy != mutation.Claim && !task.leaseExpired(now) {
+		return nil, nil, nil, classified(ErrorConflict, fmt.Errorf("task %s is already claimed by %s", id, task.ClaimedBy))
+	}
+	if mutation.EpicSet && mutation.ValidateMove {
+		if err := validateMovePlacement(graph, task, mutation.EpicID); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	if graph.IsEpic(task.ID) {
+		if mutation.ClaimSet {
+			return nil, nil, nil, classified(ErrorConflict, errors.New("epics cannot be claimed"))
+		}
+		if mutation.StateSet {
+			return nil, nil, nil, classified(ErrorConflict, errors.New("epics do not have state"))
+		}
+		if mutation.MessageSet {
+			return nil, nil, nil, classified(ErrorConflict, errors.New("epics cannot have lifecycle messages"))
+		}
+		if mutation.PrioritySet {
+			return nil, nil, nil, classified(ErrorConflict, errors.New("epics do not have priority; prioritize their children"))
+		}
+		if len(mutation.AddLabels) > 0 {
+			return nil, nil, nil, classified(ErrorConflict, errors.New("epics do not have labels; label their children"))
+		}
+	}
+	if mutation.Kind == "open" && task.State == stateTodo {
+		mutation.MessageSet = false
+		mutation.MessageText = ""
+	}
+
+	events, fields, err := buildMutationEvents(id, task, mutation, agentID, now)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	var journal []JournalEntry
+	if mutation.ClaimSet {
+		journal = leaseExpiryJournal(task, now)
+	}
+	if isAutomaticJournalKind(mutation.Kind) && (len(events) > 0 || mutation.MessageSet) {
+		responsible := agentID
+		if responsible == "" {
+			responsible = task.ClaimedBy
+		}
+		journal = append(journal, newJournalEntry(id, mutation.Kind, responsible, mutation.MessageText, now))
+	}
+	if mutation.MessageSet {
+		fields = append(fields, "message")
+	}
+	return events, journal, fields, nil
+}
+
 func buildMutationEvents(id string, task *Task, mutation taskMutation, agentID string, now time.Time) ([]Event, []string, error) {
 	var events []Event
 	var fields []string
@@ -158,12 +192,49 @@ func buildMutationEvents(id string, task *Task, mutation taskMutation, agentID s
 		events = append(events, event)
 		fields = append(fields, "epic")
 	}
+	if mutation.PrioritySet && mutation.Priority != effectivePriority(task) {
+		event, err := newEvent(eventPriority, now, PriorityEvent{ID: id, Priority: mutation.Priority, TS: formatTime(now)})
+		if err != nil {
+			return nil, nil, err
+		}
+		events = append(events, event)
+		fields = append(fields, "priority")
+	}
+	labelsChanged := false
+	for _, label := range mutation.AddLabels {
+		if task.hasLabel(label) {
+			continue
+		}
+		event, err := newEvent(eventLabel, now, LabelEvent{ID: id, Label: label, TS: formatTime(now)})
+		if err != nil {
+			return nil, nil, err
+		}
+		events = append(events, event)
+		labelsChanged = true
+	}
+	for _, label := range mutation.RemoveLabels {
+		if !task.hasLabel(label) {
+			continue
+		}
+		event, err := newEvent(eventUnlabel, now, LabelEvent{ID: id, Label: label, TS: formatTime(now)})
+		if err != nil {
+			return nil, nil, err
+		}
+		events = append(events, event)
+		labelsChanged = true
+	}
+	if labelsChanged {
+		fields = append(fields, "labels")
+	}
 
 	targetState, targetClaim, err := mutationPostcondition(task, mutation, agentID)
 	if err != nil {
 		return nil, nil, err
 	}
-	if targetClaim != task.ClaimedBy {
+	// Claiming over a lapsed lease rewrites the claim even for the same agent,
+	// which clears the stale lease.
+	reclaim := mutation.ClaimSet && targetClaim != "" && task.leaseExpired(now)
+	if targetClaim != task.ClaimedBy || reclaim {
 		if targetClaim == "" {
 			event, err := newEvent("unclaim", now, UnclaimEvent{ID: id, TS: formatTime(now)})
 			if err != nil {
@@ -187,6 +258,16 @@ func buildMutationEvents(id string, task *Task, mutation taskMutation, agentID s
 		events = append(events, event)
 		fields = append(fields, "state")
 	}
+	if mutation.Lease > 0 && targetClaim != "" {
+		event, err := newEvent(eventLease, now, LeaseEvent{
+			ID: id, AgentID: targetClaim, ExpiresAt: formatTime(now.Add(mutation.Lease)), TS: formatTime(now),
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		events = append(events, event)
+		fields = append(fields, "lease")
+	}
 
 	return events, fields, nil
 }
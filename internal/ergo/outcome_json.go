@@ -0,0 +1,357 @@
+// Purpose: Render command outcomes and failures as versioned JSON documents.
+// Exports: RenderOutcomeJSON and RenderErrorJSON.
+// Role: Stable machine projection behind the global --json flag, beside the
+// readable receipts. Commands with their own documents (list, search, history,
+// path, stats, fsck, watch) keep them.
+// Invariants: every document carries "version"; a failure is one
+// {"version", "error": {"kind", "message"}} document.
+package ergo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+const outcomeJSONVersion = 1
+
+// taskJSONDocument is the task projection shared by JSON receipts and the
+// MCP show, claim, and lifecycle tools.
+type taskJSONDocument struct {
+	ID             string         `json:"id"`
+	Title          string         `json:"title"`
+	Kind           string         `json:"kind"`
+	State          string         `json:"state"`
+	Revision       string         `json:"revision"`
+	EpicID         string         `json:"epic_id,omitempty"`
+	Priority       string         `json:"priority,omitempty"`
+	Labels         []string       `json:"labels,omitempty"`
+	ClaimedBy      string         `json:"claimed_by,omitempty"`
+	LeaseExpiresAt string         `json:"lease_expires_at,omitempty"`
+	DependsOn      []string       `json:"depends_on,omitempty"`
+	Dependents     []string       `json:"dependents,omitempty"`
+	Body           string         `json:"body"`
+	Children       []string       `json:"children,omitempty"`
+	Journal        []JournalEntry `json:"journal,omitempty"`
+}
+
+func newTaskJSONDocument(graph *Graph, task *Task) taskJSONDocument {
+	document := taskJSONDocument{
+		ID: task.ID, Title: task.Title, Kind: "task", State: task.State, Revision: graph.Revision(task.ID), EpicID: task.EpicID,
+		Labels: task.Labels, ClaimedBy: task.ClaimedBy, DependsOn: sortedKeys(graph.Deps[task.ID]),
+		Dependents: graph.Dependents(task.ID), Body: task.Body,
+	}
+	if graph.IsEpic(task.ID) {
+		document.Kind, document.State = "epic", graph.EpicState(task.ID)
+	} else {
+		document.Priority = effectivePriority(task)
+	}
+	if !task.LeaseExpiresAt.IsZero() {
+		document.LeaseExpiresAt = formatTime(task.LeaseExpiresAt)
+	}
+	return document
+}
+
+type jsonEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+func jsonEdges(edges []sequenceEdge) []jsonEdge {
+	converted := make([]jsonEdge, 0, len(edges))
+	for _, edge := range edges {
+		converted = append(converted, jsonEdge{From: edge.FromID, To: edge.ToID})
+	}
+	return converted
+}
+
+type taskJSONRef struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+type pruneJSONItem struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	Kind  string `json:"kind"`
+	State string `json:"state"`
+}
+
+type batchJSONCreated struct {
+	Handle string `json:"handle,omitempty"`
+	ID     string `json:"id"`
+	Title  string `json:"title"`
+}
+
+// RenderOutcomeJSON writes one versioned document for a command outcome.
+func RenderOutcomeJSON(w io.Writer, outcome any) error {
+	const v = outcomeJSONVersion
+	var document any
+	switch outcome := outcome.(type) {
+	case InitializeOutcome:
+		document = struct {
+			Version       int    `json:"version"`
+			Path          string `json:"path"`
+			Status        string `json:"status"`
+			GitAttributes string `json:"git_attributes,omitempty"`
+			GitConfigured bool   `json:"git_configured,omitempty"`
+		}{v, outcome.Path, outcome.Status, outcome.GitAttributes, outcome.GitConfigured}
+	case CreateTaskOutcome:
+		document = struct {
+			Version int    `json:"version"`
+			ID      string `json:"id"`
+		}{v, outcome.ID}
+	case CreateEpicOutcome:
+		children := make([]taskJSONRef, 0, len(outcome.Children))
+		for _, child := range outcome.Children {
+			children = append(children, taskJSONRef{ID: child.ID, Title: child.Title})
+		}
+		document = struct {
+			Version  int           `json:"version"`
+			ID       string        `json:"id"`
+			Title    string        `json:"title"`
+			Children []taskJSONRef `json:"children"`
+			Edges    []jsonEdge    `json:"edges"`
+		}{v, outcome.ID, outcome.Title, children, jsonEdges(outcome.Edges)}
+	case ShowOutcome:
+		task := newTaskJSONDocument(outcome.Graph, outcome.Task)
+		for _, child := range outcome.Children {
+			task.Children = append(task.Children, child.ID)
+		}
+		task.Journal = journalForTask(outcome.Journal, outcome.Task.ID)
+		document = struct {
+			Version int              `json:"version"`
+			Task    taskJSONDocument `json:"task"`
+		}{v, task}
+	case ShowBodyOutcome:
+		document = struct {
+			Version int    `json:"version"`
+			Body    string `json:"body"`
+		}{v, outcome.Body}
+	case ClaimOutcome:
+		var task *taskJSONDocument
+		if outcome.Task != nil {
+			claimed := newTaskJSONDocument(outcome.Graph, outcome.Task)
+			claimed.Journal = journalForTask(outcome.Journal, outcome.Task.ID)
+			task = &claimed
+		}
+		document = struct {
+			Version int               `json:"version"`
+			Task    *taskJSONDocument `json:"task"`
+			Labels  []string          `json:"labels,omitempty"`
+		}{v, task, outcome.Labels}
+	case HeartbeatOutcome:
+		document = struct {
+			Version      int    `json:"version"`
+			ID           string `json:"id"`
+			Agent        string `json:"agent"`
+			LeaseSeconds int64  `json:"lease_seconds"`
+			ExpiresAt    string `json:"expires_at"`
+		}{v, outcome.ID, outcome.AgentID, int64(outcome.Lease / time.Second), formatTime(outcome.ExpiresAt)}
+	case LifecycleOutcome:
+		var ready *taskJSONRef
+		if outcome.Ready != nil {
+			ready = &taskJSONRef{ID: outcome.Ready.ID, Title: outcome.Ready.Title}
+		}
+		document = struct {
+			Version         int              `json:"version"`
+			Task            taskJSONDocument `json:"task"`
+			Changed         []string         `json:"changed"`
+			MessageAppended bool             `json:"message_appended"`
+			Ready           *taskJSONRef     `json:"ready,omitempty"`
+		}{v, newTaskJSONDocument(outcome.Graph, outcome.Task), nonNil(outcome.ChangedFields), outcome.MessageSet, ready}
+	case ResultOutcome:
+		document = struct {
+			Version  int    `json:"version"`
+			TaskID   string `json:"task_id"`
+			Text     string `json:"text"`
+			FilePath string `json:"file_path,omitempty"`
+		}{v, outcome.TaskID, outcome.Text, outcome.FilePath}
+	case UpdateTitleOutcome:
+		document = struct {
+			Version int    `json:"version"`
+			ID      string `json:"id"`
+			Title   string `json:"title"`
+			Changed bool   `json:"changed"`
+		}{v, outcome.ID, outcome.Title, outcome.Changed}
+	case UpdateBodyOutcome:
+		document = struct {
+			Version int    `json:"version"`
+			ID      string `json:"id"`
+			Bytes   int    `json:"bytes"`
+			Changed bool   `json:"changed"`
+		}{v, outcome.ID, outcome.Bytes, outcome.Changed}
+	case EditTaskOutcome:
+		document = struct {
+			Version       int      `json:"version"`
+			ID            string   `json:"id"`
+			Title         string   `json:"title"`
+			Bytes         int      `json:"bytes"`
+			ChangedFields []string `json:"changed_fields"`
+		}{v, outcome.ID, outcome.Title, outcome.Bytes, nonNil(outcome.ChangedFields)}
+	case SetPriorityOutcome:
+		document = struct {
+			Version  int    `json:"version"`
+			ID       string `json:"id"`
+			Priority string `json:"priority"`
+			Changed  bool   `json:"changed"`
+		}{v, outcome.ID, outcome.Priority, outcome.Changed}
+	case LabelOutcome:
+		action := "add"
+		if outcome.Remove {
+			action = "remove"
+		}
+		document = struct {
+			Version int      `json:"version"`
+			ID      string   `json:"id"`
+			Action  string   `json:"action"`
+			Labels  []string `json:"labels"`
+			Changed bool     `json:"changed"`
+		}{v, outcome.ID, action, nonNil(outcome.Labels), outcome.Changed}
+	case MoveOutcome:
+		var epicID *string
+		if !outcome.ToRoot {
+			epicID = &outcome.DestinationID
+		}
+		document = struct {
+			Version int     `json:"version"`
+			ID      string  `json:"id"`
+			EpicID  *string `json:"epic_id"`
+			Changed bool    `json:"changed"`
+		}{v, outcome.ID, epicID, outcome.Changed}
+	case SequenceOutcome:
+		document = struct {
+			Version int        `json:"version"`
+			Action  string     `json:"action"`
+			Edges   []jsonEdge `json:"edges"`
+		}{v, outcome.EventType, jsonEdges(outcome.Edges)}
+	case BatchOutcome:
+		created := make([]batchJSONCreated, 0, len(outcome.Created))
+		for _, task := range outcome.Created {
+			created = append(created, batchJSONCreated{task.Handle, task.ID, task.Title})
+		}
+		document = struct {
+			Version    int                `json:"version"`
+			Operations int                `json:"operations"`
+			Created    []batchJSONCreated `json:"created"`
+			Handles    map[string]string  `json:"handles"`
+		}{v, outcome.Operations, created, outcome.Handles()}
+	case ImportBeadsOutcome:
+		type importedJSON struct {
+			SourceID string `json:"source_id"`
+			ID       string `json:"id,omitempty"`
+			Title    string `json:"title"`
+			Kind     string `json:"kind"`
+		}
+		type noteJSON struct {
+			Field  string   `json:"field"`
+			Detail string   `json:"detail"`
+			Issues []string `json:"issues"`
+		}
+		created := make([]importedJSON, 0, len(outcome.Created))
+		for _, task := range outcome.Created {
+			kind := "task"
+			if task.Epic {
+				kind = "epic"
+			}
+			created = append(created, importedJSON{task.SourceID, task.ID, task.Title, kind})
+		}
+		notes := make([]noteJSON, 0, len(outcome.Notes))
+		for _, note := range outcome.Notes {
+			notes = append(notes, noteJSON{note.Field, note.Detail, note.Issues})
+		}
+		document = struct {
+			Version      int            `json:"version"`
+			Source       string         `json:"source"`
+			DryRun       bool           `json:"dry_run"`
+			Created      []importedJSON `json:"created"`
+			Dependencies int            `json:"dependencies"`
+			Skipped      int            `json:"skipped"`
+			Notes        []noteJSON     `json:"notes"`
+		}{v, outcome.Source, outcome.DryRun, created, outcome.Dependencies, outcome.Skipped, notes}
+	case UndoOutcome:
+		restored := outcome.Restored
+		if restored == nil {
+			restored = map[string][]string{}
+		}
+		document = struct {
+			Version  int                 `json:"version"`
+			At       string              `json:"at"`
+			Kinds    []string            `json:"kinds"`
+			Restored map[string][]string `json:"restored"`
+			Removed  []string            `json:"removed"`
+		}{v, outcome.At, nonNil(outcome.Kinds), restored, nonNil(outcome.Removed)}
+	case WhereOutcome:
+		document = struct {
+			Version int    `json:"version"`
+			Path    string `json:"path"`
+		}{v, outcome.Path}
+	case InfoOutcome:
+		document = struct {
+			Version     int    `json:"version"`
+			Executable  string `json:"executable"`
+			ErgoVersion string `json:"ergo_version"`
+			Project     string `json:"project"`
+			Backlog     string `json:"backlog"`
+			Journal     string `json:"journal"`
+		}{v, outcome.Executable, outcome.Version, outcome.Project, outcome.Backlog, outcome.Journal}
+	case CompactOutcome:
+		document = struct {
+			Version         int    `json:"version"`
+			Path            string `json:"path"`
+			SourceRecords   int    `json:"source_records"`
+			SnapshotRecords int    `json:"snapshot_records"`
+			JournalRecords  int    `json:"journal_records"`
+		}{v, outcome.Path, outcome.SourceRecords, outcome.SnapshotRecords, outcome.JournalRecords}
+	case PruneOutcome:
+		items := make([]pruneJSONItem, 0, len(outcome.Items))
+		for _, item := range outcome.Items {
+			kind := "task"
+			if item.IsContainer {
+				kind = "epic"
+			}
+			items = append(items, pruneJSONItem{ID: item.ID, Title: item.Title, Kind: kind, State: item.State})
+		}
+		document = struct {
+			Version        int             `json:"version"`
+			Applied        bool            `json:"applied"`
+			Items          []pruneJSONItem `json:"items"`
+			JournalEntries int             `json:"journal_entries"`
+		}{v, outcome.Confirmed, items, outcome.JournalEntries}
+	case VersionOutcome:
+		document = struct {
+			Version     int    `json:"version"`
+			ErgoVersion string `json:"ergo_version"`
+		}{v, outcome.Version}
+	default:
+		return classified(ErrorInternal, fmt.Errorf("no JSON document for %T", outcome))
+	}
+	return encodeJSONDocument(w, document)
+}
+
+// RenderErrorJSON writes a failure with its stable classification.
+func RenderErrorJSON(w io.Writer, kind ErrorKind, err error) error {
+	type errorJSON struct {
+		Kind    ErrorKind `json:"kind"`
+		Message string    `json:"message"`
+	}
+	return encodeJSONDocument(w, struct {
+		Version int       `json:"version"`
+		Error   errorJSON `json:"error"`
+	}{outcomeJSONVersion, errorJSON{kind, err.Error()}})
+}
+
+func encodeJSONDocument(w io.Writer, document any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(document)
+}
+
+// nonNil keeps empty lists as [] rather than null.
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
@@ -0,0 +1,67 @@
+package ergo
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestRenderOutcomeJSONCarriesRelationshipsAndJournal(t *testing.T) {
+	app := newTestApplication(t)
+	first, err := app.CreateTask(CreateTaskRequest{Title: "First", Body: "Do it"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := app.CreateTask(CreateTaskRequest{Title: "Second"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.Sequence(SequenceRequest{Command: "sequence", EventType: eventLink, IDs: []string{first.ID, second.ID}}); err != nil {
+		t.Fatal(err)
+	}
+	shown, err := app.Show(ShowRequest{ID: first.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := RenderOutcomeJSON(&out, shown); err != nil {
+		t.Fatal(err)
+	}
+	var document struct {
+		Version int `json:"version"`
+		Task    struct {
+			ID         string         `json:"id"`
+			Body       string         `json:"body"`
+			Dependents []string       `json:"dependents"`
+			Journal    []JournalEntry `json:"journal"`
+		} `json:"task"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &document); err != nil {
+		t.Fatalf("decode %q: %v", out.String(), err)
+	}
+	if document.Version != 1 || document.Task.ID != first.ID || document.Task.Body != "Do it" ||
+		!reflect.DeepEqual(document.Task.Dependents, []string{second.ID}) || len(document.Task.Journal) != 1 {
+		t.Fatalf("show document = %s", out.String())
+	}
+
+	out.Reset()
+	if err := RenderOutcomeJSON(&out, ClaimOutcome{NoReady: true, Labels: []string{"backend"}}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := out.String(), `{"version":1,"task":null,"labels":["backend"]}`+"\n"; got != want {
+		t.Fatalf("no-ready claim = %q, want %q", got, want)
+	}
+	requireApplicationError(t, RenderOutcomeJSON(&out, struct{}{}), ErrorInternal)
+}
+
+func TestRenderErrorJSON(t *testing.T) {
+	var out bytes.Buffer
+	if err := RenderErrorJSON(&out, ErrorNotFound, errors.New("unknown task id ABCDEF")); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := out.String(), `{"version":1,"error":{"kind":"not_found","message":"unknown task id ABCDEF"}}`+"\n"; got != want {
+		t.Fatalf("error document = %q, want %q", got, want)
+	}
+}
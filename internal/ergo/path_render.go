@@ -0,0 +1,91 @@
+// Purpose: Render path outcomes as readable text or a versioned JSON document.
+// Role: Presentation only; chain selection belongs to Application.Path.
+package ergo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+const pathJSONVersion = 1
+
+type pathJSONDocument struct {
+	Version  int            `json:"version"`
+	ID       string         `json:"id"`
+	Title    string         `json:"title"`
+	Length   int            `json:"length"`
+	Critical []string       `json:"critical_path"`
+	Tasks    []pathJSONTask `json:"tasks"`
+	Links    []pathJSONLink `json:"links"`
+	Ready    []string       `json:"ready"`
+	Width    int            `json:"width"`
+}
+
+type pathJSONTask struct {
+	ID       string `json:"id"`
+	Title    string `json:"title"`
+	State    string `json:"state"`
+	Ready    bool   `json:"ready"`
+	Critical bool   `json:"critical"`
+}
+
+type pathJSONLink struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Critical bool   `json:"critical"`
+}
+
+func RenderPath(w io.Writer, outcome PathOutcome, useColor bool) {
+	writeGenerated(w, outcome.Task.ID, colorCyan, useColor)
+	fmt.Fprintf(w, "  %s\n", outcome.Task.Title)
+	if len(outcome.Critical) == 0 {
+		fmt.Fprintln(w, "Nothing unfinished gates this work.")
+		return
+	}
+	writeGeneratedLine(w, "Critical path: "+pluralize(len(outcome.Critical), "task", "tasks"), colorBold, useColor)
+	for _, task := range outcome.Critical {
+		fmt.Fprintf(w, "  %s  %s %s\n", task.ID, stateIcon(task, outcome.Graph.IsReady(task.ID), false), task.Title)
+	}
+	if len(outcome.Links) > 0 {
+		writeGeneratedLine(w, "Links (* on the critical path):", colorBold, useColor)
+		for _, link := range outcome.Links {
+			marker := " "
+			if link.Critical {
+				marker = "*"
+			}
+			fmt.Fprintf(w, "%s %s → %s\n", marker, link.FromID, link.ToID)
+		}
+	}
+	writeGeneratedLine(w, fmt.Sprintf("Ready now: %d of %s", len(outcome.Ready), pluralize(len(outcome.Scope), "unfinished task", "unfinished tasks")), colorBold, useColor)
+	for _, task := range outcome.Ready {
+		fmt.Fprintf(w, "  %s  %s\n", task.ID, task.Title)
+	}
+}
+
+func RenderPathJSON(w io.Writer, outcome PathOutcome) error {
+	document := pathJSONDocument{
+		Version: pathJSONVersion, ID: outcome.Task.ID, Title: outcome.Task.Title, Length: len(outcome.Critical),
+		Critical: make([]string, 0, len(outcome.Critical)), Tasks: make([]pathJSONTask, 0, len(outcome.Scope)),
+		Links: make([]pathJSONLink, 0, len(outcome.Links)), Ready: make([]string, 0, len(outcome.Ready)), Width: len(outcome.Ready),
+	}
+	critical := map[string]bool{}
+	for _, task := range outcome.Critical {
+		document.Critical = append(document.Critical, task.ID)
+		critical[task.ID] = true
+	}
+	for _, task := range outcome.Scope {
+		document.Tasks = append(document.Tasks, pathJSONTask{
+			ID: task.ID, Title: task.Title, State: task.State, Ready: outcome.Graph.IsReady(task.ID), Critical: critical[task.ID],
+		})
+	}
+	for _, link := range outcome.Links {
+		document.Links = append(document.Links, pathJSONLink{From: link.FromID, To: link.ToID, Critical: link.Critical})
+	}
+	for _, task := range outcome.Ready {
+		document.Ready = append(document.Ready, task.ID)
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(document)
+}
@@ -23,16 +23,17 @@ type PruneItem struct {
 }
 
 // RunPrunePlan computes the prune plan under the lock without writing events.
-func RunPrunePlan(dir string) (PrunePlan, error) {
-	return runPrune(dir, GlobalOptions{}, false)
+// Labels, when given, limit finished leaves to those carrying every label.
+func RunPrunePlan(dir string, labels ...string) (PrunePlan, error) {
+	return runPrune(dir, GlobalOptions{}, false, labelFilter{Include: labels})
 }
 
 // RunPruneApply computes the prune plan and appends delete-marker events for all targets.
-func RunPruneApply(dir string, opts GlobalOptions) (PrunePlan, error) {
-	return runPrune(dir, opts, true)
+func RunPruneApply(dir string, opts GlobalOptions, labels ...string) (PrunePlan, error) {
+	return runPrune(dir, opts, true, labelFilter{Include: labels})
 }
 
-func runPrune(dir string, opts GlobalOptions, apply bool) (PrunePlan, error) {
+func runPrune(dir string, opts GlobalOptions, apply bool, filter labelFilter) (PrunePlan, error) {
 	var repository Repository
 	if err := repository.openAt(dir, opts, systemRepositoryIO()); err != nil {
 		return PrunePlan{}, err
@@ -48,7 +49,7 @@ func runPrune(dir string, opts GlobalOptions, apply bool) (PrunePlan, error) {
 			return err
 		}
 		journal := journalRead.entries
-		plan = buildPrunePlan(graph)
+		plan = buildPrunePlan(graph, filter)
 		selected := make(map[string]struct{}, len(plan.PrunedIDs))
 		for _, id := range plan.PrunedIDs {
 			selected[id] = struct{}{}
@@ -82,13 +83,19 @@ func runPrune(dir string, opts GlobalOptions, apply bool) (PrunePlan, error) {
 	return plan, err
 }
 
-func buildPrunePlan(graph *Graph) PrunePlan {
-	pruned := selectPruneTargets(graph)
+func buildPrunePlan(graph *Graph, filter labelFilter) PrunePlan {
+	pruned := selectMatchingPruneTargets(graph, filter)
 	items := buildPruneItems(graph, pruned)
 	return PrunePlan{PrunedIDs: pruned, Items: items}
 }
 
 func selectPruneTargets(graph *Graph) []string {
+	return selectMatchingPruneTargets(graph, labelFilter{})
+}
+
+// selectMatchingPruneTargets limits finished leaves to filter. An epic is
+// still pruned only when no child would remain.
+func selectMatchingPruneTargets(graph *Graph, filter labelFilter) []string {
 	if graph == nil {
 		return nil
 	}
@@ -97,7 +104,7 @@ func selectPruneTargets(graph *Graph) []string {
 		if graph.IsEpic(task.ID) {
 			continue
 		}
-		if isFinishedState(task.State) {
+		if isFinishedState(task.State) && filter.matches(task) {
 			eligibleTasks[task.ID] = struct{}{}
 		}
 	}
@@ -8,6 +8,7 @@ package ergo
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 )
@@ -148,6 +149,7 @@ func cloneGraph(graph *Graph) *Graph {
 		copied := *task
 		copied.Results = append([]Result(nil), task.Results...)
 		copied.Messages = append([]Message(nil), task.Messages...)
+		copied.Labels = append([]string(nil), task.Labels...)
 		clone.Tasks[id] = &copied
 	}
 	for from, deps := range graph.Deps {
@@ -162,6 +164,7 @@ func cloneGraph(graph *Graph) *Graph {
 	for id := range graph.legacyEmptyEpics {
 		clone.legacyEmptyEpics[id] = struct{}{}
 	}
+	clone.asOf = graph.asOf
 	clone.rebuildIndexes()
 	return clone
 }
@@ -208,6 +211,14 @@ func replayEventsOnto(graph *Graph, events []Event) (*Graph, error) {
 			if !isReadableState(data.State) {
 				return nil, replayInvariantError(context, event.Type, data.ID, fmt.Sprintf("invalid state %q", data.State))
 			}
+			if data.Priority != "" {
+				if level, err := normalizePriority(data.Priority); err != nil || level != data.Priority {
+					return nil, replayInvariantError(context, event.Type, data.ID, fmt.Sprintf("invalid priority %q", data.Priority))
+				}
+			}
+			if labels, err := normalizeLabels(data.Labels); err != nil || !slices.Equal(labels, data.Labels) {
+				return nil, replayInvariantError(context, event.Type, data.ID, fmt.Sprintf("invalid labels %q", data.Labels))
+			}
 			task := &Task{
 				ID:        data.ID,
 				UUID:      data.UUID,
@@ -215,6 +226,8 @@ func replayEventsOnto(graph *Graph, events []Event) (*Graph, error) {
 				State:     data.State,
 				Title:     data.Title,
 				Body:      data.Body,
+				Priority:  data.Priority,
+				Labels:    data.Labels,
 				CreatedAt: createdAt,
 				UpdatedAt: createdAt,
 			}
@@ -249,6 +262,7 @@ func replayEventsOnto(graph *Graph, events []Event) (*Graph, error) {
 			if data.NewState == stateTodo || isFinishedState(data.NewState) {
 				task.ClaimedBy = ""
 				task.ClaimedAt = time.Time{}
+				clearLease(task)
 			}
 		case eventClaim:
 			data := decoded.payload.(ClaimEvent)
@@ -268,7 +282,37 @@ func replayEventsOnto(graph *Graph, events []Event) (*Graph, error) {
 			}
 			task.ClaimedBy = data.AgentID
 			task.ClaimedAt = ts
+			// A new claim starts unleased; a following lease event grants one.
+			clearLease(task)
 			lifecycleSource[data.ID] = replayEventSource{context: context, kind: event.Type}
+		case eventLease, eventHeartbeat:
+			data := decoded.payload.(LeaseEvent)
+			if _, tombstoned := graph.Tombstones[data.ID]; tombstoned {
+				continue
+			}
+			task, ok := graph.Tasks[data.ID]
+			if !ok {
+				return nil, replayInvariantError(context, event.Type, data.ID, "orphan "+event.Type+" event")
+			}
+			if task.ClaimedBy == "" || task.ClaimedBy != data.AgentID {
+				return nil, replayInvariantError(context, event.Type, data.ID, fmt.Sprintf("lease agent %q does not hold the claim", data.AgentID))
+			}
+			if decoded.kind == eventHeartbeat && task.LeaseExpiresAt.IsZero() {
+				return nil, replayInvariantError(context, event.Type, data.ID, "heartbeat without a lease")
+			}
+			ts, err := parseTime(data.TS)
+			if err != nil {
+				return nil, replayDecodeError(context, event.Type, data.ID, fmt.Errorf("invalid ts: %w", err))
+			}
+			expiresAt, err := parseTime(data.ExpiresAt)
+			if err != nil {
+				return nil, replayDecodeError(context, event.Type, data.ID, fmt.Errorf("invalid expires_at: %w", err))
+			}
+			if !expiresAt.After(ts) {
+				return nil, replayInvariantError(context, event.Type, data.ID, "lease must expire after it is granted")
+			}
+			task.Lease = expiresAt.Sub(ts)
+			task.LeaseExpiresAt = expiresAt
 		case eventLink:
 			data := decoded.payload.(LinkEvent)
 			if _, tombstoned := graph.Tombstones[data.FromID]; tombstoned {
@@ -337,6 +381,51 @@ func replayEventsOnto(graph *Graph, events []Event) (*Graph, error) {
 			}
 			task.Title = data.Title
 			task.UpdatedAt = maxTime(task.UpdatedAt, ts)
+		case eventPriority:
+			data := decoded.payload.(PriorityEvent)
+			if _, tombstoned := graph.Tombstones[data.ID]; tombstoned {
+				continue
+			}
+			task, ok := graph.Tasks[data.ID]
+			if !ok {
+				return nil, replayInvariantError(context, event.Type, data.ID, "orphan priority event")
+			}
+			level, err := normalizePriority(data.Priority)
+			if err != nil || level != data.Priority {
+				return nil, replayInvariantError(context, event.Type, data.ID, fmt.Sprintf("invalid priority %q", data.Priority))
+			}
+			ts, err := parseTime(data.TS)
+			if err != nil {
+				return nil, replayDecodeError(context, event.Type, data.ID, fmt.Errorf("invalid ts: %w", err))
+			}
+			task.Priority = data.Priority
+			task.UpdatedAt = maxTime(task.UpdatedAt, ts)
+		case eventLabel, eventUnlabel:
+			data := decoded.payload.(LabelEvent)
+			if _, tombstoned := graph.Tombstones[data.ID]; tombstoned {
+				continue
+			}
+			task, ok := graph.Tasks[data.ID]
+			if !ok {
+				return nil, replayInvariantError(context, event.Type, data.ID, "orphan "+decoded.kind+" event")
+			}
+			label, err := normalizeLabel(data.Label)
+			if err != nil || label != data.Label {
+				return nil, replayInvariantError(context, event.Type, data.ID, fmt.Sprintf("invalid label %q", data.Label))
+			}
+			ts, err := parseTime(data.TS)
+			if err != nil {
+				return nil, replayDecodeError(context, event.Type, data.ID, fmt.Errorf("invalid ts: %w", err))
+			}
+			if decoded.kind == eventLabel {
+				task.Labels = sortedUniqueStrings(append(slices.Clone(task.Labels), label))
+			} else {
+				task.Labels = slices.DeleteFunc(slices.Clone(task.Labels), func(existing string) bool { return existing == label })
+				if len(task.Labels) == 0 {
+					task.Labels = nil
+				}
+			}
+			task.UpdatedAt = maxTime(task.UpdatedAt, ts)
 		case eventBody:
 			data := decoded.payload.(BodyUpdateEvent)
 			if _, tombstoned := graph.Tombstones[data.ID]; tombstoned {
@@ -382,6 +471,7 @@ func replayEventsOnto(graph *Graph, events []Event) (*Graph, error) {
 			}
 			task.ClaimedBy = ""
 			task.ClaimedAt = time.Time{}
+			clearLease(task)
 			lifecycleSource[data.ID] = replayEventSource{context: context, kind: event.Type}
 		case eventTombstone:
 			data := decoded.payload.(TombstoneEvent)
@@ -450,6 +540,11 @@ func replayEventsOnto(graph *Graph, events []Event) (*Graph, error) {
 	return graph, nil
 }
 
+func clearLease(task *Task) {
+	task.Lease = 0
+	task.LeaseExpiresAt = time.Time{}
+}
+
 func applyTombstone(graph *Graph, id string, info TombstoneInfo) {
 	if graph == nil {
 		return
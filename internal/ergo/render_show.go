@@ -20,6 +20,10 @@ func printTaskDocument(w io.Writer, task *Task, graph *Graph, journal []JournalE
 		{key: "id", value: task.ID, style: colorCyan},
 		{key: "title", value: task.Title},
 		{key: "state", value: task.State, style: stateColor(task)},
+		{key: "priority", value: effectivePriority(task)},
+	}
+	if len(task.Labels) > 0 {
+		fields = append(fields, frontMatterField{key: "labels", value: yamlStringList(task.Labels), raw: true})
 	}
 	if task.EpicID != "" {
 		fields = append(fields, frontMatterField{key: "parent", value: task.EpicID, style: colorCyan})
@@ -29,10 +33,18 @@ func printTaskDocument(w io.Writer, task *Task, graph *Graph, journal []JournalE
 		if claimedAt := claimedAtForTask(task); claimedAt != "" {
 			fields = append(fields, frontMatterField{key: "claimed_at", value: claimedAt, style: colorDim})
 		}
+		if !task.LeaseExpiresAt.IsZero() {
+			field := frontMatterField{key: "lease_expires_at", value: formatTime(task.LeaseExpiresAt), style: colorDim}
+			if graph.LeaseExpired(task.ID) {
+				field.style = colorRed
+			}
+			fields = append(fields, field)
+		}
 	}
 	fields = append(fields,
 		frontMatterField{key: "created_at", value: formatTime(task.CreatedAt), style: colorDim},
 		frontMatterField{key: "updated_at", value: formatTime(task.UpdatedAt), style: colorDim},
+		frontMatterField{key: "revision", value: graph.Revision(task.ID), style: colorDim},
 	)
 	writeShowFrontMatter(w, fields, useColor)
 
@@ -59,6 +71,7 @@ func printContainerDocument(w io.Writer, epic *Task, children []*Task, graph *Gr
 	fields = append(fields,
 		frontMatterField{key: "created_at", value: formatTime(epic.CreatedAt), style: colorDim},
 		frontMatterField{key: "updated_at", value: formatTime(epic.UpdatedAt), style: colorDim},
+		frontMatterField{key: "revision", value: graph.Revision(epic.ID), style: colorDim},
 	)
 	writeShowFrontMatter(w, fields, useColor)
 
@@ -75,8 +88,17 @@ func printContainerDocument(w io.Writer, epic *Task, children []*Task, graph *Gr
 		writeChildHeading(w, child, useColor)
 		fmt.Fprint(w, "- state: ")
 		writeGeneratedLine(w, child.State, stateColor(child), useColor)
+		if child.Priority != "" {
+			fmt.Fprintf(w, "- priority: %s\n", child.Priority)
+		}
+		if len(child.Labels) > 0 {
+			fmt.Fprintf(w, "- labels: %s\n", strings.Join(child.Labels, ", "))
+		}
 		if child.ClaimedBy != "" {
 			fmt.Fprintf(w, "- claimed by: %s\n", child.ClaimedBy)
+			if graph.LeaseExpired(child.ID) {
+				fmt.Fprintf(w, "- lease expired: %s\n", formatTime(child.LeaseExpiresAt))
+			}
 		}
 		fmt.Fprintln(w)
 
@@ -110,6 +132,15 @@ func writeShowFrontMatter(w io.Writer, fields []frontMatterField, useColor bool)
 	fmt.Fprintln(w)
 }
 
+// yamlStringList renders values as a one-line YAML flow sequence.
+func yamlStringList(values []string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = yamlString(value)
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
 func writeGenerated(w io.Writer, text, style string, useColor bool) {
 	if useColor && style != "" {
 		fmt.Fprint(w, style, text, colorReset)
@@ -10,7 +10,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 )
 
 // Repository is an opened Ergo repository.
@@ -24,6 +26,8 @@ type Repository struct {
 	lockPath    string
 	opts        GlobalOptions
 	io          repositoryIO
+	// asOf, when set, limits views to transactions recorded at or before it.
+	asOf time.Time
 }
 
 type UpdateOutcome struct {
@@ -41,6 +45,10 @@ type CompactOutcome struct {
 type InitializeOutcome struct {
 	Path   string
 	Status string
+	// GitAttributes is set when init routed the logs through the git merge
+	// driver; GitConfigured reports whether local git config now defines it.
+	GitAttributes string
+	GitConfigured bool
 }
 
 func InitializeRepository(dir string) (InitializeOutcome, error) {
@@ -143,11 +151,11 @@ func (r *Repository) View() (*Graph, error) {
 	var graph *Graph
 	err := withLock(r.lockPath, r.opts, func() error {
 		var err error
-		graph, err = r.load()
+		graph, err = r.loadView()
 		if err != nil {
 			return err
 		}
-		journal, err := r.loadJournal()
+		journal, err := r.loadViewJournal()
 		if err != nil {
 			return err
 		}
@@ -166,7 +174,7 @@ func (r *Repository) ViewGraph() (*Graph, error) {
 	var graph *Graph
 	err := withLock(r.lockPath, r.opts, func() error {
 		var err error
-		graph, err = r.load()
+		graph, err = r.loadView()
 		return err
 	})
 	return graph, err
@@ -180,11 +188,11 @@ func (r *Repository) ViewWithJournal() (*Graph, []JournalEntry, error) {
 	var journal []JournalEntry
 	err := withLock(r.lockPath, r.opts, func() error {
 		var err error
-		graph, err = r.load()
+		graph, err = r.loadView()
 		if err != nil {
 			return err
 		}
-		journal, err = r.loadJournal()
+		journal, err = r.loadViewJournal()
 		if err != nil {
 			return err
 		}
@@ -229,6 +237,16 @@ func (r *Repository) Update(fn func(*Graph) ([]Event, error)) (UpdateOutcome, er
 // UpdateWithJournal writes validated graph state before its journal side effect
 // while both files remain under the same repository lock.
 func (r *Repository) UpdateWithJournal(fn func(*Graph) ([]Event, []JournalEntry, error)) (UpdateOutcome, error) {
+	return r.updateDroppingJournal(func(graph *Graph) ([]Event, []JournalEntry, []string, error) {
+		events, journal, err := fn(graph)
+		return events, journal, nil, err
+	})
+}
+
+// updateDroppingJournal is UpdateWithJournal for writes that tombstone tasks:
+// fn also names tasks whose journal entries go, as prune drops them, so no
+// entry outlives its task.
+func (r *Repository) updateDroppingJournal(fn func(*Graph) ([]Event, []JournalEntry, []string, error)) (UpdateOutcome, error) {
 	if r == nil || r.eventsPath == "" {
 		return UpdateOutcome{}, errors.New("repository is not open")
 	}
@@ -246,7 +264,7 @@ func (r *Repository) UpdateWithJournal(fn func(*Graph) ([]Event, []JournalEntry,
 		existingJournal = mergeLegacyJournal(existingJournal, graph)
 		hydrateGraphEvidence(graph, existingJournal)
 		working := cloneGraph(graph)
-		events, journal, err := fn(working)
+		events, journal, dropped, err := fn(working)
 		if err != nil {
 			return err
 		}
@@ -258,7 +276,17 @@ func (r *Repository) UpdateWithJournal(fn func(*Graph) ([]Event, []JournalEntry,
 			return err
 		}
 		outcome.Graph = candidate
-		if err := r.appendJournalValidated(journal, journalRead); err != nil {
+		if len(dropped) > 0 {
+			// existingJournal may share its array with the read entries, so
+			// filter copies of each.
+			isDropped := func(entry JournalEntry) bool { return slices.Contains(dropped, entry.TaskID) }
+			existingJournal = slices.DeleteFunc(slices.Clone(existingJournal), isDropped)
+			retained := slices.DeleteFunc(slices.Clone(journalRead.entries), isDropped)
+			err = r.replaceJournal(append(retained, journal...))
+		} else {
+			err = r.appendJournalValidated(journal, journalRead)
+		}
+		if err != nil {
 			return fmt.Errorf("backlog changed, but journal update failed: %w", err)
 		}
 		outcome.Journal = append(existingJournal, journal...)
@@ -1,7 +1,7 @@
 package ergo
 
 import (
-	"errors"
+	"fmt"
 	"time"
 )
 
@@ -16,6 +16,9 @@ func runBulkCreate(dir string, opts GlobalOptions, epicTitle string, epicBody st
 	var out bulkCreateOutput
 	if _, err := repository.UpdateWithJournal(func(graph *Graph) ([]Event, []JournalEntry, error) {
 		working := graph
+		if err := validateEpicAfter(working, tasks); err != nil {
+			return nil, nil, err
+		}
 		workingIDs := make(map[string]*Task, len(working.Tasks)+len(tasks)+1)
 		for id, task := range working.Tasks {
 			workingIDs[id] = task
@@ -84,6 +87,7 @@ func runBulkCreate(dir string, opts GlobalOptions, epicTitle string, epicBody st
 				Title:     taskTitle,
 				Body:      taskBody,
 				CreatedAt: formatTime(taskNow),
+				Priority:  taskInput.Priority,
 			})
 			if err != nil {
 				return nil, nil, err
@@ -107,7 +111,10 @@ func runBulkCreate(dir string, opts GlobalOptions, epicTitle string, epicBody st
 			fromTitle := taskInput.Title
 			fromID := titleToID[fromTitle]
 			for _, dep := range taskInput.After {
-				toID := titleToID[dep]
+				toID, sibling := titleToID[dep]
+				if !sibling {
+					toID = dep
+				}
 				edgeKey := fromID + "->" + toID
 				if _, exists := seenEdges[edgeKey]; exists {
 					continue
@@ -115,10 +122,10 @@ func runBulkCreate(dir string, opts GlobalOptions, epicTitle string, epicBody st
 				seenEdges[edgeKey] = struct{}{}
 
 				if err := validateDepSelf(fromID, toID); err != nil {
-					return nil, nil, err
+					return nil, nil, classified(ErrorUsage, fmt.Errorf("%q: %w", fromTitle, err))
 				}
 				if hasCycle(working, fromID, toID) {
-					return nil, nil, errors.New("dependency would create a cycle")
+					return nil, nil, classified(ErrorUsage, fmt.Errorf("%q after %q would create a cycle", fromTitle, dep))
 				}
 
 				linkNow := time.Now().UTC()
@@ -148,3 +155,26 @@ func runBulkCreate(dir string, opts GlobalOptions, epicTitle string, epicBody st
 	}
 	return out, nil
 }
+
+// validateEpicAfter checks that every After reference names a sibling title or
+// a live task before anything is written. Sibling titles win over IDs.
+func validateEpicAfter(graph *Graph, tasks []EpicTaskInput) error {
+	titles := make(map[string]struct{}, len(tasks))
+	for _, task := range tasks {
+		titles[task.Title] = struct{}{}
+	}
+	for _, task := range tasks {
+		for _, ref := range task.After {
+			if _, sibling := titles[ref]; sibling {
+				continue
+			}
+			if _, pruned := graph.Tombstones[ref]; pruned {
+				return classified(ErrorUsage, fmt.Errorf("%q: After: %w", task.Title, prunedErr(ref)))
+			}
+			if _, exists := graph.Tasks[ref]; !exists {
+				return classified(ErrorUsage, fmt.Errorf("%q: After names %q, which is neither a title in this file nor a task id", task.Title, ref))
+			}
+		}
+	}
+	return nil
+}
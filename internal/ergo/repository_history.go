@@ -0,0 +1,169 @@
+// Purpose: Load read-only views of the backlog as it stood at a past instant.
+// Exports: Repository.ViewAsOf.
+// Role: Replay a prefix of the selected log through the ordinary reducer.
+// Invariants: history views never write; updates always act on the whole log.
+// Notes: compaction discards the history behind its snapshot, so instants
+// before the first transaction that follows a snapshot are refused.
+package ergo
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var errHistoryCompacted = errors.New("history before the last compaction is not retained")
+
+// ViewAsOf pins later View, ViewGraph, and ViewWithJournal calls to the
+// backlog as it stood at an instant. The zero time restores the present.
+func (r *Repository) ViewAsOf(at time.Time) {
+	r.asOf = at
+}
+
+func (r *Repository) loadView() (*Graph, error) {
+	if r.asOf.IsZero() {
+		return r.load()
+	}
+	read, err := r.io.inspectEvents(r.eventsPath)
+	if err != nil {
+		var pathError *os.PathError
+		if !errors.As(err, &pathError) {
+			return nil, &corruptionError{err: err}
+		}
+		return nil, err
+	}
+	events, err := eventsThrough(read, r.asOf)
+	if err != nil {
+		return nil, err
+	}
+	graph := read.snapshot
+	if graph == nil {
+		graph = newGraph()
+	}
+	if graph, err = replayEventsOnto(graph, events); err != nil {
+		return nil, &corruptionError{err: err}
+	}
+	graph.asOf = r.asOf
+	return graph, nil
+}
+
+func (r *Repository) loadViewJournal() ([]JournalEntry, error) {
+	journal, err := r.loadJournal()
+	if err != nil || r.asOf.IsZero() {
+		return journal, err
+	}
+	kept := journal[:0]
+	for _, entry := range journal {
+		if at, err := parseTime(entry.At); err == nil && !at.After(r.asOf) {
+			kept = append(kept, entry)
+		}
+	}
+	return kept, nil
+}
+
+// eventsThrough keeps the leading transactions recorded at or before at. A
+// transaction is dated by its first event, and replay stops at the first
+// later transaction so the prefix always replays.
+func eventsThrough(read eventLogRead, at time.Time) ([]Event, error) {
+	count := 0
+	for count < len(read.events) {
+		recorded, err := parseTime(read.events[count].TS)
+		if err != nil {
+			return nil, &corruptionError{err: fmt.Errorf("%s:%d: invalid event timestamp: %w",
+				read.events[count].Source.Path, read.events[count].Source.Line, err)}
+		}
+		if recorded.After(at) {
+			break
+		}
+		line := read.events[count].Source.Line
+		for count < len(read.events) && read.events[count].Source.Line == line {
+			count++
+		}
+	}
+	if read.snapshot == nil || count > 0 {
+		return read.events[:count], nil
+	}
+	if len(read.events) > 0 {
+		return nil, fmt.Errorf("%w: the earliest reachable point is %s, the first transaction after it",
+			errHistoryCompacted, read.events[0].TS)
+	}
+	if at.Before(time.Now()) {
+		return nil, fmt.Errorf("%w: no transaction follows it, so only the current backlog is reachable", errHistoryCompacted)
+	}
+	return nil, nil
+}
+
+// parseViewInstant accepts an RFC 3339 timestamp or a positive duration that
+// many units before now.
+func parseViewInstant(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if at, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return at.UTC(), nil
+	}
+	ago, ok := parseAgo(value)
+	if !ok {
+		return time.Time{}, fmt.Errorf("invalid --at %q: use an RFC 3339 timestamp or a positive duration ago such as 36h or 7d", value)
+	}
+	return now.Add(-ago).UTC(), nil
+}
+
+// parseAgo accepts a positive Go duration or a whole number of days such as 7d.
+func parseAgo(value string) (time.Duration, bool) {
+	if days, found := strings.CutSuffix(value, "d"); found {
+		count, err := strconv.Atoi(days)
+		return time.Duration(count) * 24 * time.Hour, err == nil && count > 0
+	}
+	ago, err := time.ParseDuration(value)
+	return ago, err == nil && ago > 0
+}
+
+// classifyViewError reports unreachable history as a usage error.
+func classifyViewError(err error) error {
+	if errors.Is(err, errHistoryCompacted) {
+		return classified(ErrorUsage, err)
+	}
+	return classifyRepositoryError(err)
+}
+
+// openView opens the repository and pins it to the instant named by at, if any.
+func (a *Application) openView(at string) (Repository, error) {
+	instant, err := parseViewInstant(at, time.Now())
+	if err != nil {
+		return Repository{}, classified(ErrorUsage, err)
+	}
+	var repository Repository
+	if err := repository.Open(a.repository); err != nil {
+		return Repository{}, classifyRepositoryError(err)
+	}
+	repository.ViewAsOf(instant)
+	return repository, nil
+}
+
+// viewLog reads the selected log and the journal under one lock without
+// replaying either.
+func (r *Repository) viewLog() (eventLogRead, journalRead, error) {
+	if r == nil || r.eventsPath == "" {
+		return eventLogRead{}, journalRead{}, errors.New("repository is not open")
+	}
+	var events eventLogRead
+	var journal journalRead
+	err := withLock(r.lockPath, r.opts, func() error {
+		var err error
+		if events, err = r.io.inspectEvents(r.eventsPath); err != nil {
+			var pathError *os.PathError
+			if !errors.As(err, &pathError) {
+				return &corruptionError{err: err}
+			}
+			return err
+		}
+		journal, err = r.readJournal()
+		return err
+	})
+	return events, journal, err
+}
@@ -0,0 +1,85 @@
+package ergo
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestListAndShowAtReplayHistoryPrefix(t *testing.T) {
+	app := newTestApplication(t)
+	first, err := app.CreateTask(CreateTaskRequest{Title: "First"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := formatTime(time.Now())
+	if _, err := app.Claim(ClaimRequest{ID: first.ID, AgentID: "worker"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.Lifecycle(LifecycleRequest{Kind: "done", ID: first.ID, Messages: []string{"Shipped"}}); err != nil {
+		t.Fatal(err)
+	}
+	second, err := app.CreateTask(CreateTaskRequest{Title: "Second"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	listed, err := app.List(ListRequest{ShowAll: true, At: before})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(listed.AllTasks) != 1 || listed.AllTasks[0].ID != first.ID || listed.AllTasks[0].State != stateTodo {
+		t.Fatalf("past list = %#v", listed.AllTasks)
+	}
+	shown, err := app.Show(ShowRequest{ID: first.ID, At: before})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shown.Task.State != stateTodo || len(shown.Journal) != 1 || shown.Journal[0].Kind != "created" {
+		t.Fatalf("past show = %s with journal %#v", shown.Task.State, shown.Journal)
+	}
+	_, err = app.Show(ShowRequest{ID: second.ID, At: before})
+	requireApplicationError(t, err, ErrorNotFound)
+
+	current, err := app.Show(ShowRequest{ID: first.ID, At: "1ns"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current.Task.State != stateDone {
+		t.Fatalf("recent show state = %s", current.Task.State)
+	}
+	_, err = app.List(ListRequest{At: "yesterday"})
+	requireApplicationError(t, err, ErrorUsage)
+}
+
+func TestViewAtReportsEarliestPointAfterCompaction(t *testing.T) {
+	app := newTestApplication(t)
+	if _, err := app.CreateTask(CreateTaskRequest{Title: "Compacted"}); err != nil {
+		t.Fatal(err)
+	}
+	before := formatTime(time.Now())
+	if _, err := app.Compact(); err != nil {
+		t.Fatal(err)
+	}
+	_, err := app.List(ListRequest{At: before})
+	requireApplicationError(t, err, ErrorUsage)
+	if !strings.Contains(err.Error(), "only the current backlog is reachable") {
+		t.Fatalf("error = %v", err)
+	}
+
+	if _, err := app.CreateTask(CreateTaskRequest{Title: "Later"}); err != nil {
+		t.Fatal(err)
+	}
+	_, err = app.List(ListRequest{At: before})
+	requireApplicationError(t, err, ErrorUsage)
+	if !strings.Contains(err.Error(), "the earliest reachable point is ") {
+		t.Fatalf("error = %v", err)
+	}
+	listed, err := app.List(ListRequest{At: formatTime(time.Now())})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(listed.AllTasks) != 2 {
+		t.Fatalf("listed %d tasks, want 2", len(listed.AllTasks))
+	}
+}
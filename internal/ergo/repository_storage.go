@@ -320,67 +320,27 @@ func writeAllWith(w *os.File, data []byte, write func(*os.File, []byte) (int, er
 	return nil
 }
 
-func createTask(dir string, opts RepositoryOptions, epicID string, title, body string, draft bool) (createOutput, error) {
+// newTaskInput is one validated single-task creation.
+type newTaskInput struct {
+	EpicID, Title, Body, Priority string
+	Labels                        []string
+	Draft                         bool
+}
+
+func createTask(dir string, opts RepositoryOptions, input newTaskInput) (createOutput, error) {
 	var repository Repository
 	if err := repository.openAt(dir, opts, systemRepositoryIO()); err != nil {
 		return createOutput{}, err
 	}
 	var output createOutput
 	update, err := repository.UpdateWithJournal(func(graph *Graph) ([]Event, []JournalEntry, error) {
-		if epicID != "" {
-			epic, ok := graph.Tasks[epicID]
-			if !ok {
-				return nil, nil, classified(ErrorNotFound, fmt.Errorf("unknown epic id %s", epicID))
-			}
-			if epic.EpicID != "" {
-				return nil, nil, classified(ErrorConflict, fmt.Errorf("task %s is not an epic", epicID))
-			}
-			// Reject first-child assignment to a dirty leaf: once promoted to a
-			// container, leaf-only semantics (state/claim/results) no longer apply.
-			if !graph.IsEpic(epic.ID) {
-				if err := validateEpicPromotion(epic); err != nil {
-					return nil, nil, classified(ErrorConflict, fmt.Errorf("cannot add child to task %s: %w", epicID, err))
-				}
-			}
-		}
-		id, err := newShortID(graph.Tasks)
-		if err != nil {
-			return nil, nil, err
-		}
-		uuid, err := newUUID()
-		if err != nil {
-			return nil, nil, err
-		}
 		now := time.Now().UTC()
-		createdAt := formatTime(now)
-		state := stateTodo
-		if draft {
-			state = stateDraft
-		}
-		payload := NewTaskEvent{
-			ID:        id,
-			UUID:      uuid,
-			EpicID:    epicID,
-			State:     state,
-			Title:     title,
-			Body:      body,
-			CreatedAt: createdAt,
-		}
-		event, err := newEvent("new_task", now, payload)
+		event, created, err := buildNewTask(graph, input, now)
 		if err != nil {
 			return nil, nil, err
 		}
-
-		output = createOutput{
-			ID:        id,
-			UUID:      uuid,
-			EpicID:    payload.EpicID,
-			State:     payload.State,
-			Title:     payload.Title,
-			Body:      payload.Body,
-			CreatedAt: createdAt,
-		}
-		return []Event{event}, []JournalEntry{newJournalEntry(id, "created", "", "", now)}, nil
+		output = created
+		return []Event{event}, []JournalEntry{newJournalEntry(created.ID, "created", "", "", now)}, nil
 	})
 	if err != nil {
 		return createOutput{}, err
@@ -392,6 +352,67 @@ func createTask(dir string, opts RepositoryOptions, epicID string, title, body s
 	return output, nil
 }
 
+// buildNewTask validates input against graph and returns the event creating
+// the task under a fresh ID.
+func buildNewTask(graph *Graph, input newTaskInput, now time.Time) (Event, createOutput, error) {
+	epicID := input.EpicID
+	if epicID != "" {
+		epic, ok := graph.Tasks[epicID]
+		if !ok {
+			return Event{}, createOutput{}, classified(ErrorNotFound, fmt.Errorf("unknown epic id %s", epicID))
+		}
+		if epic.EpicID != "" {
+			return Event{}, createOutput{}, classified(ErrorConflict, fmt.Errorf("task %s is not an epic", epicID))
+		}
+		// Reject first-child assignment to a dirty leaf: once promoted to a
+		// container, leaf-only semantics (state/claim/results) no longer apply.
+		if !graph.IsEpic(epic.ID) {
+			if err := validateEpicPromotion(epic); err != nil {
+				return Event{}, createOutput{}, classified(ErrorConflict, fmt.Errorf("cannot add child to task %s: %w", epicID, err))
+			}
+		}
+	}
+	id, err := newShortID(graph.Tasks)
+	if err != nil {
+		return Event{}, createOutput{}, err
+	}
+	uuid, err := newUUID()
+	if err != nil {
+		return Event{}, createOutput{}, err
+	}
+	createdAt := formatTime(now)
+	state := stateTodo
+	if input.Draft {
+		state = stateDraft
+	}
+	payload := NewTaskEvent{
+		ID:        id,
+		UUID:      uuid,
+		EpicID:    epicID,
+		State:     state,
+		Title:     input.Title,
+		Body:      input.Body,
+		CreatedAt: createdAt,
+		Priority:  input.Priority,
+		Labels:    input.Labels,
+	}
+	event, err := newEvent("new_task", now, payload)
+	if err != nil {
+		return Event{}, createOutput{}, err
+	}
+
+	output := createOutput{
+		ID:        id,
+		UUID:      uuid,
+		EpicID:    payload.EpicID,
+		State:     payload.State,
+		Title:     payload.Title,
+		Body:      payload.Body,
+		CreatedAt: createdAt,
+	}
+	return event, output, nil
+}
+
 // ResultEvidence holds evidence metadata captured when attaching a result.
 type ResultEvidence struct {
 	Sha256AtAttach    string
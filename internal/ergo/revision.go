@@ -0,0 +1,95 @@
+// Purpose: Name the stored state of one task for compare-and-swap writes.
+// Exports: Graph.Revision.
+// Role: Shown by show and list --json, and checked under the lock by every
+// mutation that carries an expected revision.
+// Invariants: the revision is a hash of the task's event state and its own
+// dependency edges, so replicas, merges, and compaction agree on it.
+// Notes: journal evidence and lease renewals do not change it; a result or a
+// heartbeat never invalidates another writer's revision.
+package ergo
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// revisionLength is the number of hex digits shown; 48 bits keep accidental
+// matches out of reach for one task's history.
+const revisionLength = 12
+
+type revisionState struct {
+	EpicID    string   `json:"epic_id"`
+	State     string   `json:"state"`
+	Title     string   `json:"title"`
+	Body      string   `json:"body"`
+	ClaimedBy string   `json:"claimed_by"`
+	ClaimedAt string   `json:"claimed_at"`
+	UpdatedAt string   `json:"updated_at"`
+	Priority  string   `json:"priority"`
+	Labels    []string `json:"labels"`
+	DependsOn []string `json:"depends_on"`
+}
+
+// Revision returns the revision of task id, or "" when it does not exist.
+func (graph *Graph) Revision(id string) string {
+	task := graph.Tasks[id]
+	if task == nil {
+		return ""
+	}
+	claimedAt := ""
+	if !task.ClaimedAt.IsZero() {
+		claimedAt = formatTime(task.ClaimedAt)
+	}
+	// Strings and string slices always marshal.
+	data, _ := json.Marshal(revisionState{
+		EpicID: task.EpicID, State: task.State, Title: task.Title, Body: task.Body,
+		ClaimedBy: task.ClaimedBy, ClaimedAt: claimedAt, UpdatedAt: formatTime(task.UpdatedAt),
+		Priority: task.Priority, Labels: task.Labels, DependsOn: sortedKeys(graph.Deps[id]),
+	})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:revisionLength]
+}
+
+// checkRevision refuses a write to id when want is set and the task has moved
+// past it. Callers check existence first.
+func checkRevision(graph *Graph, id, want string) error {
+	if want == "" {
+		return nil
+	}
+	if current := graph.Revision(id); current != want {
+		return classified(ErrorConflict, fmt.Errorf("task %s is at revision %s, not %s", id, current, want))
+	}
+	return nil
+}
+
+// checkRevisions applies checkRevision to every task in want that exists,
+// in ID order; unknown IDs are left for the write itself to report.
+func checkRevisions(graph *Graph, want map[string]string) error {
+	for _, id := range sortedKeys(want) {
+		if graph.Tasks[id] == nil {
+			continue
+		}
+		if err := checkRevision(graph, id, want[id]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkRevisionTargets refuses expected revisions for tasks outside ids, and
+// blank ones, so a mistyped ID cannot make a precondition silently vanish.
+func checkRevisionTargets(ids []string, want map[string]string) error {
+	for _, id := range sortedKeys(want) {
+		if !slices.Contains(ids, id) {
+			return fmt.Errorf("a revision is expected for %s, which is not in the sequence", id)
+		}
+		if strings.TrimSpace(want[id]) == "" {
+			return fmt.Errorf("the expected revision for %s is empty", id)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package ergo
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRevisionGuardsMutationsAndSurvivesCompaction(t *testing.T) {
+	app := newTestApplication(t)
+	created, err := app.CreateTask(CreateTaskRequest{Title: "Guarded"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := created.ID
+	read := mustList(t, app).Revision(id)
+	if len(read) != revisionLength {
+		t.Fatalf("revision = %q", read)
+	}
+
+	if _, err := app.UpdateTitle(UpdateTitleRequest{ID: id, Title: "Renamed", IfRevision: read}); err != nil {
+		t.Fatal(err)
+	}
+	stale := read
+	read = mustList(t, app).Revision(id)
+	if read == stale {
+		t.Fatal("title change kept the revision")
+	}
+	_, err = app.UpdateBody(UpdateBodyRequest{ID: id, Body: []byte("Lost\n"), IfRevision: stale})
+	requireApplicationError(t, err, ErrorConflict)
+	if !strings.Contains(err.Error(), "revision "+read) {
+		t.Fatalf("stale body err = %v", err)
+	}
+	if body := mustList(t, app).Tasks[id].Body; body != "" {
+		t.Fatalf("stale write stored %q", body)
+	}
+	for name, call := range map[string]func() error{
+		"lifecycle": func() error {
+			_, err := app.Lifecycle(LifecycleRequest{Kind: "block", ID: id, IfRevision: stale})
+			return err
+		},
+		"claim": func() error {
+			_, err := app.Claim(ClaimRequest{ID: id, AgentID: "a", IfRevision: stale})
+			return err
+		},
+		"result": func() error {
+			_, err := app.Result(ResultRequest{ID: id, Text: "Noted", IfRevision: stale})
+			return err
+		},
+		"move": func() error {
+			_, err := app.Move(MoveRequest{ID: id, ToRoot: true, IfRevision: stale})
+			return err
+		},
+	} {
+		if err := call(); err == nil {
+			t.Errorf("%s accepted a stale revision", name)
+		} else {
+			requireApplicationError(t, err, ErrorConflict)
+		}
+	}
+
+	// Journal evidence and lease renewals leave the revision alone.
+	if _, err := app.Claim(ClaimRequest{ID: id, AgentID: "a", Lease: time.Hour, IfRevision: read}); err != nil {
+		t.Fatal(err)
+	}
+	read = mustList(t, app).Revision(id)
+	if _, err := app.Result(ResultRequest{ID: id, Text: "Halfway", IfRevision: read}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.Heartbeat(HeartbeatRequest{ID: id, AgentID: "a", IfRevision: read}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.Compact(); err != nil {
+		t.Fatal(err)
+	}
+	if got := mustList(t, app).Revision(id); got != read {
+		t.Fatalf("revision after result, heartbeat, and compact = %s, want %s", got, read)
+	}
+}
+
+func TestSequenceChecksTheRevisionOfEachNamedTask(t *testing.T) {
+	app := newTestApplication(t)
+	var ids []string
+	for _, title := range []string{"First", "Second"} {
+		created, err := app.CreateTask(CreateTaskRequest{Title: title})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, created.ID)
+	}
+	read := mustList(t, app).Revision(ids[1])
+	link := SequenceRequest{Command: "sequence", EventType: eventLink, IDs: ids, IfRevisions: map[string]string{ids[1]: read}}
+	if _, err := app.Sequence(link); err != nil {
+		t.Fatal(err)
+	}
+	// The link changed the dependent's revision, so the same precondition
+	// now refuses to undo it.
+	_, err := app.Sequence(SequenceRequest{Command: "unsequence", EventType: eventUnlink, IDs: ids, IfRevisions: link.IfRevisions})
+	requireApplicationError(t, err, ErrorConflict)
+	if blockers := mustList(t, app).Blockers(ids[1]); len(blockers) != 1 {
+		t.Fatalf("stale unsequence removed the edge: %v", blockers)
+	}
+	_, err = app.Sequence(SequenceRequest{Command: "sequence", EventType: eventLink, IDs: ids, IfRevisions: map[string]string{"ZZZZZZ": read}})
+	requireApplicationError(t, err, ErrorUsage)
+}
+
+func TestBatchChecksRevisionsAgainstTheBacklogBeforeIt(t *testing.T) {
+	app := newTestApplication(t)
+	created, err := app.CreateTask(CreateTaskRequest{Title: "Batched"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	read := mustList(t, app).Revision(created.ID)
+	input := `{"op":"title","id":"` + created.ID + `","title":"Renamed","if_revision":"` + read + `"}
+{"op":"body","id":"` + created.ID + `","body":"Text","if_revision":"` + read + `"}`
+	if _, err := app.Batch(BatchRequest{Input: []byte(input)}); err != nil {
+		t.Fatal(err)
+	}
+	_, err = app.Batch(BatchRequest{Input: []byte(`{"op":"done","id":"` + created.ID + `","if_revision":"` + read + `"}`)})
+	requireApplicationError(t, err, ErrorConflict)
+	other, err := app.CreateTask(CreateTaskRequest{Title: "Other"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := `"ids":["` + other.ID + `","` + created.ID + `"]`
+	_, err = app.Batch(BatchRequest{Input: []byte(`{"op":"sequence",` + ids + `,"if_revisions":{"` + created.ID + `":"` + read + `"}}`)})
+	requireApplicationError(t, err, ErrorConflict)
+	current := mustList(t, app).Revision(created.ID)
+	if _, err := app.Batch(BatchRequest{Input: []byte(`{"op":"sequence",` + ids + `,"if_revisions":{"` + created.ID + `":"` + current + `"}}`)}); err != nil {
+		t.Fatal(err)
+	}
+	for _, input := range []string{
+		`{"op":"create","title":"A","if_revision":"abc"}`,
+		`{"op":"create","handle":"a","title":"A"}` + "\n" + `{"op":"done","id":"$a","if_revision":"abc"}`,
+		`{"op":"sequence",` + ids + `,"if_revision":"abc"}`,
+		`{"op":"sequence",` + ids + `,"if_revisions":{"ZZZZZZ":"abc"}}`,
+		`{"op":"done","id":"` + other.ID + `","if_revisions":{"` + other.ID + `":"abc"}}`,
+	} {
+		_, err := app.Batch(BatchRequest{Input: []byte(input)})
+		requireApplicationError(t, err, ErrorUsage)
+	}
+}
@@ -0,0 +1,72 @@
+// Purpose: Render search outcomes as readable text or a versioned JSON document.
+// Role: Presentation only; ranking and filtering belong to Application.Search.
+package ergo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const searchJSONVersion = 1
+
+type searchJSONDocument struct {
+	Version int              `json:"version"`
+	Query   string           `json:"query"`
+	Items   []searchJSONItem `json:"items"`
+}
+
+type searchJSONItem struct {
+	ID      string   `json:"id"`
+	Title   string   `json:"title"`
+	Kind    string   `json:"kind"`
+	State   string   `json:"state"`
+	EpicID  string   `json:"epic_id,omitempty"`
+	Matches []string `json:"matches"`
+	Snippet string   `json:"snippet,omitempty"`
+	// SnippetField names the matched field the snippet came from.
+	SnippetField string `json:"snippet_field,omitempty"`
+}
+
+func RenderSearch(w io.Writer, outcome SearchOutcome, useColor bool) {
+	if len(outcome.Hits) == 0 {
+		fmt.Fprintf(w, "No tasks match %q.\n", outcome.Query)
+		return
+	}
+	for _, hit := range outcome.Hits {
+		task := hit.Task
+		state := searchState(outcome.Graph, task)
+		writeGenerated(w, task.ID, colorCyan, useColor)
+		fmt.Fprint(w, "  ")
+		writeGenerated(w, state, stateColor(task), useColor)
+		fmt.Fprintf(w, "  %s", task.Title)
+		writeGeneratedLine(w, "  ("+strings.Join(hit.Fields, ", ")+")", colorDim, useColor)
+		if hit.Snippet != "" {
+			fmt.Fprintf(w, "%s%s: %s\n", strings.Repeat(" ", len(task.ID)+idContentGap), hit.SnippetField, hit.Snippet)
+		}
+	}
+	noun := "tasks"
+	if len(outcome.Hits) == 1 {
+		noun = "task"
+	}
+	fmt.Fprintf(w, "\n%d matching %s\n", len(outcome.Hits), noun)
+}
+
+func RenderSearchJSON(w io.Writer, outcome SearchOutcome) error {
+	document := searchJSONDocument{Version: searchJSONVersion, Query: outcome.Query, Items: make([]searchJSONItem, 0, len(outcome.Hits))}
+	for _, hit := range outcome.Hits {
+		item := searchJSONItem{
+			ID: hit.Task.ID, Title: hit.Task.Title, Kind: "task",
+			State: searchState(outcome.Graph, hit.Task), EpicID: hit.Task.EpicID,
+			Matches: hit.Fields, Snippet: hit.Snippet, SnippetField: hit.SnippetField,
+		}
+		if outcome.Graph.IsEpic(hit.Task.ID) {
+			item.Kind = "epic"
+		}
+		document.Items = append(document.Items, item)
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(document)
+}
@@ -39,7 +39,7 @@ func TestApplicationShowBodyDoesNotReadJournal(t *testing.T) {
 	if err := os.WriteFile(filepath.Join(dir, journalFileName), []byte("{}\n"), 0644); err != nil {
 		t.Fatal(err)
 	}
-	outcome, err := app.ShowBody(ShowBodyRequest(created))
+	outcome, err := app.ShowBody(ShowBodyRequest{ID: created.ID})
 	if err != nil || outcome.Body != "literal body\n" {
 		t.Fatalf("show body = %#v, %v", outcome, err)
 	}
@@ -61,7 +61,7 @@ func TestApplicationShowBodyLeafRoundTripIsLossless(t *testing.T) {
 			if err != nil {
 				t.Fatal(err)
 			}
-			projected, err := app.ShowBody(ShowBodyRequest(created))
+			projected, err := app.ShowBody(ShowBodyRequest{ID: created.ID})
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -72,7 +72,7 @@ func TestApplicationShowBodyLeafRoundTripIsLossless(t *testing.T) {
 			if _, err := app.UpdateBody(UpdateBodyRequest{ID: created.ID, Body: pipe.Bytes()}); err != nil {
 				t.Fatal(err)
 			}
-			roundTripped, err := app.ShowBody(ShowBodyRequest(created))
+			roundTripped, err := app.ShowBody(ShowBodyRequest{ID: created.ID})
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -94,7 +94,7 @@ func TestApplicationShowBodyEpicOmitsChildren(t *testing.T) {
 	}); err != nil {
 		t.Fatal(err)
 	}
-	outcome, err := app.ShowBody(ShowBodyRequest(epic))
+	outcome, err := app.ShowBody(ShowBodyRequest{ID: epic.ID})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -118,7 +118,7 @@ func TestApplicationShowBodyUnknownAndPrunedFailures(t *testing.T) {
 	if _, err := app.Prune(PruneRequest{Confirm: true}); err != nil {
 		t.Fatal(err)
 	}
-	_, err = app.ShowBody(ShowBodyRequest(created))
+	_, err = app.ShowBody(ShowBodyRequest{ID: created.ID})
 	requireApplicationError(t, err, ErrorNotFound)
 }
 
@@ -10,6 +10,7 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"slices"
 	"time"
 )
 
@@ -33,18 +34,22 @@ type snapshotManifest struct {
 }
 
 type snapshotTaskRecord struct {
-	Type         string `json:"type"`
-	ID           string `json:"id"`
-	UUID         string `json:"uuid"`
-	EpicID       string `json:"epic_id"`
-	ExplicitEpic bool   `json:"explicit_epic"`
-	State        string `json:"state"`
-	Title        string `json:"title"`
-	Body         string `json:"body"`
-	ClaimedBy    string `json:"claimed_by"`
-	ClaimedAt    string `json:"claimed_at"`
-	CreatedAt    string `json:"created_at"`
-	UpdatedAt    string `json:"updated_at"`
+	Type         string   `json:"type"`
+	ID           string   `json:"id"`
+	UUID         string   `json:"uuid"`
+	EpicID       string   `json:"epic_id"`
+	ExplicitEpic bool     `json:"explicit_epic"`
+	State        string   `json:"state"`
+	Title        string   `json:"title"`
+	Body         string   `json:"body"`
+	ClaimedBy    string   `json:"claimed_by"`
+	ClaimedAt    string   `json:"claimed_at"`
+	CreatedAt    string   `json:"created_at"`
+	UpdatedAt    string   `json:"updated_at"`
+	Priority     string   `json:"priority,omitempty"`
+	Labels       []string `json:"labels,omitempty"`
+	Lease        string   `json:"lease,omitempty"`
+	LeaseExpires string   `json:"lease_expires_at,omitempty"`
 }
 
 type snapshotResultRecord struct {
@@ -126,12 +131,18 @@ func snapshotRecords(graph *Graph) ([]any, snapshotManifest, error) {
 		if !task.ClaimedAt.IsZero() {
 			claimedAt = formatTime(task.ClaimedAt)
 		}
-		records = append(records, snapshotTaskRecord{
+		record := snapshotTaskRecord{
 			Type: snapshotTaskRecordType, ID: task.ID, UUID: task.UUID,
 			EpicID: task.EpicID, ExplicitEpic: explicit, State: task.State,
 			Title: task.Title, Body: task.Body, ClaimedBy: task.ClaimedBy,
 			ClaimedAt: claimedAt, CreatedAt: formatTime(task.CreatedAt), UpdatedAt: formatTime(task.UpdatedAt),
-		})
+			Priority: task.Priority, Labels: task.Labels,
+		}
+		if !task.LeaseExpiresAt.IsZero() {
+			record.Lease = task.Lease.String()
+			record.LeaseExpires = formatTime(task.LeaseExpiresAt)
+		}
+		records = append(records, record)
 	}
 	fromIDs := sortedMapKeys(graph.Deps)
 	for _, from := range fromIDs {
@@ -224,11 +235,34 @@ func (decoder *snapshotBlockDecoder) consume(line int, raw []byte) error {
 		if record.ExplicitEpic && record.EpicID != "" {
 			return fmt.Errorf("%s:%d: explicit snapshot epic %s cannot have a parent", decoder.path, line, record.ID)
 		}
-		decoder.graph.Tasks[record.ID] = &Task{
+		if record.Priority != "" {
+			if level, err := normalizePriority(record.Priority); err != nil || level != record.Priority {
+				return fmt.Errorf("%s:%d: snapshot task %s has invalid priority %q", decoder.path, line, record.ID, record.Priority)
+			}
+		}
+		if labels, err := normalizeLabels(record.Labels); err != nil || !slices.Equal(labels, record.Labels) {
+			return fmt.Errorf("%s:%d: snapshot task %s has invalid labels %q", decoder.path, line, record.ID, record.Labels)
+		}
+		task := &Task{
 			ID: record.ID, UUID: record.UUID, EpicID: record.EpicID, State: record.State,
 			Title: record.Title, Body: record.Body, ClaimedBy: record.ClaimedBy, ClaimedAt: claimedAt,
-			CreatedAt: createdAt, UpdatedAt: updatedAt,
+			CreatedAt: createdAt, UpdatedAt: updatedAt, Priority: record.Priority,
+			Labels: record.Labels,
+		}
+		if record.Lease != "" || record.LeaseExpires != "" {
+			if record.ClaimedBy == "" {
+				return fmt.Errorf("%s:%d: snapshot task %s has a lease without claimed_by", decoder.path, line, record.ID)
+			}
+			task.Lease, err = time.ParseDuration(record.Lease)
+			if err != nil || task.Lease <= 0 {
+				return fmt.Errorf("%s:%d: snapshot task %s has invalid lease %q", decoder.path, line, record.ID, record.Lease)
+			}
+			task.LeaseExpiresAt, err = parseTime(record.LeaseExpires)
+			if err != nil {
+				return fmt.Errorf("%s:%d: snapshot task %s has invalid lease_expires_at: %w", decoder.path, line, record.ID, err)
+			}
 		}
+		decoder.graph.Tasks[record.ID] = task
 		if record.ExplicitEpic {
 			decoder.graph.legacyEmptyEpics[record.ID] = struct{}{}
 		}
@@ -0,0 +1,127 @@
+// Purpose: Render stats outcomes as a readable report or a versioned JSON document.
+// Role: Presentation only; counting belongs to Application.Stats.
+package ergo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+const statsJSONVersion = 1
+
+type statsJSONDocument struct {
+	Version           int              `json:"version"`
+	Since             string           `json:"since"`
+	Until             string           `json:"until"`
+	Epic              string           `json:"epic,omitempty"`
+	Agent             string           `json:"agent,omitempty"`
+	States            map[string]int   `json:"states"`
+	Done              int              `json:"done"`
+	Failed            int              `json:"failed"`
+	Canceled          int              `json:"canceled"`
+	FailureRate       float64          `json:"failure_rate"`
+	CancelRate        float64          `json:"cancel_rate"`
+	MedianClaimToDone *float64         `json:"median_claim_to_done_seconds"`
+	Days              []statsJSONDay   `json:"days"`
+	Agents            []statsJSONAgent `json:"agents"`
+}
+
+type statsJSONDay struct {
+	Date     string `json:"date"`
+	Done     int    `json:"done"`
+	Failed   int    `json:"failed"`
+	Canceled int    `json:"canceled"`
+}
+
+type statsJSONAgent struct {
+	Agent             string   `json:"agent"`
+	Claims            int      `json:"claims"`
+	Done              int      `json:"done"`
+	Failed            int      `json:"failed"`
+	MedianClaimToDone *float64 `json:"median_claim_to_done_seconds"`
+}
+
+var allSummaryBuckets = []summaryBucket{
+	summaryReady, summaryDraft, summaryInProgress, summaryBlocked, summaryWaiting,
+	summaryError, summaryFailed, summaryDone, summaryCanceled,
+}
+
+func RenderStats(w io.Writer, outcome StatsOutcome, useColor bool) {
+	writeGeneratedLine(w, "Since "+outcome.Since.Format(time.RFC3339), colorDim, useColor)
+	if outcome.Counts.total == 0 {
+		fmt.Fprintln(w, "No tasks in scope.")
+	} else {
+		renderSummary(w, outcome.Counts, useColor, allSummaryBuckets, false)
+	}
+	fmt.Fprintf(w, "Closed: %d done, %d failed, %d canceled", outcome.Done, outcome.Failed, outcome.Canceled)
+	if outcome.Done+outcome.Failed+outcome.Canceled > 0 {
+		fmt.Fprintf(w, " (failure rate %.0f%%, cancel rate %.0f%%)", outcome.FailureRate()*100, outcome.CancelRate()*100)
+	}
+	fmt.Fprintln(w)
+	if outcome.MeasuredClaimToDone > 0 {
+		fmt.Fprintf(w, "Median claim to done: %s over %s\n", roundDuration(outcome.MedianClaimToDone), pluralize(outcome.MeasuredClaimToDone, "task", "tasks"))
+	}
+	if len(outcome.Days) > 0 {
+		writeGeneratedLine(w, "Per day:", colorBold, useColor)
+		for _, day := range outcome.Days {
+			fmt.Fprintf(w, "  %s  %d done  %d failed  %d canceled\n", day.Date, day.Done, day.Failed, day.Canceled)
+		}
+	}
+	if len(outcome.Agents) > 0 {
+		writeGeneratedLine(w, "Per agent:", colorBold, useColor)
+		for _, agent := range outcome.Agents {
+			fmt.Fprintf(w, "  %s  %d claimed  %d done  %d failed", agent.Agent, agent.Claims, agent.Done, agent.Failed)
+			if agent.MeasuredClaimToDone > 0 {
+				fmt.Fprintf(w, "  median %s", roundDuration(agent.MedianClaimToDone))
+			}
+			fmt.Fprintln(w)
+		}
+	}
+}
+
+func RenderStatsJSON(w io.Writer, outcome StatsOutcome) error {
+	counts := outcome.Counts
+	document := statsJSONDocument{
+		Version: statsJSONVersion, Since: formatTime(outcome.Since), Until: formatTime(outcome.Until),
+		Epic: outcome.EpicID, Agent: outcome.Agent,
+		States: map[string]int{
+			"ready": counts.ready, "draft": counts.draft, "doing": counts.inProgress, "blocked": counts.blocked,
+			"waiting": counts.waiting, "error": counts.errors, "failed": counts.failed, "done": counts.done,
+			"canceled": counts.canceled,
+		},
+		Done: outcome.Done, Failed: outcome.Failed, Canceled: outcome.Canceled,
+		FailureRate: outcome.FailureRate(), CancelRate: outcome.CancelRate(),
+		Days: make([]statsJSONDay, 0, len(outcome.Days)), Agents: make([]statsJSONAgent, 0, len(outcome.Agents)),
+	}
+	if outcome.MeasuredClaimToDone > 0 {
+		document.MedianClaimToDone = durationSeconds(outcome.MedianClaimToDone)
+	}
+	for _, day := range outcome.Days {
+		document.Days = append(document.Days, statsJSONDay(day))
+	}
+	for _, agent := range outcome.Agents {
+		entry := statsJSONAgent{Agent: agent.Agent, Claims: agent.Claims, Done: agent.Done, Failed: agent.Failed}
+		if agent.MeasuredClaimToDone > 0 {
+			entry.MedianClaimToDone = durationSeconds(agent.MedianClaimToDone)
+		}
+		document.Agents = append(document.Agents, entry)
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(document)
+}
+
+func durationSeconds(value time.Duration) *float64 {
+	seconds := value.Seconds()
+	return &seconds
+}
+
+// roundDuration keeps reports readable: seconds under an hour, minutes above.
+func roundDuration(value time.Duration) time.Duration {
+	if value >= time.Hour {
+		return value.Round(time.Minute)
+	}
+	return value.Round(time.Second)
+}
@@ -0,0 +1,767 @@
+// Purpose: Drive the full-screen backlog view behind ergo tui.
+// Exports: TUIOptions, TUITerminal, and RunTUI.
+// Role: Keep a navigable projection of the list tree beside the show document
+// of the selected task, and dispatch keys as Application requests.
+// Invariants: every read and write is one Application call, so the screen
+// holds no lock between keys. The model reloads after each action and after
+// each change the watcher reports.
+// Notes: tuiModel knows nothing about the terminal loop; tests drive it with
+// key names and inspect the frames it draws.
+package ergo
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// TUIOptions configures an interactive session.
+type TUIOptions struct {
+	// AgentID is the identity the claim key claims as; empty disables claims.
+	AgentID string
+	// Stop ends the session as if the user quit.
+	Stop <-chan struct{}
+}
+
+// TUITerminal is the screen RunTUI draws on. The caller puts In into raw
+// mode before RunTUI and restores it afterwards.
+type TUITerminal struct {
+	In    io.Reader
+	Out   io.Writer
+	Color bool
+	// Size reports the screen in columns and rows.
+	Size func() (width, height int)
+	// Edit runs the user's editor on text with the terminal in its ordinary
+	// mode and returns the saved text. Nil disables editing.
+	Edit func(text string) (string, error)
+}
+
+const (
+	// tuiSplitWidth is the narrowest screen that shows the list and the detail
+	// pane side by side; narrower screens show one at a time.
+	tuiSplitWidth = 100
+	tuiGutter     = 2
+	// tuiResizeInterval bounds how long a resized screen keeps its old layout.
+	tuiResizeInterval = 250 * time.Millisecond
+
+	tuiEnterScreen = "\033[?1049h\033[?25l"
+	tuiLeaveScreen = "\033[?25h\033[?1049l"
+	tuiReverse     = "\033[7m"
+)
+
+const tuiKeyHelp = "↑↓ move · tab detail · f view · enter epic · esc back · space mark · s sequence · o open · b block · x cancel · c claim · e edit · q quit"
+
+// tuiView is one entry in the f cycle: a list request, optionally narrowed
+// to leaves in one state.
+type tuiView struct {
+	name    string
+	request ListRequest
+	state   string
+}
+
+var tuiViews = []tuiView{
+	{name: "active"},
+	{name: "ready", request: ListRequest{ReadyOnly: true}},
+	{name: "all", request: ListRequest{ShowAll: true}},
+	{name: stateDoing, request: ListRequest{ShowAll: true}, state: stateDoing},
+	{name: stateBlocked, request: ListRequest{ShowAll: true}, state: stateBlocked},
+	{name: stateFailed, request: ListRequest{ShowAll: true}, state: stateFailed},
+}
+
+type tuiRow struct {
+	node *treeNode
+	line string
+}
+
+type tuiModel struct {
+	app           *Application
+	agentID       string
+	color         bool
+	width, height int
+	edit          func(string) (string, error)
+
+	view       int
+	epicID     string
+	rows       []tuiRow
+	cursor     int
+	top        int
+	marked     []string
+	detail     []string
+	detailTop  int
+	showDetail bool
+	status     string
+	failed     bool
+	confirm    func()
+	quit       bool
+}
+
+func newTUIModel(app *Application, options TUIOptions, color bool) *tuiModel {
+	return &tuiModel{app: app, agentID: options.AgentID, color: color}
+}
+
+// resize adopts a new screen size. Crossing tuiSplitWidth resets the detail
+// pane: shown beside the list when wide, hidden behind it when narrow.
+func (m *tuiModel) resize(width, height int) {
+	if m.width == 0 || (width >= tuiSplitWidth) != (m.width >= tuiSplitWidth) {
+		m.showDetail = width >= tuiSplitWidth
+	}
+	m.width, m.height = width, height
+}
+
+// RunTUI shows the backlog until the user quits, options.Stop closes, or
+// input ends.
+func RunTUI(app *Application, options TUIOptions, terminal TUITerminal) error {
+	model := newTUIModel(app, options, terminal.Color)
+	model.resize(terminal.Size())
+	if terminal.Edit != nil {
+		model.edit = func(text string) (string, error) {
+			fmt.Fprint(terminal.Out, tuiLeaveScreen)
+			defer fmt.Fprint(terminal.Out, tuiEnterScreen)
+			return terminal.Edit(text)
+		}
+	}
+	fmt.Fprint(terminal.Out, tuiEnterScreen)
+	defer fmt.Fprint(terminal.Out, tuiLeaveScreen)
+	model.reload()
+
+	done := make(chan struct{})
+	defer close(done)
+	changes := make(chan struct{}, 1)
+	watchFailed := make(chan error, 1)
+	go func() {
+		watchFailed <- app.Watch(WatchRequest{Stop: done}, func(WatchEvent) error {
+			select {
+			case changes <- struct{}{}:
+			default:
+			}
+			return nil
+		})
+	}()
+	// Keys are read one batch at a time on request, so no read is pending
+	// while the editor owns the terminal.
+	next := make(chan struct{}, 1)
+	keys := make(chan []string)
+	readFailed := make(chan error, 1)
+	go readTUIKeys(terminal.In, next, done, keys, readFailed)
+	next <- struct{}{}
+	resize := time.NewTicker(tuiResizeInterval)
+	defer resize.Stop()
+
+	dirty := true
+	for {
+		if dirty {
+			if _, err := io.WriteString(terminal.Out, model.frame()); err != nil {
+				return err
+			}
+		}
+		dirty = true
+		select {
+		case <-options.Stop:
+			return nil
+		case batch := <-keys:
+			for _, key := range batch {
+				model.handleKey(key)
+				if model.quit {
+					return nil
+				}
+			}
+			next <- struct{}{}
+		case err := <-readFailed:
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		case <-changes:
+			model.reload()
+		case err := <-watchFailed:
+			watchFailed = nil
+			if err != nil {
+				model.setError(fmt.Errorf("live refresh stopped: %w", err))
+			}
+		case <-resize.C:
+			width, height := terminal.Size()
+			if width == model.width && height == model.height {
+				dirty = false
+				continue
+			}
+			model.resize(width, height)
+			model.reload()
+		}
+	}
+}
+
+func readTUIKeys(in io.Reader, next, done <-chan struct{}, keys chan<- []string, failed chan<- error) {
+	buffer := make([]byte, 256)
+	for {
+		select {
+		case <-next:
+		case <-done:
+			return
+		}
+		n, err := 0, error(nil)
+		for n == 0 && err == nil {
+			n, err = in.Read(buffer)
+		}
+		if n == 0 {
+			select {
+			case failed <- err:
+			case <-done:
+			}
+			return
+		}
+		select {
+		case keys <- parseTUIKeys(buffer[:n]):
+		case <-done:
+			return
+		}
+	}
+}
+
+var tuiEscapeKeys = map[string]string{
+	"[A": "up", "[B": "down", "[C": "right", "[D": "left",
+	"OA": "up", "OB": "down", "OC": "right", "OD": "left",
+	"[5~": "pgup", "[6~": "pgdown",
+	"[H": "home", "[1~": "home", "OH": "home",
+	"[F": "end", "[4~": "end", "OF": "end",
+}
+
+// parseTUIKeys names the keys in one raw read. Unknown escape sequences are
+// dropped whole rather than read as letters.
+func parseTUIKeys(data []byte) []string {
+	var keys []string
+	for len(data) > 0 {
+		switch data[0] {
+		case 0x1b:
+			end := escapeSequenceEnd(data)
+			if end == 1 {
+				keys = append(keys, "esc")
+			} else if name, ok := tuiEscapeKeys[string(data[1:end])]; ok {
+				keys = append(keys, name)
+			}
+			data = data[end:]
+			continue
+		case '\r', '\n':
+			keys = append(keys, "enter")
+		case '\t':
+			keys = append(keys, "tab")
+		case ' ':
+			keys = append(keys, "space")
+		case 0x03:
+			keys = append(keys, "ctrl-c")
+		case 0x7f, 0x08:
+			keys = append(keys, "backspace")
+		default:
+			r, size := utf8.DecodeRune(data)
+			if r != utf8.RuneError && r >= ' ' {
+				keys = append(keys, string(r))
+			}
+			data = data[size:]
+			continue
+		}
+		data = data[1:]
+	}
+	return keys
+}
+
+// escapeSequenceEnd returns the length of the CSI or SS3 sequence at the
+// start of data, or 1 for a lone escape.
+func escapeSequenceEnd(data []byte) int {
+	if len(data) < 2 {
+		return 1
+	}
+	switch data[1] {
+	case 'O':
+		if len(data) < 3 {
+			return 1
+		}
+		return 3
+	case '[':
+		for i := 2; i < len(data); i++ {
+			if data[i] >= 0x40 && data[i] <= 0x7e {
+				return i + 1
+			}
+		}
+		return len(data)
+	}
+	return 1
+}
+
+// split reports whether the detail pane sits beside the list.
+func (m *tuiModel) split() bool {
+	return m.showDetail && m.width >= tuiSplitWidth
+}
+
+func (m *tuiModel) paneWidths() (list, detail int) {
+	switch {
+	case m.split():
+		detail = m.width * 2 / 5
+		return m.width - detail - 1, detail
+	case m.showDetail:
+		return 0, m.width
+	default:
+		return m.width, 0
+	}
+}
+
+func (m *tuiModel) bodyHeight() int {
+	return max(m.height-3, 1)
+}
+
+func (m *tuiModel) selected() *Task {
+	if m.cursor < 0 || m.cursor >= len(m.rows) {
+		return nil
+	}
+	return m.rows[m.cursor].node.task
+}
+
+// reload lists the current view again and keeps the cursor on the same task
+// when it is still shown.
+func (m *tuiModel) reload() {
+	view := tuiViews[m.view]
+	request := view.request
+	request.EpicID = m.epicID
+	outcome, err := m.app.List(request)
+	if kind, _ := ApplicationErrorKind(err); kind == ErrorNotFound && m.epicID != "" {
+		// The focused epic was pruned or emptied; fall back to the whole view.
+		m.epicID = ""
+		request.EpicID = ""
+		outcome, err = m.app.List(request)
+	}
+	if err != nil {
+		m.setError(err)
+		return
+	}
+	roots := outcome.Roots
+	if view.state != "" {
+		roots = filterNodesByState(roots, view.state)
+	}
+	listWidth := m.width - tuiGutter
+	if m.width >= tuiSplitWidth {
+		listWidth, _ = m.paneWidths()
+		listWidth -= tuiGutter
+	}
+	var rendered bytes.Buffer
+	renderTreeView(&rendered, roots, outcome.Graph, m.color, max(listWidth, 1))
+	lines := strings.Split(strings.TrimSuffix(rendered.String(), "\n"), "\n")
+	nodes := flattenTreeNodes(roots)
+
+	previous := m.selected()
+	m.rows = m.rows[:0]
+	for i, node := range nodes {
+		if i < len(lines) {
+			m.rows = append(m.rows, tuiRow{node: node, line: lines[i]})
+		}
+	}
+	m.cursor = min(m.cursor, len(m.rows)-1)
+	if previous != nil {
+		for i, row := range m.rows {
+			if row.node.task.ID == previous.ID {
+				m.cursor = i
+			}
+		}
+	}
+	m.cursor = max(m.cursor, 0)
+	m.loadDetail()
+}
+
+func (m *tuiModel) loadDetail() {
+	m.detail, m.detailTop = nil, 0
+	task := m.selected()
+	if task == nil {
+		return
+	}
+	outcome, err := m.app.Show(ShowRequest{ID: task.ID})
+	if err != nil {
+		m.detail = []string{err.Error()}
+		return
+	}
+	var document bytes.Buffer
+	RenderShow(&document, outcome, false)
+	text := strings.ReplaceAll(strings.TrimRight(document.String(), "\n"), "\t", "    ")
+	m.detail = strings.Split(text, "\n")
+}
+
+// flattenTreeNodes lists nodes in the order renderTreeView prints them.
+func flattenTreeNodes(nodes []*treeNode) []*treeNode {
+	var flat []*treeNode
+	for _, node := range nodes {
+		flat = append(flat, node)
+		if !node.collapsed || !node.isEpic {
+			flat = append(flat, flattenTreeNodes(node.children)...)
+		}
+	}
+	return flat
+}
+
+// filterNodesByState keeps leaves in state and epics with such children.
+func filterNodesByState(nodes []*treeNode, state string) []*treeNode {
+	filtered := make([]*treeNode, 0, len(nodes))
+	for _, node := range nodes {
+		if node == nil || node.task == nil {
+			continue
+		}
+		if node.isEpic {
+			node.children = filterNodesByState(node.children, state)
+			if len(node.children) == 0 {
+				continue
+			}
+		} else if node.task.State != state {
+			continue
+		}
+		filtered = append(filtered, node)
+	}
+	return filtered
+}
+
+func (m *tuiModel) setStatus(format string, args ...any) {
+	m.status, m.failed = fmt.Sprintf(format, args...), false
+}
+
+func (m *tuiModel) setError(err error) {
+	m.status, m.failed = err.Error(), true
+}
+
+func (m *tuiModel) move(delta int) {
+	if len(m.rows) == 0 {
+		return
+	}
+	cursor := min(max(m.cursor+delta, 0), len(m.rows)-1)
+	if cursor != m.cursor {
+		m.cursor = cursor
+		m.loadDetail()
+	}
+}
+
+func (m *tuiModel) handleKey(key string) {
+	if m.confirm != nil {
+		confirm := m.confirm
+		m.confirm = nil
+		if key == "y" {
+			confirm()
+		} else {
+			m.setStatus("Nothing changed")
+		}
+		return
+	}
+	switch key {
+	case "q", "ctrl-c":
+		m.quit = true
+	case "up", "k":
+		m.move(-1)
+	case "down", "j":
+		m.move(1)
+	case "pgup":
+		m.move(-m.bodyHeight())
+	case "pgdown":
+		m.move(m.bodyHeight())
+	case "home", "g":
+		m.move(-len(m.rows))
+	case "end", "G":
+		m.move(len(m.rows))
+	case "K":
+		m.detailTop = max(m.detailTop-1, 0)
+	case "J":
+		m.detailTop = min(m.detailTop+1, max(len(m.detail)-1, 0))
+	case "tab":
+		m.showDetail = !m.showDetail
+		m.reload()
+	case "f":
+		m.view = (m.view + 1) % len(tuiViews)
+		m.reload()
+		m.setStatus("Showing %s tasks", tuiViews[m.view].name)
+	case "enter":
+		m.enter()
+	case "esc", "backspace":
+		m.back()
+	case "r":
+		m.reload()
+	case "space":
+		m.toggleMark()
+	case "s":
+		m.sequence()
+	case "o":
+		m.lifecycle("open")
+	case "b":
+		m.lifecycle("block")
+	case "x":
+		if task := m.selected(); task != nil {
+			m.confirm = func() { m.lifecycle("cancel") }
+			m.setStatus("Cancel %s %s? y to confirm", task.ID, task.Title)
+		}
+	case "c":
+		m.claim()
+	case "e":
+		m.editBody()
+	}
+}
+
+func (m *tuiModel) enter() {
+	task := m.selected()
+	switch {
+	case task == nil:
+	case m.rows[m.cursor].node.isEpic && m.epicID != task.ID:
+		m.epicID = task.ID
+		m.reload()
+		m.setStatus("Epic %s %s", task.ID, task.Title)
+	default:
+		m.showDetail = true
+		m.reload()
+	}
+}
+
+// back leaves the innermost mode: a narrow detail pane, then the epic focus,
+// then the marks.
+func (m *tuiModel) back() {
+	switch {
+	case m.showDetail && !m.split():
+		m.showDetail = false
+		m.reload()
+	case m.epicID != "":
+		m.epicID = ""
+		m.reload()
+		m.setStatus("Showing %s tasks", tuiViews[m.view].name)
+	case len(m.marked) > 0:
+		m.marked = nil
+		m.setStatus("Marks cleared")
+	}
+}
+
+func (m *tuiModel) toggleMark() {
+	task := m.selected()
+	if task == nil {
+		return
+	}
+	for i, id := range m.marked {
+		if id == task.ID {
+			m.marked = append(m.marked[:i], m.marked[i+1:]...)
+			m.move(1)
+			return
+		}
+	}
+	m.marked = append(m.marked, task.ID)
+	m.move(1)
+}
+
+func (m *tuiModel) sequence() {
+	if len(m.marked) < 2 {
+		m.setStatus("Mark two or more tasks with space, in order, then press s")
+		return
+	}
+	if _, err := m.app.Sequence(SequenceRequest{Command: "sequence", EventType: eventLink, IDs: m.marked}); err != nil {
+		m.setError(err)
+		return
+	}
+	m.setStatus("Sequenced %s", strings.Join(m.marked, " then "))
+	m.marked = nil
+	m.reload()
+}
+
+func (m *tuiModel) lifecycle(kind string) {
+	task := m.selected()
+	if task == nil {
+		return
+	}
+	outcome, err := m.app.Lifecycle(LifecycleRequest{Kind: kind, ID: task.ID})
+	if err != nil {
+		m.setError(err)
+		return
+	}
+	m.reload()
+	m.setStatus("%s %s is %s", outcome.Task.ID, outcome.Task.Title, outcome.Task.State)
+}
+
+func (m *tuiModel) claim() {
+	task := m.selected()
+	if task == nil {
+		return
+	}
+	if m.agentID == "" {
+		m.setStatus("Claims need an identity; restart with ergo tui --agent <identity>")
+		return
+	}
+	if _, err := m.app.Claim(ClaimRequest{ID: task.ID, AgentID: m.agentID}); err != nil {
+		m.setError(err)
+		return
+	}
+	m.reload()
+	m.setStatus("Claimed %s as %s", task.ID, m.agentID)
+}
+
+func (m *tuiModel) editBody() {
+	task := m.selected()
+	if task == nil {
+		return
+	}
+	if m.edit == nil {
+		m.setStatus("Editing needs an interactive terminal")
+		return
+	}
+	current, err := m.app.ShowBody(ShowBodyRequest{ID: task.ID})
+	if err != nil {
+		m.setError(err)
+		return
+	}
+	body, err := m.edit(current.Body)
+	if err != nil {
+		m.setError(err)
+		return
+	}
+	base := TaskText{Title: task.Title, Body: current.Body}
+	m.saveEdit(EditTaskRequest{ID: task.ID, Base: base, Edited: TaskText{Body: body}}, body)
+}
+
+// saveEdit stores an edited body. On a conflict the edit is kept in a
+// temporary file, and a clean merge is offered for confirmation, so a
+// concurrent change never discards the user's text.
+func (m *tuiModel) saveEdit(request EditTaskRequest, body string) {
+	outcome, err := m.app.EditTask(request)
+	m.reload()
+	var conflict *EditConflict
+	if errors.As(err, &conflict) {
+		saved, saveErr := keepTUIEdit(body)
+		if saveErr != nil {
+			m.setError(fmt.Errorf("%w; saving your edit failed: %w", err, saveErr))
+			return
+		}
+		if conflict.Clean {
+			merged := EditTaskRequest{ID: request.ID, Base: conflict.Current, Edited: conflict.Merged}
+			m.confirm = func() { m.saveEdit(merged, body) }
+			m.setStatus("%s changed since the edit began; your edit is saved in %s. Apply the merged text? y to confirm", request.ID, saved)
+			return
+		}
+		err = fmt.Errorf("%w; your edit is saved in %s", err, saved)
+	}
+	if err != nil {
+		m.setError(err)
+		return
+	}
+	if len(outcome.ChangedFields) > 0 {
+		m.setStatus("Updated the body of %s", request.ID)
+	} else {
+		m.setStatus("Body of %s unchanged", request.ID)
+	}
+}
+
+// keepTUIEdit writes an edit that could not be applied to a temporary file
+// and returns its path.
+func keepTUIEdit(text string) (string, error) {
+	file, err := os.CreateTemp("", "ergo-edit-*.md")
+	if err != nil {
+		return "", err
+	}
+	if _, err := file.WriteString(text); err != nil {
+		file.Close()
+		return "", err
+	}
+	return file.Name(), file.Close()
+}
+
+// frame draws the whole screen: a header, the panes, the status line, and
+// the key help. Every line fits the width, so nothing wraps.
+func (m *tuiModel) frame() string {
+	var frame strings.Builder
+	frame.WriteString("\033[H")
+	lines := m.frameLines()
+	for i, line := range lines {
+		frame.WriteString(line)
+		frame.WriteString("\033[K")
+		if i < len(lines)-1 {
+			frame.WriteString("\r\n")
+		}
+	}
+	frame.WriteString("\033[J")
+	return frame.String()
+}
+
+func (m *tuiModel) frameLines() []string {
+	height := m.bodyHeight()
+	if m.cursor < m.top {
+		m.top = m.cursor
+	} else if m.cursor >= m.top+height {
+		m.top = m.cursor - height + 1
+	}
+	listWidth, detailWidth := m.paneWidths()
+
+	header := "ergo · " + tuiViews[m.view].name
+	if m.epicID != "" {
+		header += " · epic " + m.epicID
+	}
+	header += fmt.Sprintf(" · %d shown", len(m.rows))
+	if len(m.marked) > 0 {
+		header += fmt.Sprintf(" · %d marked", len(m.marked))
+	}
+	lines := []string{m.styled(truncateToWidth(header, m.width), colorBold)}
+
+	for i := 0; i < height; i++ {
+		var line strings.Builder
+		if listWidth > 0 {
+			line.WriteString(m.listLine(m.top+i, listWidth))
+		}
+		if listWidth > 0 && detailWidth > 0 {
+			line.WriteString(m.styled("│", colorDim))
+		}
+		if detailWidth > 0 && m.detailTop+i < len(m.detail) {
+			line.WriteString(truncateToWidth(m.detail[m.detailTop+i], detailWidth))
+		}
+		lines = append(lines, line.String())
+	}
+
+	status := truncateToWidth(m.status, m.width)
+	if m.failed {
+		status = m.styled(status, colorRed)
+	}
+	lines = append(lines, status, m.styled(truncateToWidth(tuiKeyHelp, m.width), colorDim))
+	if len(lines) > m.height {
+		lines = lines[:max(m.height, 1)]
+	}
+	return lines
+}
+
+// listLine returns row index padded to width, with a gutter holding the
+// row's place in the sequence marks.
+func (m *tuiModel) listLine(index, width int) string {
+	if index >= len(m.rows) {
+		if index == 0 {
+			return padToWidth(truncateToWidth("No tasks in this view; press f for another", width), width)
+		}
+		return strings.Repeat(" ", width)
+	}
+	row := m.rows[index]
+	gutter := "  "
+	for i, id := range m.marked {
+		if id == row.node.task.ID {
+			gutter = fmt.Sprintf("%-2d", i+1)
+			if i >= 9 {
+				gutter = "+ "
+			}
+		}
+	}
+	if index == m.cursor {
+		text := padToWidth(truncateToWidth(gutter+stripANSICodes(row.line), width), width)
+		return tuiReverse + text + colorReset
+	}
+	text := truncateToWidth(gutter+row.line, width)
+	if m.color {
+		text += colorReset
+	}
+	return padToWidth(text, width)
+}
+
+func (m *tuiModel) styled(text, style string) string {
+	if !m.color || text == "" {
+		return text
+	}
+	return style + text + colorReset
+}
+
+func padToWidth(s string, width int) string {
+	if gap := width - visibleLen(s); gap > 0 {
+		return s + strings.Repeat(" ", gap)
+	}
+	return s
+}
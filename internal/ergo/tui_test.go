@@ -0,0 +1,250 @@
+package ergo
+
+import (
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTUIKeysDecodeEscapeSequences(t *testing.T) {
+	got := parseTUIKeys([]byte("\x1b[Ajk\r\x1b[6~ \x1b\x1b[200~x\x03\x1bOB"))
+	want := []string{"up", "j", "k", "enter", "pgdown", "space", "esc", "x", "ctrl-c", "down"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("keys = %q, want %q", got, want)
+	}
+}
+
+func TestTUINavigatesFiltersAndDispatchesActions(t *testing.T) {
+	app := newTestApplication(t)
+	epic, err := app.CreateTask(CreateTaskRequest{Title: "Launch"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, title := range []string{"Design", "Build"} {
+		if _, err := app.CreateTask(CreateTaskRequest{Title: title, EpicID: epic.ID}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	loose, err := app.CreateTask(CreateTaskRequest{Title: "Loose end"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	model := newTUIModel(app, TUIOptions{AgentID: "human"}, false)
+	model.resize(120, 20)
+	model.edit = func(text string) (string, error) { return text + "## Goal\nShip it\n", nil }
+	model.reload()
+	if task := model.selected(); task == nil || task.ID != loose.ID {
+		t.Fatalf("first row = %#v", model.rows)
+	}
+	frame := model.frame()
+	for _, want := range []string{"Launch", "Design", "Build", "title: " + yamlString("Loose end")} {
+		if !strings.Contains(frame, want) {
+			t.Fatalf("frame lacks %q:\n%s", want, frame)
+		}
+	}
+
+	press(model, "down", "enter")
+	if model.epicID != epic.ID || len(model.rows) != 3 {
+		t.Fatalf("epic focus = %q with %d rows", model.epicID, len(model.rows))
+	}
+	before, after := model.rows[1].node.task.ID, model.rows[2].node.task.ID
+	press(model, "down", "space", "down", "space", "s")
+	if !strings.HasPrefix(model.status, "Sequenced") || len(model.marked) != 0 {
+		t.Fatalf("sequence status = %q, marked %v", model.status, model.marked)
+	}
+	if blockers := mustList(t, app).Blockers(after); len(blockers) != 1 || blockers[0] != before {
+		t.Fatalf("blockers of %s = %v, want %s", after, blockers, before)
+	}
+
+	press(model, "end", "x", "n")
+	if state := mustList(t, app).Tasks[after].State; state != stateTodo {
+		t.Fatalf("declined cancel left %s", state)
+	}
+	press(model, "x", "y")
+	if state := mustList(t, app).Tasks[after].State; state != stateCanceled {
+		t.Fatalf("confirmed cancel left %s: %s", state, model.status)
+	}
+
+	press(model, "home", "down", "c", "e")
+	task := mustList(t, app).Tasks[before]
+	if task.State != stateDoing || task.ClaimedBy != "human" || task.Body != "## Goal\nShip it\n" {
+		t.Fatalf("claimed and edited task = %#v", task)
+	}
+	press(model, "f", "f", "f", "end")
+	if tuiViews[model.view].name != stateDoing || len(model.rows) != 2 || model.selected().ID != before {
+		t.Fatalf("doing view rows = %d, selected %v", len(model.rows), model.selected())
+	}
+	press(model, "f", "f", "f", "home", "down", "b")
+	if state := mustList(t, app).Tasks[before].State; state != stateBlocked {
+		t.Fatalf("block left %s: %s", state, model.status)
+	}
+	press(model, "o")
+	if state := mustList(t, app).Tasks[before].State; state != stateTodo {
+		t.Fatalf("open left %s: %s", state, model.status)
+	}
+	press(model, "esc")
+	if model.epicID != "" || len(model.rows) != 3 {
+		t.Fatalf("after esc: epic %q with %d rows", model.epicID, len(model.rows))
+	}
+}
+
+func TestTUIReportsRefusedActionsInTheStatusLine(t *testing.T) {
+	app := newTestApplication(t)
+	for _, title := range []string{"First", "Second"} {
+		if _, err := app.CreateTask(CreateTaskRequest{Title: title}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	model := newTUIModel(app, TUIOptions{}, false)
+	model.resize(80, 24)
+	model.reload()
+	press(model, "c")
+	if !strings.Contains(model.status, "--agent") {
+		t.Fatalf("claim without identity status = %q", model.status)
+	}
+	press(model, "s")
+	if !strings.Contains(model.status, "Mark two") {
+		t.Fatalf("sequence without marks status = %q", model.status)
+	}
+	press(model, "space", "space", "s", "space", "home", "space", "s")
+	if !model.failed || !strings.Contains(model.status, "cycle") {
+		t.Fatalf("cyclic sequence status = %q", model.status)
+	}
+	id := model.selected().ID
+	model.edit = func(text string) (string, error) {
+		if _, err := app.UpdateBody(UpdateBodyRequest{ID: id, Body: []byte("Theirs\n")}); err != nil {
+			t.Fatal(err)
+		}
+		return "Mine\n", nil
+	}
+	t.Setenv("TMPDIR", t.TempDir())
+	press(model, "e")
+	if !model.failed || !strings.Contains(model.status, "changed since the edit began") || mustList(t, app).Tasks[id].Body != "Theirs\n" {
+		t.Fatalf("stale edit status = %q", model.status)
+	}
+	_, saved, _ := strings.Cut(model.status, "your edit is saved in ")
+	if kept, err := os.ReadFile(saved); err != nil || string(kept) != "Mine\n" {
+		t.Fatalf("kept edit = %q, %v", kept, err)
+	}
+
+	if _, err := app.UpdateBody(UpdateBodyRequest{ID: id, Body: []byte("one\ntwo\nthree\n")}); err != nil {
+		t.Fatal(err)
+	}
+	model.edit = func(text string) (string, error) {
+		if _, err := app.UpdateBody(UpdateBodyRequest{ID: id, Body: []byte("ONE\ntwo\nthree\n")}); err != nil {
+			t.Fatal(err)
+		}
+		return "one\ntwo\nTHREE\n", nil
+	}
+	press(model, "e")
+	if !strings.Contains(model.status, "Apply the merged text?") {
+		t.Fatalf("mergeable edit status = %q", model.status)
+	}
+	press(model, "y")
+	if body := mustList(t, app).Tasks[id].Body; body != "ONE\ntwo\nTHREE\n" {
+		t.Fatalf("merged body = %q, status %q", body, model.status)
+	}
+}
+
+func TestTUIFitsNarrowTerminals(t *testing.T) {
+	app := newTestApplication(t)
+	if _, err := app.CreateTask(CreateTaskRequest{Title: "A title long enough to need truncation on a small screen", Labels: []string{"frontend"}}); err != nil {
+		t.Fatal(err)
+	}
+	model := newTUIModel(app, TUIOptions{}, true)
+	model.resize(40, 8)
+	model.reload()
+	for _, showDetail := range []bool{false, true} {
+		lines := model.frameLines()
+		if len(lines) != model.height {
+			t.Fatalf("frame has %d lines, want %d", len(lines), model.height)
+		}
+		for _, line := range lines {
+			if visibleLen(line) > model.width {
+				t.Fatalf("line wider than %d: %q", model.width, stripANSICodes(line))
+			}
+		}
+		if got := strings.Contains(strings.Join(lines, "\n"), "title: "); got != showDetail {
+			t.Fatalf("detail shown = %v, want %v", got, showDetail)
+		}
+		press(model, "tab")
+	}
+}
+
+func TestRunTUIRefreshesOnChangesAndQuits(t *testing.T) {
+	app := newTestApplication(t)
+	input, keys := io.Pipe()
+	screen := &syncBuffer{}
+	finished := make(chan error, 1)
+	go func() {
+		finished <- RunTUI(app, TUIOptions{}, TUITerminal{
+			In: input, Out: screen, Size: func() (int, int) { return 120, 20 },
+		})
+	}()
+	waitForScreen(t, screen, "No tasks in this view")
+	if _, err := app.CreateTask(CreateTaskRequest{Title: "Arrived while watching"}); err != nil {
+		t.Fatal(err)
+	}
+	waitForScreen(t, screen, "Arrived while watching")
+	if _, err := keys.Write([]byte("q")); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-finished:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("RunTUI did not quit")
+	}
+	if !strings.HasSuffix(screen.String(), tuiLeaveScreen) {
+		t.Fatal("RunTUI did not restore the screen")
+	}
+}
+
+func press(model *tuiModel, keys ...string) {
+	for _, key := range keys {
+		model.handleKey(key)
+	}
+}
+
+func mustList(t *testing.T, app *Application) *Graph {
+	t.Helper()
+	list, err := app.List(ListRequest{ShowAll: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return list.Graph
+}
+
+type syncBuffer struct {
+	mu   sync.Mutex
+	text strings.Builder
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.text.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.text.String()
+}
+
+func waitForScreen(t *testing.T, screen *syncBuffer, want string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(screen.String(), want) {
+		if time.Now().After(deadline) {
+			t.Fatalf("screen never showed %q", want)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
@@ -17,7 +17,7 @@ import (
 	"time"
 )
 
-func sortedKeys(items map[string]struct{}) []string {
+func sortedKeys[V any](items map[string]V) []string {
 	if len(items) == 0 {
 		return nil
 	}
@@ -0,0 +1,258 @@
+// Purpose: Stream task-level backlog changes to a long-running reader.
+// Exports: WatchRequest, WatchEvent, and Application.Watch.
+// Role: Follow records appended to the backlog and journal, decode them with
+// the ordinary codecs, and report created, state, claimed, result, and ready
+// changes.
+// Invariants: Watch never writes and holds the repository lock only while
+// reading. Records already present at start are not reported. A rewrite
+// (compaction, prune, or merge) cannot be followed record by record, so the
+// watcher reports a resync and continues from the rewritten files.
+// Notes: readiness is re-derived on every wake, so a lapsing lease is reported
+// without any write.
+package ergo
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Watch event kinds.
+const (
+	watchCreated = "created"
+	watchState   = "state"
+	watchClaimed = "claimed"
+	watchResult  = "result"
+	watchReady   = "ready"
+	watchResync  = "resync"
+)
+
+// defaultWatchInterval bounds a wait without a file notification and is the
+// polling period where notifications are unavailable.
+const defaultWatchInterval = time.Second
+
+type WatchRequest struct {
+	// ReadyOnly reports only tasks becoming ready.
+	ReadyOnly bool
+	Interval  time.Duration
+	// Stop ends the watch; Watch returns nil once it is closed.
+	Stop <-chan struct{}
+}
+
+type WatchEvent struct {
+	At     string
+	Kind   string
+	TaskID string
+	Title  string
+	State  string
+	Agent  string
+	Text   string
+}
+
+// Watch reports changes to emit until request.Stop closes or emit fails.
+func (a *Application) Watch(request WatchRequest, emit func(WatchEvent) error) error {
+	var repository Repository
+	if err := repository.Open(a.repository); err != nil {
+		return classifyRepositoryError(err)
+	}
+	interval := request.Interval
+	if interval <= 0 {
+		interval = defaultWatchInterval
+	}
+	waiter := newChangeWaiter(filepath.Dir(repository.eventsPath), interval)
+	defer waiter.close()
+
+	var follower watchFollower
+	if _, err := follower.sync(&repository); err != nil {
+		return classifyViewError(err)
+	}
+	for {
+		select {
+		case <-request.Stop:
+			return nil
+		default:
+		}
+		waiter.wait(time.Time{})
+		select {
+		case <-request.Stop:
+			return nil
+		default:
+		}
+		changes, err := follower.sync(&repository)
+		if err != nil {
+			return classifyViewError(err)
+		}
+		for _, change := range changes {
+			if request.ReadyOnly && change.Kind != watchReady {
+				continue
+			}
+			if err := emit(change); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// changeWaiter blocks until the .ergo directory may have changed, its
+// interval passes, or deadline arrives; a zero deadline is no limit.
+type changeWaiter interface {
+	wait(deadline time.Time)
+	close()
+}
+
+type pollWaiter struct{ interval time.Duration }
+
+func (w pollWaiter) wait(deadline time.Time) { time.Sleep(waitSpan(w.interval, deadline)) }
+func (w pollWaiter) close()                  {}
+
+// waitSpan is how long one wait may last: the interval, cut short by a
+// deadline.
+func waitSpan(interval time.Duration, deadline time.Time) time.Duration {
+	if deadline.IsZero() {
+		return interval
+	}
+	return max(0, min(interval, time.Until(deadline)))
+}
+
+// fileStamp cheaply detects that a file may have changed since the last read.
+type fileStamp struct {
+	size    int64
+	modTime time.Time
+}
+
+func statStamp(path string) fileStamp {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}
+	}
+	return fileStamp{size: info.Size(), modTime: info.ModTime()}
+}
+
+// watchFollower remembers how much of each file has been reported.
+type watchFollower struct {
+	started                    bool
+	backlogStamp, journalStamp fileStamp
+	events                     int
+	lastEvent                  string
+	snapshot                   bool
+	entries                    int
+	lastEntry                  string
+	graph                      *Graph
+	ready                      map[string]bool
+}
+
+func (f *watchFollower) sync(r *Repository) ([]WatchEvent, error) {
+	var changes []WatchEvent
+	backlogStamp, journalStamp := statStamp(r.eventsPath), statStamp(r.journalPath)
+	if !f.started || backlogStamp != f.backlogStamp || journalStamp != f.journalStamp {
+		events, journal, err := r.viewLog()
+		if err != nil {
+			return nil, err
+		}
+		graph := events.snapshot
+		if graph == nil {
+			graph = newGraph()
+		}
+		if graph, err = replayEventsOnto(graph, events.events); err != nil {
+			return nil, &corruptionError{err: err}
+		}
+		if f.started {
+			eventChanges, err := f.eventChanges(events, graph)
+			if err != nil {
+				return nil, err
+			}
+			changes = append(eventChanges, f.journalChanges(journal, graph)...)
+		}
+		f.backlogStamp, f.journalStamp = backlogStamp, journalStamp
+		f.events, f.snapshot, f.lastEvent = len(events.events), events.snapshot != nil, ""
+		if f.events > 0 {
+			f.lastEvent = eventFingerprint(events.events[f.events-1])
+		}
+		f.entries, f.lastEntry = len(journal.entries), ""
+		if f.entries > 0 {
+			f.lastEntry = entryFingerprint(journal.entries[f.entries-1])
+		}
+		f.graph = graph
+	}
+
+	ready := map[string]bool{}
+	now := formatTime(time.Now().UTC())
+	for _, task := range sortedTasks(f.graph.Tasks) {
+		if !f.graph.IsReady(task.ID) {
+			continue
+		}
+		ready[task.ID] = true
+		if f.started && !f.ready[task.ID] {
+			changes = append(changes, WatchEvent{At: now, Kind: watchReady, TaskID: task.ID, Title: task.Title, State: task.State})
+		}
+	}
+	f.ready, f.started = ready, true
+	return changes, nil
+}
+
+// eventChanges describes backlog records appended since the last sync, or a
+// resync when the records already reported were rewritten.
+func (f *watchFollower) eventChanges(read eventLogRead, graph *Graph) ([]WatchEvent, error) {
+	rewritten := len(read.events) < f.events || (read.snapshot != nil) != f.snapshot ||
+		(f.events > 0 && eventFingerprint(read.events[f.events-1]) != f.lastEvent)
+	if rewritten {
+		return []WatchEvent{{At: formatTime(time.Now().UTC()), Kind: watchResync}}, nil
+	}
+	var changes []WatchEvent
+	for index := f.events; index < len(read.events); index++ {
+		event := read.events[index]
+		decoded, err := decodeEvent(event, index)
+		if err != nil {
+			return nil, &corruptionError{err: err}
+		}
+		change := WatchEvent{At: event.TS}
+		switch data := decoded.payload.(type) {
+		case NewTaskEvent:
+			change.Kind, change.TaskID, change.Title, change.State = watchCreated, data.ID, data.Title, data.State
+		case StateEvent:
+			change.Kind, change.TaskID, change.State = watchState, data.ID, data.NewState
+		case ClaimEvent:
+			change.Kind, change.TaskID, change.Agent = watchClaimed, data.ID, data.AgentID
+		default:
+			continue
+		}
+		if task := graph.Tasks[change.TaskID]; task != nil && change.Title == "" {
+			change.Title = task.Title
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// journalChanges reports appended result entries. A rewritten journal always
+// accompanies a rewritten backlog, whose resync already covers it.
+func (f *watchFollower) journalChanges(read journalRead, graph *Graph) []WatchEvent {
+	if len(read.entries) < f.entries || (f.entries > 0 && entryFingerprint(read.entries[f.entries-1]) != f.lastEntry) {
+		return nil
+	}
+	var changes []WatchEvent
+	for _, entry := range read.entries[f.entries:] {
+		if entry.Kind != "result" {
+			continue
+		}
+		text := entry.Text
+		if entry.File != nil {
+			text = strings.TrimSpace(text + " [" + entry.File.Path + "]")
+		}
+		title := ""
+		if task := graph.Tasks[entry.TaskID]; task != nil {
+			title = task.Title
+		}
+		changes = append(changes, WatchEvent{At: entry.At, Kind: watchResult, TaskID: entry.TaskID, Title: title, Agent: entry.Agent, Text: text})
+	}
+	return changes
+}
+
+func eventFingerprint(event Event) string {
+	return event.Type + "\x00" + event.TS + "\x00" + string(event.Data)
+}
+
+func entryFingerprint(entry JournalEntry) string {
+	return entry.TaskID + "\x00" + entry.Kind + "\x00" + entry.At + "\x00" + entry.Text
+}
@@ -0,0 +1,53 @@
+//go:build linux
+
+// Purpose: Wake the backlog watcher on Linux when the .ergo directory changes.
+// Exports: none (package-internal helper).
+// Role: Platform implementation used by Application.Watch.
+// Invariants: Any setup failure falls back to polling; a wait never outlasts
+// the interval, so stop requests and lease expiry stay timely.
+package ergo
+
+import (
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+type inotifyWaiter struct {
+	fd       int
+	interval time.Duration
+}
+
+func newChangeWaiter(dir string, interval time.Duration) changeWaiter {
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC | unix.IN_NONBLOCK)
+	if err != nil {
+		return pollWaiter{interval: interval}
+	}
+	// Atomic rewrites arrive as renames into the directory, so watch the
+	// directory rather than the files.
+	mask := uint32(unix.IN_MODIFY | unix.IN_CLOSE_WRITE | unix.IN_MOVED_TO | unix.IN_CREATE | unix.IN_DELETE)
+	if _, err := unix.InotifyAddWatch(fd, dir, mask); err != nil {
+		_ = unix.Close(fd)
+		return pollWaiter{interval: interval}
+	}
+	return &inotifyWaiter{fd: fd, interval: interval}
+}
+
+func (w *inotifyWaiter) wait(deadline time.Time) {
+	span := waitSpan(w.interval, deadline)
+	fds := []unix.PollFd{{Fd: int32(w.fd), Events: unix.POLLIN}}
+	// Round up so a sub-millisecond remainder still waits rather than spins.
+	if _, err := unix.Poll(fds, int((span+time.Millisecond-1)/time.Millisecond)); err != nil {
+		time.Sleep(span)
+		return
+	}
+	// Drain every queued notification; the caller re-reads state once.
+	buffer := make([]byte, 4096)
+	for {
+		if n, err := unix.Read(w.fd, buffer); n <= 0 || err != nil {
+			return
+		}
+	}
+}
+
+func (w *inotifyWaiter) close() { _ = unix.Close(w.fd) }
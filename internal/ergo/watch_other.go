@@ -0,0 +1,13 @@
+//go:build !linux
+
+// Purpose: Wake the backlog watcher on hosts without inotify.
+// Exports: none (package-internal helper).
+// Role: Platform implementation used by Application.Watch.
+// Invariants: Polling re-checks file stamps once per interval.
+package ergo
+
+import "time"
+
+func newChangeWaiter(_ string, interval time.Duration) changeWaiter {
+	return pollWaiter{interval: interval}
+}
@@ -0,0 +1,54 @@
+// Purpose: Render watch events as readable lines or newline-delimited JSON.
+// Role: Presentation only; change detection belongs to Application.Watch.
+// Invariants: each event is one line, written as soon as it is known.
+package ergo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+const watchJSONVersion = 1
+
+type watchJSONEvent struct {
+	Version int    `json:"version"`
+	At      string `json:"at"`
+	Kind    string `json:"kind"`
+	ID      string `json:"id,omitempty"`
+	Title   string `json:"title,omitempty"`
+	State   string `json:"state,omitempty"`
+	Agent   string `json:"agent,omitempty"`
+	Text    string `json:"text,omitempty"`
+}
+
+func RenderWatchEvent(w io.Writer, event WatchEvent, useColor bool) {
+	writeGenerated(w, event.At+"  ", colorDim, useColor)
+	writeGenerated(w, event.Kind, colorBold, useColor)
+	if event.Kind == watchResync {
+		fmt.Fprintln(w, "  backlog rewritten; following the new files")
+		return
+	}
+	fmt.Fprint(w, "  ")
+	writeGenerated(w, event.TaskID, colorCyan, useColor)
+	fmt.Fprintf(w, "  %s", event.Title)
+	switch event.Kind {
+	case watchCreated, watchState:
+		fmt.Fprintf(w, "  %s", event.State)
+	case watchResult:
+		fmt.Fprintf(w, "  %s", event.Text)
+	}
+	if event.Agent != "" {
+		writeGenerated(w, "  by "+event.Agent, colorDim, useColor)
+	}
+	fmt.Fprintln(w)
+}
+
+func RenderWatchEventJSON(w io.Writer, event WatchEvent) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(watchJSONEvent{
+		Version: watchJSONVersion, At: event.At, Kind: event.Kind, ID: event.TaskID, Title: event.Title,
+		State: event.State, Agent: event.Agent, Text: event.Text,
+	})
+}
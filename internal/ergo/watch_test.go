@@ -0,0 +1,91 @@
+package ergo
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWatchReportsChangesAfterStart(t *testing.T) {
+	app := newTestApplication(t)
+	if _, err := app.CreateTask(CreateTaskRequest{Title: "Already here"}); err != nil {
+		t.Fatal(err)
+	}
+	stop := make(chan struct{})
+	events := make(chan WatchEvent, 64)
+	finished := make(chan error, 1)
+	go func() {
+		finished <- app.Watch(WatchRequest{Interval: 10 * time.Millisecond, Stop: stop}, func(event WatchEvent) error {
+			events <- event
+			return nil
+		})
+	}()
+	expect := func(kind string) WatchEvent {
+		t.Helper()
+		deadline := time.After(5 * time.Second)
+		for {
+			select {
+			case event := <-events:
+				if event.Kind == kind {
+					return event
+				}
+			case <-deadline:
+				t.Fatalf("no %s event", kind)
+			}
+		}
+	}
+	// Give the watcher time to take its baseline before writing.
+	time.Sleep(50 * time.Millisecond)
+
+	task, err := app.CreateTask(CreateTaskRequest{Title: "Watched"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created := expect(watchCreated); created.TaskID != task.ID || created.Title != "Watched" {
+		t.Fatalf("created event = %+v", created)
+	}
+	if _, err := app.Claim(ClaimRequest{ID: task.ID, AgentID: "alpha"}); err != nil {
+		t.Fatal(err)
+	}
+	if claimed := expect(watchClaimed); claimed.TaskID != task.ID || claimed.Agent != "alpha" {
+		t.Fatalf("claimed event = %+v", claimed)
+	}
+	if _, err := app.Result(ResultRequest{ID: task.ID, Text: "halfway"}); err != nil {
+		t.Fatal(err)
+	}
+	if result := expect(watchResult); result.Text != "halfway" || result.Title != "Watched" {
+		t.Fatalf("result event = %+v", result)
+	}
+	if _, err := app.Compact(); err != nil {
+		t.Fatal(err)
+	}
+	expect(watchResync)
+
+	close(stop)
+	select {
+	case err := <-finished:
+		if err != nil {
+			t.Fatalf("Watch returned %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Watch did not stop")
+	}
+}
+
+func TestRenderWatchEventJSONWritesOneLinePerEvent(t *testing.T) {
+	var out bytes.Buffer
+	event := WatchEvent{At: "2026-01-02T03:04:05Z", Kind: watchState, TaskID: "ABCDEF", Title: "A <b>", State: "done"}
+	if err := RenderWatchEventJSON(&out, event); err != nil {
+		t.Fatal(err)
+	}
+	want := `{"version":1,"at":"2026-01-02T03:04:05Z","kind":"state","id":"ABCDEF","title":"A <b>","state":"done"}` + "\n"
+	if out.String() != want {
+		t.Fatalf("json = %q", out.String())
+	}
+	out.Reset()
+	RenderWatchEvent(&out, event, false)
+	if !strings.Contains(out.String(), "state  ABCDEF  A <b>  done") {
+		t.Fatalf("text = %q", out.String())
+	}
+}
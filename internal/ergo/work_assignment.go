@@ -3,6 +3,7 @@ package ergo
 import (
 	"fmt"
 	"io"
+	"strings"
 )
 
 func RunClaim(id, agentID string, opts GlobalOptions, render RenderOptions) error {
@@ -25,6 +26,10 @@ func RunClaimOldestReady(agentID string, opts GlobalOptions, render RenderOption
 
 func RenderClaim(w io.Writer, outcome ClaimOutcome, useColor bool) {
 	if outcome.NoReady {
+		if len(outcome.Labels) > 0 {
+			fmt.Fprintf(w, "No ready ergo tasks labeled %s.\n", strings.Join(outcome.Labels, " and "))
+			return
+		}
 		fmt.Fprintln(w, "No ready ergo tasks.")
 		return
 	}
@@ -48,6 +53,13 @@ func RenderClaim(w io.Writer, outcome ClaimOutcome, useColor bool) {
 	writeNextCommand(w, next["block"], useColor)
 	writeNextCommand(w, next["cancel"], useColor)
 	writeNextCommand(w, next["open"], useColor)
+	if !task.LeaseExpiresAt.IsZero() {
+		writeNextCommand(w, "ergo heartbeat "+id+" --agent "+task.ClaimedBy, useColor)
+	}
+}
+
+func RenderHeartbeat(w io.Writer, outcome HeartbeatOutcome) {
+	fmt.Fprintf(w, "%s - lease held by %s until %s (%s)\n", outcome.ID, outcome.AgentID, formatTime(outcome.ExpiresAt), outcome.Lease)
 }
 
 func writeNextCommand(w io.Writer, command string, useColor bool) {